@@ -0,0 +1,64 @@
+package main
+
+import (
+	"image"
+	"time"
+)
+
+const defaultAntiBurninMinutes = 3
+
+// burninOffsets is the cycle of shifts anti_burnin steps through, starting
+// unshifted and circling the original position one pixel out
+var burninOffsets = []image.Point{
+	{0, 0}, {1, 0}, {1, 1}, {0, 1}, {-1, 1}, {-1, 0}, {-1, -1}, {0, -1}, {1, -1},
+}
+
+// burninShift returns the offset the frame is drawn at now. Each step lasts
+// anti_burnin_minutes, counted from the Unix epoch so every display and
+// restart agree on the step.
+func (dm *DisplayManager) burninShift() image.Point {
+	if !dm.config.AntiBurnin {
+		return image.Point{}
+	}
+	minutes := dm.config.AntiBurninMinutes
+	if minutes == 0 {
+		minutes = defaultAntiBurninMinutes
+	}
+	step := dm.timeNow().Unix() / int64((time.Duration(minutes) * time.Minute).Seconds())
+	return burninOffsets[step%int64(len(burninOffsets))]
+}
+
+// clampShift drops the part of a shift that would push lit pixels off an
+// edge, so content drawn against the border is never clipped
+func clampShift(img *image.RGBA, shift image.Point) image.Point {
+	b := img.Bounds()
+	if shift.X > 0 && !columnClear(img, b.Max.X-1) || shift.X < 0 && !columnClear(img, b.Min.X) {
+		shift.X = 0
+	}
+	if shift.Y > 0 && !rowClear(img, b.Max.Y-1) || shift.Y < 0 && !rowClear(img, b.Min.Y) {
+		shift.Y = 0
+	}
+	return shift
+}
+
+// columnClear reports whether no pixel in column x is lit
+func columnClear(img *image.RGBA, x int) bool {
+	b := img.Bounds()
+	for y := b.Min.Y; y < b.Max.Y; y++ {
+		if img.RGBAAt(x, y).R != 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// rowClear reports whether no pixel in row y is lit
+func rowClear(img *image.RGBA, y int) bool {
+	b := img.Bounds()
+	for x := b.Min.X; x < b.Max.X; x++ {
+		if img.RGBAAt(x, y).R != 0 {
+			return false
+		}
+	}
+	return true
+}
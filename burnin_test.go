@@ -0,0 +1,119 @@
+package main
+
+import (
+	"bytes"
+	"image"
+	"image/color"
+	"testing"
+	"time"
+
+	"github.com/swilcox/go-monitor-ssd1306/render"
+)
+
+// TestBurninShiftCycles tests that the offset steps through the cycle every
+// anti_burnin_minutes and wraps around
+func TestBurninShiftCycles(t *testing.T) {
+	// A multiple of the 3 minute step, so the cycle starts at the first offset
+	start := time.Unix(int64(3*60*len(burninOffsets)*1000), 0)
+	now := start
+	dm := &DisplayManager{
+		config:  Config{AntiBurnin: true},
+		timeNow: func() time.Time { return now },
+	}
+
+	for i := 0; i <= len(burninOffsets); i++ {
+		want := burninOffsets[i%len(burninOffsets)]
+		now = start.Add(time.Duration(i) * 3 * time.Minute)
+		if got := dm.burninShift(); got != want {
+			t.Errorf("Step %d: expected %v, got %v", i, want, got)
+		}
+		// The offset holds for the whole step
+		now = now.Add(3*time.Minute - time.Second)
+		if got := dm.burninShift(); got != want {
+			t.Errorf("Step %d: expected %v until the step ends, got %v", i, want, got)
+		}
+	}
+
+	dm.config.AntiBurninMinutes = 10
+	now = start.Add(10 * time.Minute)
+	if got := dm.burninShift(); got != burninOffsets[1] {
+		t.Errorf("Expected anti_burnin_minutes to set the step length, got %v", got)
+	}
+
+	dm.config.AntiBurnin = false
+	if got := dm.burninShift(); got != (image.Point{}) {
+		t.Errorf("Expected no shift when disabled, got %v", got)
+	}
+}
+
+// TestAntiBurninDraw tests that frames reach the display shifted and that
+// an unchanged screen is redrawn when the offset moves
+func TestAntiBurninDraw(t *testing.T) {
+	start := time.Unix(int64(3*60*len(burninOffsets)*1000), 0)
+	now := start
+	mockDisplay := NewMockDisplay(t)
+	dm := &DisplayManager{
+		dev:     mockDisplay,
+		img:     blankFrame(),
+		timeNow: func() time.Time { return now },
+		config: Config{AntiBurnin: true, Screens: []Screen{
+			{Name: "Static", Components: []Component{{Type: "text", X: 20, Y: 30, Text: "Still"}}},
+		}},
+	}
+	frame := labelImage(20, 30, "Still")
+
+	if err := dm.renderCurrentScreen(); err != nil {
+		t.Fatalf("Failed to render screen: %v", err)
+	}
+	if !bytes.Equal(mockDisplay.lastImage.Pix, frame.Pix) {
+		t.Error("Expected the first step to be unshifted")
+	}
+
+	// Same content within the step is skipped
+	now = now.Add(time.Minute)
+	if err := dm.renderCurrentScreen(); err != nil {
+		t.Fatalf("Failed to render screen: %v", err)
+	}
+	if mockDisplay.drawCount != 1 {
+		t.Errorf("Expected an unchanged frame to be skipped, got %d draws", mockDisplay.drawCount)
+	}
+
+	now = start.Add(3 * time.Minute)
+	if err := dm.renderCurrentScreen(); err != nil {
+		t.Fatalf("Failed to render screen: %v", err)
+	}
+	if mockDisplay.drawCount != 2 {
+		t.Errorf("Expected the new offset to redraw an unchanged frame, got %d draws", mockDisplay.drawCount)
+	}
+	if want := render.Shift(frame, 1, 0); !bytes.Equal(mockDisplay.lastImage.Pix, want.Pix) {
+		t.Error("Expected the frame to be shifted one pixel right")
+	}
+}
+
+// TestClampShift tests that a shift is dropped on an axis where it would
+// push lit edge pixels off the display
+func TestClampShift(t *testing.T) {
+	tests := []struct {
+		name  string
+		lit   image.Point
+		shift image.Point
+		want  image.Point
+	}{
+		{name: "Clear edges", lit: image.Pt(64, 32), shift: image.Pt(1, -1), want: image.Pt(1, -1)},
+		{name: "Right edge", lit: image.Pt(width-1, 32), shift: image.Pt(1, 1), want: image.Pt(0, 1)},
+		{name: "Left edge", lit: image.Pt(0, 32), shift: image.Pt(-1, 1), want: image.Pt(0, 1)},
+		{name: "Away from the edge", lit: image.Pt(0, 32), shift: image.Pt(1, 0), want: image.Pt(1, 0)},
+		{name: "Top edge", lit: image.Pt(64, 0), shift: image.Pt(-1, -1), want: image.Pt(-1, 0)},
+		{name: "Bottom edge", lit: image.Pt(64, height-1), shift: image.Pt(1, 1), want: image.Pt(1, 0)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			img := blankFrame()
+			img.Set(tt.lit.X, tt.lit.Y, color.White)
+			if got := clampShift(img, tt.shift); got != tt.want {
+				t.Errorf("Expected %v, got %v", tt.want, got)
+			}
+		})
+	}
+}
@@ -0,0 +1,76 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"periph.io/x/conn/v3/gpio"
+	"periph.io/x/conn/v3/gpio/gpioreg"
+)
+
+const (
+	// buttonDebounce is how long after a press further edges are ignored
+	buttonDebounce = 200 * time.Millisecond
+	// buttonPollInterval bounds how long a wait blocks, so watchers notice shutdown
+	buttonPollInterval = 500 * time.Millisecond
+)
+
+// Button interface for a push button
+type Button interface {
+	// WaitForPress blocks for up to timeout and reports whether the button was pressed
+	WaitForPress(timeout time.Duration) bool
+}
+
+// gpioButton implements Button with a GPIO wired to ground through the button
+type gpioButton struct {
+	pin gpio.PinIO
+}
+
+// openButton configures the named GPIO as a pulled-up input that detects
+// presses on the falling edge. periph must already be initialized.
+func openButton(pinName string) (*gpioButton, error) {
+	pin := gpioreg.ByName(pinName)
+	if pin == nil {
+		return nil, fmt.Errorf("unknown button pin %q", pinName)
+	}
+	if err := pin.In(gpio.PullUp, gpio.FallingEdge); err != nil {
+		return nil, fmt.Errorf("failed to configure button pin %s: %v", pinName, err)
+	}
+	return &gpioButton{pin: pin}, nil
+}
+
+// WaitForPress waits for a falling edge and checks the pin is still low, so
+// noise on release isn't counted
+func (b *gpioButton) WaitForPress(timeout time.Duration) bool {
+	return b.pin.WaitForEdge(timeout) && b.pin.Read() == gpio.Low
+}
+
+// debouncer accepts an event only when the previous accepted one is at least
+// interval old
+type debouncer struct {
+	interval time.Duration
+	last     time.Time
+}
+
+func (d *debouncer) accept(now time.Time) bool {
+	if !d.last.IsZero() && now.Sub(d.last) < d.interval {
+		return false
+	}
+	d.last = now
+	return true
+}
+
+// watchButton sends cmd to Run's loop for every debounced press until ctx is done
+func (dm *DisplayManager) watchButton(ctx context.Context, button Button, cmd displayCommand) {
+	bounce := debouncer{interval: buttonDebounce}
+	for ctx.Err() == nil {
+		if !button.WaitForPress(buttonPollInterval) || !bounce.accept(time.Now()) {
+			continue
+		}
+		select {
+		case dm.commands <- cmd:
+		case <-ctx.Done():
+		}
+	}
+}
@@ -0,0 +1,63 @@
+package main
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+// MockButton implements Button, reporting a press for every value sent on presses
+type MockButton struct {
+	presses chan struct{}
+}
+
+func (b *MockButton) WaitForPress(timeout time.Duration) bool {
+	select {
+	case <-b.presses:
+		return true
+	case <-time.After(timeout):
+		return false
+	}
+}
+
+// TestDebouncer tests that presses closer together than the interval are dropped
+func TestDebouncer(t *testing.T) {
+	start := time.Date(2024, 3, 9, 14, 0, 0, 0, time.Local)
+	d := debouncer{interval: buttonDebounce}
+	presses := []struct {
+		at   time.Duration
+		want bool
+	}{
+		{0, true},
+		{20 * time.Millisecond, false}, // contact bounce
+		{150 * time.Millisecond, false},
+		{250 * time.Millisecond, true},
+		{900 * time.Millisecond, true},
+	}
+	for _, p := range presses {
+		if got := d.accept(start.Add(p.at)); got != p.want {
+			t.Errorf("Press at %v: expected accept=%v, got %v", p.at, p.want, got)
+		}
+	}
+}
+
+// TestButtonNavigation tests that simulated presses move between screens
+func TestButtonNavigation(t *testing.T) {
+	next := &MockButton{presses: make(chan struct{})}
+	prev := &MockButton{presses: make(chan struct{})}
+	dm := &DisplayManager{commands: make(chan displayCommand)}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go dm.watchButton(ctx, next, displayCommand{kind: cmdNextScreen})
+	go dm.watchButton(ctx, prev, displayCommand{kind: cmdPrevScreen})
+
+	next.presses <- struct{}{}
+	if cmd := <-dm.commands; cmd.kind != cmdNextScreen {
+		t.Errorf("Expected next screen command, got %v", cmd)
+	}
+	prev.presses <- struct{}{}
+	if cmd := <-dm.commands; cmd.kind != cmdPrevScreen {
+		t.Errorf("Expected previous screen command, got %v", cmd)
+	}
+}
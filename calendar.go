@@ -0,0 +1,129 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"sort"
+	"time"
+
+	"github.com/apognu/gocal"
+)
+
+const (
+	defaultCalendarInterval = 15 // minutes
+	defaultCalendarDays     = 7
+	calendarRetryInterval   = time.Minute
+)
+
+// CalendarConfig configures the iCal feed calendar components read from
+type CalendarConfig struct {
+	URL      string `yaml:"url" json:"url"`           // iCal (.ics) feed
+	Interval int    `yaml:"interval" json:"interval"` // minutes between fetches, defaults to 15
+	Days     int    `yaml:"days" json:"days"`         // how far ahead to look for events, defaults to 7
+}
+
+// interval returns how long a fetched feed is reused
+func (c CalendarConfig) interval() time.Duration {
+	if c.Interval == 0 {
+		return defaultCalendarInterval * time.Minute
+	}
+	return time.Duration(c.Interval) * time.Minute
+}
+
+// window returns how far ahead events are read
+func (c CalendarConfig) window() time.Duration {
+	days := c.Days
+	if days == 0 {
+		days = defaultCalendarDays
+	}
+	return time.Duration(days) * 24 * time.Hour
+}
+
+// calendarEvent is a single occurrence of an event
+type calendarEvent struct {
+	title  string
+	start  time.Time
+	allDay bool
+}
+
+// CalendarParser interface for reading events from an iCal feed
+type CalendarParser interface {
+	// Events returns the occurrences starting between from and to, in start order
+	Events(r io.Reader, from, to time.Time) ([]calendarEvent, error)
+}
+
+// RealCalendarParser implements CalendarParser using gocal, which also
+// expands recurring events
+type RealCalendarParser struct{}
+
+// Events parses an iCal feed
+func (p *RealCalendarParser) Events(r io.Reader, from, to time.Time) ([]calendarEvent, error) {
+	parser := gocal.NewParser(r)
+	parser.Start, parser.End = &from, &to
+	if err := parser.Parse(); err != nil {
+		return nil, fmt.Errorf("failed to parse calendar: %v", err)
+	}
+
+	var events []calendarEvent
+	for _, e := range parser.Events {
+		if e.Start == nil || e.Start.Before(from) || e.Start.After(to) {
+			continue
+		}
+		events = append(events, calendarEvent{
+			title:  e.Summary,
+			start:  *e.Start,
+			allDay: e.RawStart.Params["VALUE"] == "DATE",
+		})
+	}
+	sort.SliceStable(events, func(i, j int) bool { return events[i].start.Before(events[j].start) })
+	return events, nil
+}
+
+// nextEvent returns the first cached event that hasn't started yet, fetching
+// the feed again in the background once the configured interval has passed,
+// or a minute after a failed fetch. ok is false until a fetch has succeeded.
+func (dm *DisplayManager) nextEvent() (event calendarEvent, found, ok bool) {
+	now := dm.timeNow()
+	client, parser, config := dm.httpClient, dm.calendarParser, dm.config.Calendar
+	events, ok := dm.calendar.get("calendar", now, config.interval(), calendarRetryInterval, dm.goAsync, func() ([]calendarEvent, error) {
+		return fetchCalendar(client, parser, config, now)
+	})
+
+	for _, e := range events {
+		if e.start.After(now) {
+			return e, true, ok
+		}
+	}
+	return calendarEvent{}, false, ok
+}
+
+// fetchCalendar downloads the feed and reads the events in the lookahead window
+func fetchCalendar(client HTTPGetter, parser CalendarParser, config CalendarConfig, now time.Time) ([]calendarEvent, error) {
+	data, err := fetchURL(client, config.URL)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch calendar: %v", err)
+	}
+	return parser.Events(bytes.NewReader(data), now, now.Add(config.window()))
+}
+
+// formatEventTime formats an event's start in the local time zone of now,
+// adding the weekday when it isn't today. All-day events show only the day.
+func formatEventTime(event calendarEvent, now time.Time, timeFormat string) string {
+	if timeFormat == "" {
+		timeFormat = "15:04"
+	}
+	start := event.start.In(now.Location())
+	if event.allDay {
+		// All-day dates carry no zone, so keep their calendar date
+		start = event.start
+		if start.Year() == now.Year() && start.YearDay() == now.YearDay() {
+			return "today"
+		}
+		return start.Format("Mon")
+	}
+	if start.Year() == now.Year() && start.YearDay() == now.YearDay() {
+		return start.Format(timeFormat)
+	}
+	return start.Format("Mon " + timeFormat)
+}
@@ -0,0 +1,134 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+)
+
+// calendarFixture has a past event, a daily recurring standup and an all-day event
+const calendarFixture = `BEGIN:VCALENDAR
+VERSION:2.0
+PRODID:-//go-monitor-ssd1306//test//EN
+BEGIN:VEVENT
+UID:breakfast@example.com
+DTSTAMP:20240301T000000Z
+DTSTART:20240301T080000Z
+DTEND:20240301T083000Z
+SUMMARY:Breakfast
+END:VEVENT
+BEGIN:VEVENT
+UID:standup@example.com
+DTSTAMP:20240301T000000Z
+DTSTART:20240301T093000Z
+DTEND:20240301T094500Z
+RRULE:FREQ=DAILY;COUNT=5
+SUMMARY:Standup
+END:VEVENT
+BEGIN:VEVENT
+UID:holiday@example.com
+DTSTAMP:20240301T000000Z
+DTSTART;VALUE=DATE:20240305
+DTEND;VALUE=DATE:20240306
+SUMMARY:Holiday
+END:VEVENT
+END:VCALENDAR
+`
+
+// TestCalendarParser tests reading upcoming occurrences from the fixture
+func TestCalendarParser(t *testing.T) {
+	from := time.Date(2024, 3, 1, 9, 0, 0, 0, time.UTC)
+	events, err := (&RealCalendarParser{}).Events(strings.NewReader(calendarFixture), from, from.Add(4*24*time.Hour))
+	if err != nil {
+		t.Fatalf("Failed to parse calendar: %v", err)
+	}
+
+	var got []string
+	for _, e := range events {
+		got = append(got, fmt.Sprintf("%s %s %v", e.title, e.start.UTC().Format("Jan 2 15:04"), e.allDay))
+	}
+	want := []string{
+		"Standup Mar 1 09:30 false",
+		"Standup Mar 2 09:30 false",
+		"Standup Mar 3 09:30 false",
+		"Standup Mar 4 09:30 false",
+		"Holiday Mar 5 00:00 true",
+	}
+	if strings.Join(got, "\n") != strings.Join(want, "\n") {
+		t.Errorf("Expected events\n%s\ngot\n%s", strings.Join(want, "\n"), strings.Join(got, "\n"))
+	}
+}
+
+// TestFormatEventTime tests how event start times are shown
+func TestFormatEventTime(t *testing.T) {
+	now := time.Date(2024, 3, 1, 9, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name   string
+		event  calendarEvent
+		format string
+		want   string
+	}{
+		{"Today", calendarEvent{start: time.Date(2024, 3, 1, 9, 30, 0, 0, time.UTC)}, "", "09:30"},
+		{"Later this week", calendarEvent{start: time.Date(2024, 3, 4, 14, 0, 0, 0, time.UTC)}, "", "Mon 14:00"},
+		{"Custom format", calendarEvent{start: time.Date(2024, 3, 1, 15, 0, 0, 0, time.UTC)}, "3:04PM", "3:00PM"},
+		{"Other zone", calendarEvent{start: time.Date(2024, 3, 1, 10, 30, 0, 0, time.FixedZone("CET", 3600))}, "", "09:30"},
+		{"All day", calendarEvent{start: time.Date(2024, 3, 5, 0, 0, 0, 0, time.UTC), allDay: true}, "", "Tue"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := formatEventTime(tt.event, now, tt.format); got != tt.want {
+				t.Errorf("Expected %q, got %q", tt.want, got)
+			}
+		})
+	}
+}
+
+// TestCalendarComponent tests rendering the next event from a cached feed
+func TestCalendarComponent(t *testing.T) {
+	now := time.Date(2024, 3, 1, 9, 0, 0, 0, time.UTC)
+	client := &MockHTTPGetter{body: calendarFixture}
+	dm := &DisplayManager{
+		httpClient:     client,
+		asyncFunc:      func(f func()) { f() },
+		calendarParser: &RealCalendarParser{},
+		config:         Config{Calendar: CalendarConfig{URL: "https://example.com/cal.ics", Interval: 60, Days: 7}},
+		img:            blankFrame(),
+		timeNow:        func() time.Time { return now },
+	}
+	comp := Component{Type: "calendar", X: 5, Y: 12}
+	render := func(want string) {
+		t.Helper()
+		dm.clearImage()
+		if err := dm.renderComponent(comp); err != nil {
+			t.Fatalf("Failed to render component: %v", err)
+		}
+		if !bytes.Equal(dm.img.Pix, labelImage(5, 12, want).Pix) {
+			t.Errorf("Expected %q to be drawn", want)
+		}
+	}
+
+	render("Next: Standup 09:30")
+	if len(client.urls) != 1 || client.urls[0] != "https://example.com/cal.ics" {
+		t.Fatalf("Expected one fetch of the feed, got %v", client.urls)
+	}
+
+	// Once the event starts the next one is taken from the cache
+	now = now.Add(45 * time.Minute)
+	render("Next: Standup Sat 09:30")
+	if len(client.urls) != 1 {
+		t.Errorf("Expected no refetch within the interval, got %d fetches", len(client.urls))
+	}
+
+	// An empty feed renders No events
+	now = now.Add(15 * time.Minute)
+	client.body = "BEGIN:VCALENDAR\nVERSION:2.0\nEND:VCALENDAR\n"
+	render("No events")
+
+	// Without a good fetch the component shows N/A
+	dm.calendar = fetchCache[[]calendarEvent]{}
+	client.err = fmt.Errorf("connection refused")
+	render("Next: N/A")
+}
@@ -0,0 +1,61 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"strings"
+)
+
+// checkConfig loads and validates a config and writes a summary of the
+// screens it would show to w. Like writePreview it never initializes periph or
+// opens the bus, so configs can be checked in CI.
+func checkConfig(configPath string, w io.Writer) error {
+	config, err := loadConfig(configPath)
+	if err != nil {
+		return err
+	}
+
+	fmt.Fprintf(w, "%s: OK (version %d)\n", configPath, config.Version)
+	if len(config.Displays) == 0 {
+		writeScreenSummary(w, config, "")
+		return nil
+	}
+	for i, display := range config.Displays {
+		view := config.forDisplay(i)
+		fmt.Fprintf(w, "display %d (%s) at %#x:\n", i, display.Name, view.i2cAddress())
+		writeScreenSummary(w, view, "  ")
+	}
+	return nil
+}
+
+// writeScreenSummary writes one line per screen followed by its components,
+// each indented under indent
+func writeScreenSummary(w io.Writer, config Config, indent string) {
+	for i, screen := range config.Screens {
+		duration := screen.Duration
+		if duration == 0 {
+			duration = config.ScreenDuration
+		}
+		noun := "components"
+		if len(screen.Components) == 1 {
+			noun = "component"
+		}
+		fmt.Fprintf(w, "%sscreen %d (%s): %ds, %d %s\n", indent, i, screen.Name, duration, len(screen.Components), noun)
+		for _, comp := range screen.Components {
+			fmt.Fprintf(w, "%s  %s\n", indent, describeComponent(comp))
+		}
+	}
+}
+
+// describeComponent summarizes a component as its type, position and any
+// label or text, e.g. `cpu at 5,12 "CPU"`
+func describeComponent(comp Component) string {
+	parts := []string{comp.Type, fmt.Sprintf("at %d,%d", comp.X, comp.Y)}
+	if comp.Label != "" {
+		parts = append(parts, fmt.Sprintf("%q", comp.Label))
+	}
+	if comp.Text != "" {
+		parts = append(parts, fmt.Sprintf("%q", comp.Text))
+	}
+	return strings.Join(parts, " ")
+}
@@ -0,0 +1,96 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// TestCheckConfig tests validating good and bad configs without hardware
+func TestCheckConfig(t *testing.T) {
+	tests := []struct {
+		name    string
+		config  string
+		wantOut []string
+		wantErr string
+	}{
+		{
+			name: "Valid",
+			config: `
+screen_duration: 5
+screens:
+  - name: System
+    components:
+      - {type: cpu, x: 5, y: 12, label: CPU}
+      - {type: text, x: 5, y: 40, text: hello}
+  - name: Clock
+    duration: 10
+    components:
+      - {type: time, x: 5, y: 20}
+`,
+			wantOut: []string{
+				"OK (version 2)",
+				"screen 0 (System): 5s, 2 components",
+				`  cpu at 5,12 "CPU"`,
+				`  text at 5,40 "hello"`,
+				"screen 1 (Clock): 10s, 1 component",
+				"  time at 5,20",
+			},
+		},
+		{
+			name: "Displays",
+			config: `
+screen_duration: 5
+displays:
+  - name: left
+    screens: [{name: A, components: [{type: time, x: 5, y: 20}]}]
+  - name: right
+    i2c_address: 0x3d
+    screens: [{name: B, components: [{type: date, x: 5, y: 20}]}]
+`,
+			wantOut: []string{
+				"display 0 (left) at 0x3c:",
+				"display 1 (right) at 0x3d:",
+				"  screen 0 (B): 5s, 1 component",
+			},
+		},
+		{
+			name: "Invalid",
+			config: `
+screen_duration: 0
+screens:
+  - name: Bad
+    components: [{type: bogus, x: 5, y: 20}]
+`,
+			wantErr: `unknown type "bogus"`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "config.yaml")
+			if err := os.WriteFile(path, []byte(tt.config), 0644); err != nil {
+				t.Fatal(err)
+			}
+
+			var out bytes.Buffer
+			err := checkConfig(path, &out)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("Expected error containing %q, got %v", tt.wantErr, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Expected the config to pass, got %v", err)
+			}
+			for _, want := range tt.wantOut {
+				if !strings.Contains(out.String(), want) {
+					t.Errorf("Expected output to contain %q, got:\n%s", want, out.String())
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,72 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// configFetchTimeout bounds fetching a config URL at startup
+const configFetchTimeout = 10 * time.Second
+
+// Where readConfigSource reads "-" and URLs from; tests replace them
+var (
+	configStdin      io.Reader  = os.Stdin
+	configHTTPClient HTTPGetter = &http.Client{Timeout: configFetchTimeout}
+)
+
+// isConfigURL reports whether the config source is fetched over HTTP(S)
+func isConfigURL(source string) bool {
+	return strings.HasPrefix(source, "http://") || strings.HasPrefix(source, "https://")
+}
+
+// isConfigFile reports whether the config source is a file on disk, which is
+// the only kind that can be watched for changes
+func isConfigFile(source string) bool {
+	return source != "-" && !isConfigURL(source)
+}
+
+// readConfigSource returns the raw config: standard input for "-", the body
+// of a GET for an http:// or https:// URL and the named file otherwise
+func readConfigSource(source string) ([]byte, error) {
+	switch {
+	case source == "-":
+		data, err := io.ReadAll(configStdin)
+		if err != nil {
+			return nil, fmt.Errorf("error reading config from stdin: %v", err)
+		}
+		return data, nil
+	case isConfigURL(source):
+		data, err := fetchURL(configHTTPClient, source)
+		if err != nil {
+			return nil, fmt.Errorf("error fetching config from %s: %v", source, err)
+		}
+		return data, nil
+	default:
+		data, err := os.ReadFile(source)
+		if err != nil {
+			return nil, fmt.Errorf("error reading config file: %v", err)
+		}
+		return data, nil
+	}
+}
+
+// configIsJSON reports whether a config source is parsed as JSON, which it is
+// when its file name or URL path ends in .json. Standard input is YAML, which
+// also accepts most JSON.
+func configIsJSON(source string) bool {
+	if isConfigURL(source) {
+		u, err := url.Parse(source)
+		if err != nil {
+			return false
+		}
+		return strings.EqualFold(path.Ext(u.Path), ".json")
+	}
+	return strings.EqualFold(filepath.Ext(source), ".json")
+}
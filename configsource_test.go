@@ -0,0 +1,84 @@
+package main
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+const sourceConfigYAML = `
+screen_duration: 5
+screens:
+  - name: Piped
+    components:
+      - type: text
+        x: 5
+        y: 12
+        text: hello
+`
+
+// TestLoadConfigStdin tests reading the config from standard input with "-"
+// and that a stdin config isn't watched for changes
+func TestLoadConfigStdin(t *testing.T) {
+	orig := configStdin
+	defer func() { configStdin = orig }()
+	configStdin = strings.NewReader(sourceConfigYAML)
+
+	managers, err := newDisplayManagers("-", &MockNetworkChecker{})
+	if err != nil {
+		t.Fatalf("Failed to load config from stdin: %v", err)
+	}
+	dm := managers[0]
+	if len(dm.config.Screens) != 1 || dm.config.Screens[0].Name != "Piped" {
+		t.Errorf("Expected the piped screen, got %+v", dm.config.Screens)
+	}
+	if dm.configPath != "" {
+		t.Errorf("Expected stdin not to be watched, got config path %q", dm.configPath)
+	}
+}
+
+// TestLoadConfigURL tests fetching the config over HTTP, including JSON by
+// the URL's extension and the errors for failed fetches
+func TestLoadConfigURL(t *testing.T) {
+	orig := configHTTPClient
+	defer func() { configHTTPClient = orig }()
+
+	client := &MockHTTPGetter{body: sourceConfigYAML}
+	configHTTPClient = client
+	config, err := loadConfig("http://config.local/monitor.yaml")
+	if err != nil {
+		t.Fatalf("Failed to fetch config: %v", err)
+	}
+	if len(config.Screens) != 1 || config.Screens[0].Name != "Piped" {
+		t.Errorf("Expected the fetched screen, got %+v", config.Screens)
+	}
+	if len(client.urls) != 1 || client.urls[0] != "http://config.local/monitor.yaml" {
+		t.Errorf("Expected one GET of the config URL, got %v", client.urls)
+	}
+
+	configHTTPClient = &MockHTTPGetter{body: `{"screen_duration": 5, "screens": [{"name": "JSON", "components": [{"type": "time", "x": 5, "y": 12}]}]}`}
+	config, err = loadConfig("https://config.local/monitor.json?host=pi")
+	if err != nil {
+		t.Fatalf("Failed to fetch JSON config: %v", err)
+	}
+	if config.Screens[0].Name != "JSON" {
+		t.Errorf("Expected the JSON screen, got %+v", config.Screens)
+	}
+
+	tests := []struct {
+		name    string
+		client  *MockHTTPGetter
+		wantErr string
+	}{
+		{name: "Not found", client: &MockHTTPGetter{status: 404}, wantErr: "error fetching config from http://config.local/monitor.yaml: unexpected status 404 Not Found"},
+		{name: "Timeout", client: &MockHTTPGetter{err: errors.New("context deadline exceeded")}, wantErr: "context deadline exceeded"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			configHTTPClient = tt.client
+			if _, err := loadConfig("http://config.local/monitor.yaml"); err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("Expected error containing %q, got %v", tt.wantErr, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,68 @@
+package main
+
+import (
+	"log/slog"
+)
+
+// commandKind identifies a request to change what the display is showing
+type commandKind int
+
+const (
+	cmdShowScreen commandKind = iota
+	cmdPause
+	cmdResume
+	cmdNextScreen
+	cmdPrevScreen
+)
+
+// displayCommand is sent to Run's loop by the HTTP control endpoints and buttons
+type displayCommand struct {
+	kind   commandKind
+	screen int // cmdShowScreen only
+}
+
+// commandQueueSize lets a few commands wait while the loop is busy rendering
+const commandQueueSize = 8
+
+// handleCommand applies a command inside Run's loop. Rotation is driven by
+// timer, which is stopped while paused.
+func (dm *DisplayManager) handleCommand(cmd displayCommand, timer screenTimer) error {
+	switch cmd.kind {
+	case cmdShowScreen:
+		// The config may have been reloaded since the request was validated
+		if cmd.screen < 0 || cmd.screen >= len(dm.config.Screens) {
+			slog.Warn("ignoring request for a screen that doesn't exist", "screen", cmd.screen, "screens", len(dm.config.Screens))
+			return nil
+		}
+		return dm.showScreen(cmd.screen, timer)
+
+	case cmdNextScreen:
+		return dm.showScreen((dm.currentScreen+1)%len(dm.config.Screens), timer)
+
+	case cmdPrevScreen:
+		n := len(dm.config.Screens)
+		return dm.showScreen((dm.currentScreen-1+n)%n, timer)
+
+	case cmdPause:
+		dm.paused = true
+		timer.Stop()
+
+	case cmdResume:
+		if dm.paused {
+			dm.paused = false
+			timer.Reset(dm.screenDuration())
+		}
+	}
+	dm.publishStatus()
+	return nil
+}
+
+// showScreen switches to a screen immediately, giving it a full duration
+// unless rotation is paused
+func (dm *DisplayManager) showScreen(index int, timer screenTimer) error {
+	dm.currentScreen = index
+	if !dm.paused {
+		timer.Reset(dm.screenDuration())
+	}
+	return dm.renderCurrentScreen()
+}
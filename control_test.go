@@ -0,0 +1,82 @@
+package main
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+// TestScreenCommands tests jumping to a screen and pausing rotation through
+// the command channel
+func TestScreenCommands(t *testing.T) {
+	timer := &MockTimer{c: make(chan time.Time), resets: make(chan time.Duration)}
+	screen := func(name string) Screen {
+		return Screen{Name: name, Components: []Component{{Type: "ip", X: 5, Y: 20, Label: name}}}
+	}
+	dm := &DisplayManager{
+		dev:            NewMockDisplay(t),
+		networkChecker: &MockNetworkChecker{ipAddress: "192.168.1.100"},
+		img:            blankFrame(),
+		timeNow:        time.Now,
+		newTimer:       func(d time.Duration) screenTimer { return timer },
+		commands:       make(chan displayCommand),
+		config: Config{
+			ScreenDuration: 3,
+			Screens:        []Screen{screen("A"), screen("B"), screen("C")},
+		},
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error)
+	go func() {
+		done <- dm.Run(ctx)
+	}()
+
+	// Jumping restarts the rotation timer for the new screen
+	dm.commands <- displayCommand{kind: cmdShowScreen, screen: 2}
+	<-timer.resets
+	if dm.currentScreen != 2 {
+		t.Errorf("Expected screen 2 after jumping, got %d", dm.currentScreen)
+	}
+
+	// While paused a timer fire doesn't rotate
+	dm.commands <- displayCommand{kind: cmdPause}
+	timer.c <- time.Now()
+	dm.commands <- displayCommand{kind: cmdShowScreen, screen: 1}
+	dm.commands <- displayCommand{kind: cmdResume}
+	if got := <-timer.resets; got != 3*time.Second {
+		t.Errorf("Expected resume to restart the timer with 3s, got %v", got)
+	}
+	if dm.currentScreen != 1 {
+		t.Errorf("Expected screen 1 while paused, got %d", dm.currentScreen)
+	}
+
+	// Next and previous wrap around and also restart the timer
+	dm.commands <- displayCommand{kind: cmdNextScreen}
+	<-timer.resets
+	dm.commands <- displayCommand{kind: cmdNextScreen}
+	<-timer.resets
+	if dm.currentScreen != 0 {
+		t.Errorf("Expected next to wrap to screen 0, got %d", dm.currentScreen)
+	}
+	dm.commands <- displayCommand{kind: cmdPrevScreen}
+	<-timer.resets
+	if dm.currentScreen != 2 {
+		t.Errorf("Expected previous to wrap to screen 2, got %d", dm.currentScreen)
+	}
+	dm.commands <- displayCommand{kind: cmdShowScreen, screen: 1}
+	<-timer.resets
+
+	// Out-of-range screens are ignored
+	dm.commands <- displayCommand{kind: cmdShowScreen, screen: 7}
+	timer.c <- time.Now()
+	<-timer.resets
+	if dm.currentScreen != 2 {
+		t.Errorf("Expected rotation to continue to screen 2, got %d", dm.currentScreen)
+	}
+
+	cancel()
+	if err := <-done; err != nil {
+		t.Fatalf("Run returned error: %v", err)
+	}
+}
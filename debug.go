@@ -0,0 +1,76 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"image"
+	"image/png"
+	"os"
+	"path/filepath"
+	"strings"
+	"syscall"
+)
+
+// debugOutputPath returns where this display writes its debug frames. With
+// several displays each gets a numbered file, so frame.png becomes frame0.png
+// and a pipe named frames becomes frames0. The extension is kept as it is,
+// since it picks the format.
+func (dm *DisplayManager) debugOutputPath() string {
+	path := dm.config.DebugOutput
+	if dm.displayCount == 0 {
+		return path
+	}
+	ext := filepath.Ext(path)
+	return fmt.Sprintf("%s%d%s", strings.TrimSuffix(path, ext), dm.displayIndex, ext)
+}
+
+// writeDebugFrame replaces the contents of path with img, as a PNG when the
+// path ends in .png and otherwise as the packed 1-bit buffer the SSD1306
+// takes. A named pipe with no reader is skipped rather than waited on.
+func writeDebugFrame(path string, img *image.RGBA) error {
+	var data []byte
+	if strings.EqualFold(filepath.Ext(path), ".png") {
+		var buf bytes.Buffer
+		if err := png.Encode(&buf, img); err != nil {
+			return err
+		}
+		data = buf.Bytes()
+	} else {
+		data = packFrame(img)
+	}
+
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_TRUNC|syscall.O_NONBLOCK, 0644)
+	if errors.Is(err, syscall.ENXIO) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if _, err := f.Write(data); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// packFrame packs img in SSD1306 page order: one byte per column for each
+// band of 8 rows, with the top row in the least significant bit
+func packFrame(img *image.RGBA) []byte {
+	b := img.Bounds()
+	pages := (b.Dy() + 7) / 8
+	buf := make([]byte, pages*b.Dx())
+	for page := 0; page < pages; page++ {
+		for x := 0; x < b.Dx(); x++ {
+			var column byte
+			for bit := 0; bit < 8; bit++ {
+				y := page*8 + bit
+				if y < b.Dy() && img.RGBAAt(b.Min.X+x, b.Min.Y+y).R != 0 {
+					column |= 1 << uint(bit)
+				}
+			}
+			buf[page*b.Dx()+x] = column
+		}
+	}
+	return buf
+}
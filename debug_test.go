@@ -0,0 +1,141 @@
+package main
+
+import (
+	"bytes"
+	"image"
+	"image/draw"
+	"image/png"
+	"os"
+	"path/filepath"
+	"syscall"
+	"testing"
+	"time"
+)
+
+// TestPackFrame tests the SSD1306 page layout of packed frames
+func TestPackFrame(t *testing.T) {
+	img := blankFrame()
+	img.Pix[img.PixOffset(0, 0)] = 0xff
+	img.Pix[img.PixOffset(3, 9)] = 0xff
+	img.Pix[img.PixOffset(127, 63)] = 0xff
+
+	buf := packFrame(img)
+	if len(buf) != width*height/8 {
+		t.Fatalf("Expected %d bytes, got %d", width*height/8, len(buf))
+	}
+	want := make([]byte, len(buf))
+	want[0] = 0x01           // page 0, column 0, row 0
+	want[width+3] = 0x02     // page 1, column 3, row 9
+	want[7*width+127] = 0x80 // page 7, column 127, row 63
+	if !bytes.Equal(buf, want) {
+		t.Error("Packed frame does not match the SSD1306 page layout")
+	}
+}
+
+// TestDebugOutput tests that rendered frames are written to debug_output
+// in the format its extension asks for
+func TestDebugOutput(t *testing.T) {
+	screens := []Screen{{Name: "A", Components: []Component{{Type: "text", X: 5, Y: 20, Text: "Debug"}}}}
+
+	for _, name := range []string{"frame.bin", "frame.png"} {
+		t.Run(name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), name)
+			dm := &DisplayManager{
+				dev:     NewMockDisplay(t),
+				img:     blankFrame(),
+				timeNow: time.Now,
+				config:  Config{ScreenDuration: 5, DebugOutput: path, Screens: screens},
+			}
+			if err := dm.renderCurrentScreen(); err != nil {
+				t.Fatalf("Failed to render screen: %v", err)
+			}
+
+			data, err := os.ReadFile(path)
+			if err != nil {
+				t.Fatalf("Expected a debug frame to be written: %v", err)
+			}
+			want := labelImage(5, 20, "Debug")
+			if filepath.Ext(name) == ".png" {
+				decoded, err := png.Decode(bytes.NewReader(data))
+				if err != nil {
+					t.Fatalf("Failed to decode PNG: %v", err)
+				}
+				got := image.NewRGBA(decoded.Bounds())
+				draw.Draw(got, got.Bounds(), decoded, image.Point{}, draw.Src)
+				if !bytes.Equal(got.Pix, want.Pix) {
+					t.Error("PNG debug frame does not match the rendered frame")
+				}
+				return
+			}
+			if !bytes.Equal(data, packFrame(want)) {
+				t.Error("Packed debug frame does not match the rendered frame")
+			}
+		})
+	}
+}
+
+// TestDebugOutputMultipleDisplays tests that each display numbers its debug
+// file, keeping an extensionless path packed rather than turning it into a PNG
+func TestDebugOutputMultipleDisplays(t *testing.T) {
+	dir := t.TempDir()
+	screens := []Screen{{Name: "A", Components: []Component{{Type: "text", X: 5, Y: 20, Text: "Debug"}}}}
+	for i := 0; i < 2; i++ {
+		dm := &DisplayManager{
+			dev:          NewMockDisplay(t),
+			img:          blankFrame(),
+			timeNow:      time.Now,
+			displayIndex: i,
+			displayCount: 2,
+			config:       Config{ScreenDuration: 5, DebugOutput: filepath.Join(dir, "frames"), Screens: screens},
+		}
+		if err := dm.renderCurrentScreen(); err != nil {
+			t.Fatalf("Display %d: failed to render screen: %v", i, err)
+		}
+	}
+
+	want := packFrame(labelImage(5, 20, "Debug"))
+	for _, name := range []string{"frames0", "frames1"} {
+		data, err := os.ReadFile(filepath.Join(dir, name))
+		if err != nil {
+			t.Fatalf("Expected a debug frame in %s: %v", name, err)
+		}
+		if !bytes.Equal(data, want) {
+			t.Errorf("%s: packed debug frame does not match the rendered frame", name)
+		}
+	}
+	if entries, _ := os.ReadDir(dir); len(entries) != 2 {
+		t.Errorf("Expected only frames0 and frames1, got %d files", len(entries))
+	}
+}
+
+// TestDebugOutputPipeWithoutReader tests that a named pipe nobody reads
+// doesn't block rendering
+func TestDebugOutputPipeWithoutReader(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "frames")
+	if err := syscall.Mkfifo(path, 0644); err != nil {
+		t.Skipf("named pipes unavailable: %v", err)
+	}
+	mockDisplay := NewMockDisplay(t)
+	dm := &DisplayManager{
+		dev:     mockDisplay,
+		img:     blankFrame(),
+		timeNow: time.Now,
+		config: Config{ScreenDuration: 5, DebugOutput: path, Screens: []Screen{
+			{Name: "A", Components: []Component{{Type: "text", X: 5, Y: 20, Text: "Debug"}}},
+		}},
+	}
+
+	done := make(chan error, 1)
+	go func() { done <- dm.renderCurrentScreen() }()
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Fatalf("Failed to render screen: %v", err)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("Rendering blocked on a named pipe without a reader")
+	}
+	if mockDisplay.drawCount != 1 {
+		t.Errorf("Expected the frame to reach the display, got %d draws", mockDisplay.drawCount)
+	}
+}
@@ -0,0 +1,23 @@
+package main
+
+import "log/slog"
+
+// diskTotal sums the used and total bytes of the filesystems mounted at
+// mountpoints. Unreadable mountpoints are skipped; ok is false when none could
+// be read.
+func (dm *DisplayManager) diskTotal(mountpoints []string) (used, total uint64, ok bool) {
+	for _, mountpoint := range mountpoints {
+		usage, err := dm.metricsSource.DiskUsage(mountpoint)
+		if err != nil {
+			slog.Debug("skipping unreadable mountpoint", "mountpoint", mountpoint, "err", err)
+			continue
+		}
+		if usage.Total == 0 {
+			continue
+		}
+		used += usage.Used
+		total += usage.Total
+		ok = true
+	}
+	return used, total, ok
+}
@@ -0,0 +1,51 @@
+package main
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/shirou/gopsutil/v3/disk"
+)
+
+// TestDiskAllComponent tests that diskall adds up the readable mountpoints and
+// shows the combined percentage and used/total bytes
+func TestDiskAllComponent(t *testing.T) {
+	metrics := &MockMetricsProvider{usages: map[string]*disk.UsageStat{
+		"/":     {Path: "/", Used: 10 << 30, Total: 32 << 30},
+		"/data": {Path: "/data", Used: 200 << 30, Total: 480 << 30},
+	}}
+
+	tests := []struct {
+		name      string
+		comp      Component
+		wantLabel string
+	}{
+		{
+			name:      "Two mountpoints",
+			comp:      Component{Type: "diskall", X: 5, Y: 20, Label: "Disks", Mountpoints: []string{"/", "/data"}},
+			wantLabel: "Disks: 41% 210/512 GB",
+		},
+		{
+			name:      "Unreadable mountpoint skipped",
+			comp:      Component{Type: "diskall", X: 5, Y: 20, Mountpoints: []string{"/", "/mnt/missing"}},
+			wantLabel: "31% 10.0/32.0 GB",
+		},
+		{
+			name:      "None readable",
+			comp:      Component{Type: "diskall", X: 5, Y: 20, Label: "Disks", Mountpoints: []string{"/mnt/missing"}},
+			wantLabel: "Disks: N/A",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dm := &DisplayManager{metricsSource: metrics, img: blankFrame()}
+			if err := dm.renderComponent(tt.comp); err != nil {
+				t.Fatalf("Failed to render component: %v", err)
+			}
+			if want := labelImage(5, 20, tt.wantLabel); !bytes.Equal(dm.img.Pix, want.Pix) {
+				t.Errorf("Rendered image does not match %q", tt.wantLabel)
+			}
+		})
+	}
+}
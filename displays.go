@@ -0,0 +1,198 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"time"
+
+	"periph.io/x/conn/v3/i2c"
+)
+
+// defaultI2CAddress is the address the SSD1306 driver always talks to
+const defaultI2CAddress = 0x3C
+
+// DisplayConfig is one of several panels driven at once. Every other setting
+// is shared with the top level of the config.
+type DisplayConfig struct {
+	Name       string   `yaml:"name" json:"name"`
+	I2CAddress int      `yaml:"i2c_address" json:"i2c_address"` // defaults to 0x3C
+	I2CBus     string   `yaml:"i2c_bus" json:"i2c_bus"`         // defaults to the top-level i2c_bus
+	Screens    []Screen `yaml:"screens" json:"screens"`
+}
+
+// validI2CAddress reports whether addr is outside the reserved 7-bit ranges
+func validI2CAddress(addr int) bool {
+	return addr >= 0x08 && addr <= 0x77
+}
+
+// i2cAddress returns the configured address, defaulting to 0x3C
+func (c Config) i2cAddress() int {
+	if c.I2CAddress == 0 {
+		return defaultI2CAddress
+	}
+	return c.I2CAddress
+}
+
+// busAddress identifies a device by the bus it is on and its address
+type busAddress struct {
+	bus  string
+	addr int
+}
+
+// validateDisplays checks that every display has screens and its own address
+// on its bus
+func validateDisplays(config Config) []string {
+	var problems []string
+	users := make(map[busAddress]int)
+	for i, display := range config.Displays {
+		name := fmt.Sprintf("display %d (%s)", i, display.Name)
+		if len(display.Screens) == 0 {
+			problems = append(problems, fmt.Sprintf("%s: at least one screen must be configured", name))
+		} else if !anyEnabled(display.Screens) {
+			problems = append(problems, fmt.Sprintf("%s: at least one screen must be enabled", name))
+		}
+		if display.I2CAddress != 0 && !validI2CAddress(display.I2CAddress) {
+			problems = append(problems, fmt.Sprintf("%s: i2c_address must be between 0x08 and 0x77, got %#x", name, display.I2CAddress))
+		}
+		view := config.forDisplay(i)
+		key := busAddress{bus: view.I2CBus, addr: view.i2cAddress()}
+		if other, ok := users[key]; ok {
+			problems = append(problems, fmt.Sprintf("%s: i2c_address %#x is already used by display %d", name, key.addr, other))
+		} else {
+			users[key] = i
+		}
+		problems = append(problems, validateScreens(config, display.Screens, name+" ")...)
+		problems = append(problems, validateOrder(config.Order, len(display.Screens), name+": ")...)
+	}
+	return problems
+}
+
+// forDisplay returns the config a single display runs with: the shared
+// settings plus that display's enabled screens, address and bus. The HTTP,
+// metrics and MQTT servers and the buttons are only started once, by the first
+// display.
+func (c Config) forDisplay(index int) Config {
+	display := c.Displays[index]
+	view := c
+	view.Displays = nil
+	view.Screens = display.Screens
+	if display.I2CAddress != 0 {
+		view.I2CAddress = display.I2CAddress
+	}
+	if display.I2CBus != "" {
+		view.I2CBus = display.I2CBus
+	}
+	if index > 0 {
+		view.HTTPPort = 0
+		view.MetricsPort = 0
+		view.MQTT = MQTTConfig{}
+		view.NextButtonPin = ""
+		view.PrevButtonPin = ""
+	}
+	return view.withoutDisabled()
+}
+
+// NewDisplayManagers loads the configuration and opens every configured
+// display, returning one manager per panel
+func NewDisplayManagers(configPath string, networkChecker NetworkChecker) ([]*DisplayManager, error) {
+	managers, err := newDisplayManagers(configPath, networkChecker)
+	if err != nil {
+		return nil, err
+	}
+	for _, dm := range managers {
+		if err := dm.open(); err != nil {
+			return nil, err
+		}
+	}
+	return managers, nil
+}
+
+// newDisplayManagers loads the configuration and sets up rendering for every
+// display without touching any hardware. A config without displays has a
+// single manager for its top-level screens.
+func newDisplayManagers(configPath string, networkChecker NetworkChecker) ([]*DisplayManager, error) {
+	// Only files are watched for changes; stdin and URLs are read once
+	watchPath, modTime := "", time.Time{}
+	if isConfigFile(configPath) {
+		info, err := os.Stat(configPath)
+		if err != nil {
+			return nil, fmt.Errorf("error reading config file: %v", err)
+		}
+		watchPath, modTime = configPath, info.ModTime()
+	}
+	config, err := loadConfig(configPath)
+	if err != nil {
+		return nil, err
+	}
+	applyLogLevel(config)
+
+	if len(config.Displays) == 0 {
+		dm, err := newDisplayManager(config, networkChecker, newDisplayMetrics())
+		if err != nil {
+			return nil, err
+		}
+		dm.configPath, dm.configModTime = watchPath, modTime
+		return []*DisplayManager{dm}, nil
+	}
+
+	// One registry so /metrics reports what every display sampled, and one
+	// sampler so the displays don't reset each other's CPU measurements
+	metrics := newDisplayMetrics()
+	sampler := newSharedSampler(&RealMetricsProvider{temps: &RealTemperatureReader{}})
+	managers := make([]*DisplayManager, len(config.Displays))
+	for i := range config.Displays {
+		dm, err := newDisplayManager(config.forDisplay(i), networkChecker, metrics)
+		if err != nil {
+			return nil, err
+		}
+		dm.metricsSource = sampler
+		dm.configPath, dm.configModTime = watchPath, modTime
+		dm.displayIndex, dm.displayCount = i, len(config.Displays)
+		managers[i] = dm
+	}
+	return managers, nil
+}
+
+// runDisplays runs every manager in its own goroutine until ctx is done. The
+// first display to fail stops the others, and its error is returned.
+func runDisplays(ctx context.Context, managers []*DisplayManager) error {
+	if len(managers) == 1 {
+		return managers[0].Run(ctx)
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	errs := make(chan error, len(managers))
+	for _, dm := range managers {
+		go func(dm *DisplayManager) {
+			err := dm.Run(ctx)
+			cancel()
+			errs <- err
+		}(dm)
+	}
+
+	var first error
+	for range managers {
+		if err := <-errs; err != nil && first == nil {
+			first = err
+		}
+	}
+	return first
+}
+
+// addressBus sends the driver's transfers to a configured address instead of
+// the 0x3C it always uses
+type addressBus struct {
+	i2c.Bus
+	addr uint16
+}
+
+// Tx redirects transfers for the default address
+func (b *addressBus) Tx(addr uint16, w, r []byte) error {
+	if addr == defaultI2CAddress {
+		addr = b.addr
+	}
+	return b.Bus.Tx(addr, w, r)
+}
@@ -0,0 +1,293 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"image"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+
+	"periph.io/x/conn/v3/i2c"
+	"periph.io/x/conn/v3/i2c/i2ctest"
+	"periph.io/x/devices/v3/ssd1306"
+)
+
+const twoDisplayConfig = `
+screen_duration: 5
+displays:
+  - name: left
+    screens:
+      - name: Left
+        components:
+          - type: text
+            x: 5
+            y: 12
+            text: Left panel
+  - name: right
+    i2c_address: 0x3d
+    screens:
+      - name: Right
+        components:
+          - type: text
+            x: 5
+            y: 12
+            text: Right panel
+`
+
+// frameRecorder is a display that keeps a copy of the first frame drawn to it
+type frameRecorder struct {
+	*MockDisplay
+	mu    sync.Mutex
+	first []byte
+}
+
+func (d *frameRecorder) Draw(r image.Rectangle, src image.Image, sp image.Point) error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if d.first == nil {
+		d.first = append([]byte(nil), src.(*image.RGBA).Pix...)
+	}
+	return nil
+}
+
+// TestMultipleDisplays tests that each display renders its own screens and
+// that running them stops every display together
+func TestMultipleDisplays(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	if err := os.WriteFile(path, []byte(twoDisplayConfig), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	managers, err := newDisplayManagers(path, &MockNetworkChecker{})
+	if err != nil {
+		t.Fatalf("Failed to create display managers: %v", err)
+	}
+	if len(managers) != 2 {
+		t.Fatalf("Expected 2 managers, got %d", len(managers))
+	}
+	if managers[0].metrics != managers[1].metrics {
+		t.Error("Expected the displays to share one metrics registry")
+	}
+	if managers[0].metricsSource != managers[1].metricsSource {
+		t.Error("Expected the displays to share one CPU sampler")
+	}
+	if got := managers[1].config.i2cAddress(); got != 0x3d {
+		t.Errorf("Expected the second display at 0x3d, got %#x", got)
+	}
+
+	recorders := []*frameRecorder{{MockDisplay: NewMockDisplay(t)}, {MockDisplay: NewMockDisplay(t)}}
+	for i, dm := range managers {
+		dm.dev = recorders[i]
+	}
+
+	// A cancelled context still renders the first frame before shutting down
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if err := runDisplays(ctx, managers); err != nil {
+		t.Fatalf("Expected a clean shutdown, got %v", err)
+	}
+
+	for i, text := range []string{"Left panel", "Right panel"} {
+		if want := labelImage(5, 12, text); !bytes.Equal(recorders[i].first, want.Pix) {
+			t.Errorf("Display %d: expected a frame showing %q", i, text)
+		}
+		if !recorders[i].halted {
+			t.Errorf("Display %d: expected the display to be halted", i)
+		}
+	}
+}
+
+// TestForDisplay tests that only the first display starts the shared servers
+func TestForDisplay(t *testing.T) {
+	config := Config{
+		HTTPPort:      8080,
+		MetricsPort:   9100,
+		NextButtonPin: "GPIO17",
+		I2CBus:        "1",
+		MQTT:          MQTTConfig{Broker: "tcp://broker:1883"},
+		Displays: []DisplayConfig{
+			{Screens: []Screen{{Name: "A"}}},
+			{I2CAddress: 0x3d, I2CBus: "3", Screens: []Screen{{Name: "B"}}},
+		},
+	}
+
+	first := config.forDisplay(0)
+	if first.HTTPPort != 8080 || first.MetricsPort != 9100 || first.NextButtonPin == "" || first.MQTT.Broker == "" {
+		t.Errorf("Expected the first display to keep the shared servers, got %+v", first)
+	}
+	if first.Screens[0].Name != "A" || first.Displays != nil || first.i2cAddress() != defaultI2CAddress || first.I2CBus != "1" {
+		t.Errorf("Expected the first display's own screens at 0x3c on bus 1, got %+v", first)
+	}
+
+	second := config.forDisplay(1)
+	if second.HTTPPort != 0 || second.MetricsPort != 0 || second.NextButtonPin != "" || second.MQTT.Broker != "" {
+		t.Errorf("Expected the second display without shared servers, got %+v", second)
+	}
+	if second.Screens[0].Name != "B" || second.i2cAddress() != 0x3d || second.I2CBus != "3" {
+		t.Errorf("Expected the second display's own screens at 0x3d on bus 3, got %+v", second)
+	}
+}
+
+// TestValidateDisplays tests the problems reported for a displays list
+func TestValidateDisplays(t *testing.T) {
+	screens := []Screen{{Name: "A", Components: []Component{{Type: "time", X: 5, Y: 12}}}}
+	tests := []struct {
+		name    string
+		config  Config
+		wantErr string
+	}{
+		{
+			name:   "Valid",
+			config: Config{ScreenDuration: 5, Displays: []DisplayConfig{{Screens: screens}, {I2CAddress: 0x3d, Screens: screens}}},
+		},
+		{
+			name:    "Screens and displays",
+			config:  Config{ScreenDuration: 5, Screens: screens, Displays: []DisplayConfig{{Screens: screens}}},
+			wantErr: "screens and displays cannot both be set",
+		},
+		{
+			name:    "Shared address",
+			config:  Config{ScreenDuration: 5, Displays: []DisplayConfig{{Screens: screens}, {I2CAddress: 0x3c, Screens: screens}}},
+			wantErr: "display 1 (): i2c_address 0x3c is already used by display 0",
+		},
+		{
+			name:   "Same address on another bus",
+			config: Config{ScreenDuration: 5, Displays: []DisplayConfig{{Screens: screens}, {I2CBus: "3", Screens: screens}}},
+		},
+		{
+			name:    "Display without screens",
+			config:  Config{ScreenDuration: 5, Displays: []DisplayConfig{{Name: "left"}}},
+			wantErr: "display 0 (left): at least one screen must be configured",
+		},
+		{
+			name:    "Display with every screen disabled",
+			config:  Config{ScreenDuration: 5, Displays: []DisplayConfig{{Name: "left", Screens: []Screen{{Name: "A", Enabled: new(bool)}}}}},
+			wantErr: "display 0 (left): at least one screen must be enabled",
+		},
+		{
+			name:    "Reserved address",
+			config:  Config{ScreenDuration: 5, Displays: []DisplayConfig{{I2CAddress: 0x78, Screens: screens}}},
+			wantErr: "i2c_address must be between 0x08 and 0x77, got 0x78",
+		},
+		{
+			name: "Component problem names the display",
+			config: Config{ScreenDuration: 5, Displays: []DisplayConfig{{Name: "left", Screens: []Screen{
+				{Name: "A", Components: []Component{{Type: "bogus"}}},
+			}}}},
+			wantErr: `display 0 (left) screen 0 (A) component 0 (bogus): unknown type "bogus"`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := validateConfig(tt.config)
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Fatalf("Expected no error, got %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("Expected error containing %q, got %v", tt.wantErr, err)
+			}
+		})
+	}
+
+	t.Run("Rotation reported once", func(t *testing.T) {
+		config := Config{ScreenDuration: 5, Rotation: "shuffle", Displays: []DisplayConfig{{Screens: screens}, {I2CAddress: 0x3d, Screens: screens}}}
+		err := validateConfig(config)
+		if err == nil || strings.Count(err.Error(), "rotation must be sequential or random") != 1 {
+			t.Errorf("Expected the rotation problem once, got %v", err)
+		}
+	})
+}
+
+// TestAddressBus tests that transfers for 0x3C go to the configured address
+func TestAddressBus(t *testing.T) {
+	record := &i2ctest.Record{}
+	bus := &addressBus{Bus: record, addr: 0x3d}
+	if err := bus.Tx(defaultI2CAddress, []byte{0x00, 0xAE}, nil); err != nil {
+		t.Fatal(err)
+	}
+	if err := bus.Tx(0x50, []byte{0x01}, nil); err != nil {
+		t.Fatal(err)
+	}
+	if len(record.Ops) != 2 || record.Ops[0].Addr != 0x3d || record.Ops[1].Addr != 0x50 {
+		t.Errorf("Expected transfers to 0x3d then 0x50, got %+v", record.Ops)
+	}
+}
+
+// closingRecord adds Close to i2ctest.Record so it can stand in for an opened bus
+type closingRecord struct {
+	*i2ctest.Record
+}
+
+func (closingRecord) Close() error { return nil }
+
+// TestOpenI2CDisplay tests that the configured bus name is opened and the
+// driver's transfers go to the configured address
+func TestOpenI2CDisplay(t *testing.T) {
+	record := &i2ctest.Record{}
+	var opened []string
+	orig := openI2CBus
+	defer func() { openI2CBus = orig }()
+	openI2CBus = func(name string) (i2c.BusCloser, error) {
+		opened = append(opened, name)
+		return closingRecord{record}, nil
+	}
+
+	if _, err := openI2CDisplay("/dev/i2c-3", 0x3d, &ssd1306.Opts{W: 128, H: 64}); err != nil {
+		t.Fatalf("Failed to open display: %v", err)
+	}
+	if len(opened) != 1 || opened[0] != "/dev/i2c-3" {
+		t.Errorf("Expected bus /dev/i2c-3 to be opened, got %q", opened)
+	}
+	if len(record.Ops) == 0 || record.Ops[0].Addr != 0x3d {
+		t.Errorf("Expected init transfers to 0x3d, got %+v", record.Ops)
+	}
+
+	openI2CBus = func(name string) (i2c.BusCloser, error) {
+		return nil, errors.New("no such bus")
+	}
+	if _, err := openI2CDisplay("9", defaultI2CAddress, &ssd1306.Opts{W: 128, H: 64}); err == nil || !strings.Contains(err.Error(), "no such bus") {
+		t.Errorf("Expected the bus error, got %v", err)
+	}
+}
+
+// TestRunDisplaysStopsOnError tests that one display failing stops the rest
+func TestRunDisplaysStopsOnError(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	if err := os.WriteFile(path, []byte(twoDisplayConfig), 0644); err != nil {
+		t.Fatal(err)
+	}
+	managers, err := newDisplayManagers(path, &MockNetworkChecker{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	failing := &failingDisplay{MockDisplay: NewMockDisplay(t)}
+	healthy := NewMockDisplay(t)
+	managers[0].dev, managers[1].dev = failing, healthy
+
+	err = runDisplays(context.Background(), managers)
+	if err == nil || !errors.Is(err, errBusGone) {
+		t.Fatalf("Expected the failing display's error, got %v", err)
+	}
+	if !healthy.halted {
+		t.Error("Expected the healthy display to be stopped")
+	}
+}
+
+var errBusGone = errors.New("bus gone")
+
+// failingDisplay is a display whose every draw fails
+type failingDisplay struct {
+	*MockDisplay
+}
+
+func (d *failingDisplay) Draw(r image.Rectangle, src image.Image, sp image.Point) error {
+	return errBusGone
+}
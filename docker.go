@@ -0,0 +1,92 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"time"
+)
+
+const defaultDockerSocket = "/var/run/docker.sock"
+
+// dockerContainer is the part of a Docker API container summary the docker
+// component reads
+type dockerContainer struct {
+	ID    string `json:"Id"`
+	Names []string
+	State string
+}
+
+// DockerClient interface for listing containers through the Docker API
+type DockerClient interface {
+	Containers(socket string) ([]dockerContainer, error)
+}
+
+// RealDockerClient implements DockerClient by talking HTTP over the daemon's
+// unix socket
+type RealDockerClient struct{}
+
+// Containers lists the running containers of the daemon listening on socket
+func (c *RealDockerClient) Containers(socket string) ([]dockerContainer, error) {
+	client := &http.Client{
+		Timeout: httpFetchTimeout,
+		Transport: &http.Transport{
+			DialContext: func(ctx context.Context, _, _ string) (net.Conn, error) {
+				var dialer net.Dialer
+				return dialer.DialContext(ctx, "unix", socket)
+			},
+		},
+	}
+	defer client.CloseIdleConnections()
+
+	// The host is ignored since every request goes to the socket
+	resp, err := client.Get("http://docker/containers/json")
+	if err != nil {
+		return nil, fmt.Errorf("failed to list containers: %v", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("failed to list containers: %s", resp.Status)
+	}
+	var containers []dockerContainer
+	if err := json.NewDecoder(io.LimitReader(resp.Body, maxFetchBytes)).Decode(&containers); err != nil {
+		return nil, fmt.Errorf("failed to parse container list: %v", err)
+	}
+	return containers, nil
+}
+
+// dockerRunning returns the number of running containers behind socket,
+// listing them in the background once per refresh interval so a slow daemon
+// never holds up the display. ok is false until a request has succeeded; err
+// is the latest request's error.
+func (dm *DisplayManager) dockerRunning(comp Component, socket string) (running int, ok bool, updated time.Time, err error) {
+	if dm.dockers == nil {
+		dm.dockers = make(map[string]*fetchCache[int])
+	}
+	cache, found := dm.dockers[socket]
+	if !found {
+		cache = &fetchCache[int]{}
+		dm.dockers[socket] = cache
+	}
+	client := dm.dockerClient
+	running, ok = cache.get("docker "+socket, dm.timeNow(), refreshInterval(comp), refreshInterval(comp), dm.goAsync, func() (int, error) {
+		containers, err := client.Containers(socket)
+		return runningContainers(containers), err
+	})
+	updated, err = cache.failure()
+	return running, ok, updated, err
+}
+
+// runningContainers counts the containers in the running state
+func runningContainers(containers []dockerContainer) int {
+	running := 0
+	for _, c := range containers {
+		if c.State == "running" {
+			running++
+		}
+	}
+	return running
+}
@@ -0,0 +1,182 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"net"
+	"net/http"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// MockDockerClient implements DockerClient for testing
+type MockDockerClient struct {
+	containers []dockerContainer
+	err        error
+	sockets    []string
+}
+
+func (m *MockDockerClient) Containers(socket string) ([]dockerContainer, error) {
+	m.sockets = append(m.sockets, socket)
+	return m.containers, m.err
+}
+
+// TestDockerComponent tests counting running containers and the N/A fallback
+func TestDockerComponent(t *testing.T) {
+	containers := []dockerContainer{
+		{ID: "a1", Names: []string{"/web"}, State: "running"},
+		{ID: "b2", Names: []string{"/db"}, State: "running"},
+		{ID: "c3", Names: []string{"/backup"}, State: "exited"},
+		{ID: "d4", Names: []string{"/cache"}, State: "running"},
+	}
+
+	tests := []struct {
+		name       string
+		client     *MockDockerClient
+		comp       Component
+		wantLabel  string
+		wantSocket string
+	}{
+		{
+			name:       "Running containers",
+			client:     &MockDockerClient{containers: containers},
+			comp:       Component{Type: "docker", X: 5, Y: 12},
+			wantLabel:  "Docker: 3 up",
+			wantSocket: defaultDockerSocket,
+		},
+		{
+			name:       "Label and socket",
+			client:     &MockDockerClient{},
+			comp:       Component{Type: "docker", X: 5, Y: 12, Label: "Ctr", Source: "/run/user/1000/docker.sock"},
+			wantLabel:  "Ctr: 0 up",
+			wantSocket: "/run/user/1000/docker.sock",
+		},
+		{
+			name:       "Socket unavailable",
+			client:     &MockDockerClient{err: fmt.Errorf("dial unix: no such file or directory")},
+			comp:       Component{Type: "docker", X: 5, Y: 12},
+			wantLabel:  "Docker: N/A",
+			wantSocket: defaultDockerSocket,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dm := &DisplayManager{
+				dockerClient: tt.client,
+				asyncFunc:    func(f func()) { f() },
+				img:          blankFrame(),
+				timeNow:      time.Now,
+			}
+			if err := dm.renderComponent(tt.comp); err != nil {
+				t.Fatalf("Failed to render component: %v", err)
+			}
+			if want := labelImage(5, 12, tt.wantLabel); !bytes.Equal(dm.img.Pix, want.Pix) {
+				t.Errorf("Rendered image does not match %q", tt.wantLabel)
+			}
+			if len(tt.client.sockets) != 1 || tt.client.sockets[0] != tt.wantSocket {
+				t.Errorf("Expected one request to %s, got %v", tt.wantSocket, tt.client.sockets)
+			}
+		})
+	}
+}
+
+// TestDockerComponentCache tests that the container list is reused until the
+// default refresh interval passes
+func TestDockerComponentCache(t *testing.T) {
+	client := &MockDockerClient{containers: []dockerContainer{{ID: "a1", State: "running"}}}
+	now := time.Date(2024, 3, 9, 14, 0, 0, 0, time.UTC)
+	dm := &DisplayManager{
+		dockerClient: client,
+		asyncFunc:    func(f func()) { f() },
+		img:          blankFrame(),
+		timeNow:      func() time.Time { return now },
+	}
+	comp := Component{Type: "docker", X: 5, Y: 12}
+
+	for _, step := range []struct {
+		advance   time.Duration
+		wantCalls int
+	}{
+		{0, 1},
+		{4 * time.Second, 1},
+		{time.Second, 2},
+	} {
+		now = now.Add(step.advance)
+		dm.clearImage()
+		if err := dm.renderComponent(comp); err != nil {
+			t.Fatalf("Failed to render component: %v", err)
+		}
+		if len(client.sockets) != step.wantCalls {
+			t.Errorf("After %v: expected %d requests, got %d", step.advance, step.wantCalls, len(client.sockets))
+		}
+		if want := labelImage(5, 12, "Docker: 1 up"); !bytes.Equal(dm.img.Pix, want.Pix) {
+			t.Errorf("After %v: expected the cached count to be drawn", step.advance)
+		}
+	}
+}
+
+// TestDockerDoesNotBlock tests that containers are listed in the background,
+// with the placeholder shown until the first request finishes
+func TestDockerDoesNotBlock(t *testing.T) {
+	var pending []func()
+	client := &MockDockerClient{containers: []dockerContainer{{ID: "a1", State: "running"}}}
+	dm := &DisplayManager{
+		dockerClient: client,
+		asyncFunc:    func(f func()) { pending = append(pending, f) },
+		img:          blankFrame(),
+		timeNow:      time.Now,
+	}
+	comp := Component{Type: "docker", X: 5, Y: 12}
+	if err := dm.renderComponent(comp); err != nil {
+		t.Fatalf("Failed to render component: %v", err)
+	}
+	if len(client.sockets) != 0 || len(pending) != 1 {
+		t.Fatalf("Expected the request to be queued, not made, got %d requests", len(client.sockets))
+	}
+	if want := labelImage(5, 12, "Docker: N/A"); !bytes.Equal(dm.img.Pix, want.Pix) {
+		t.Error("Expected the placeholder while the first request runs")
+	}
+
+	pending[0]()
+	dm.clearImage()
+	if err := dm.renderComponent(comp); err != nil {
+		t.Fatalf("Failed to render component: %v", err)
+	}
+	if want := labelImage(5, 12, "Docker: 1 up"); !bytes.Equal(dm.img.Pix, want.Pix) {
+		t.Error("Expected the count once the request finished")
+	}
+}
+
+// TestRealDockerClient tests listing containers over a unix socket
+func TestRealDockerClient(t *testing.T) {
+	socket := filepath.Join(t.TempDir(), "docker.sock")
+	listener, err := net.Listen("unix", socket)
+	if err != nil {
+		t.Skipf("unix sockets unavailable: %v", err)
+	}
+	var gotPath string
+	server := &http.Server{Handler: http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotPath = r.URL.Path
+		fmt.Fprint(w, `[{"Id":"a1","Names":["/web"],"State":"running","Image":"nginx"},{"Id":"b2","Names":["/db"],"State":"running"}]`)
+	})}
+	go server.Serve(listener)
+	defer server.Close()
+
+	client := &RealDockerClient{}
+	containers, err := client.Containers(socket)
+	if err != nil {
+		t.Fatalf("Failed to list containers: %v", err)
+	}
+	if gotPath != "/containers/json" {
+		t.Errorf("Expected a request to /containers/json, got %s", gotPath)
+	}
+	if len(containers) != 2 || containers[0].Names[0] != "/web" || containers[1].State != "running" {
+		t.Errorf("Unexpected containers: %+v", containers)
+	}
+
+	if _, err := client.Containers(filepath.Join(t.TempDir(), "missing.sock")); err == nil {
+		t.Error("Expected an error for a missing socket")
+	}
+}
@@ -0,0 +1,55 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"reflect"
+	"strings"
+)
+
+// expandConfigEnv replaces $VAR and ${VAR} in every string of the config,
+// so one file can be shared across hosts. $$ is a literal dollar sign.
+// Undefined variables expand to empty, or are reported when strict_env is set.
+func expandConfigEnv(config *Config, lookup func(string) (string, bool)) error {
+	var missing []string
+	seen := make(map[string]bool)
+	mapping := func(name string) string {
+		if name == "$" {
+			return "$"
+		}
+		value, ok := lookup(name)
+		if !ok && !seen[name] {
+			seen[name] = true
+			missing = append(missing, name)
+		}
+		return value
+	}
+	expandStrings(reflect.ValueOf(config).Elem(), mapping)
+
+	if config.StrictEnv && len(missing) > 0 {
+		return fmt.Errorf("undefined environment variables in config: %s", strings.Join(missing, ", "))
+	}
+	return nil
+}
+
+// expandStrings expands every exported string reachable from v in place
+func expandStrings(v reflect.Value, mapping func(string) string) {
+	switch v.Kind() {
+	case reflect.String:
+		v.SetString(os.Expand(v.String(), mapping))
+	case reflect.Struct:
+		for i := 0; i < v.NumField(); i++ {
+			if v.Type().Field(i).IsExported() {
+				expandStrings(v.Field(i), mapping)
+			}
+		}
+	case reflect.Slice:
+		for i := 0; i < v.Len(); i++ {
+			expandStrings(v.Index(i), mapping)
+		}
+	case reflect.Pointer:
+		if !v.IsNil() {
+			expandStrings(v.Elem(), mapping)
+		}
+	}
+}
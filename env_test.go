@@ -0,0 +1,83 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+// TestExpandConfigEnv tests expansion of environment variables in config strings
+func TestExpandConfigEnv(t *testing.T) {
+	env := map[string]string{"NET_IFACE": "wlan0", "HOST": "pi4", "DATA": "/mnt/data"}
+	lookup := func(name string) (string, bool) {
+		value, ok := env[name]
+		return value, ok
+	}
+
+	tests := []struct {
+		name    string
+		config  Config
+		check   func(Config) string
+		want    string
+		wantErr string
+	}{
+		{
+			name:   "Braced variable",
+			config: Config{NetworkInterface: "${NET_IFACE}"},
+			check:  func(c Config) string { return c.NetworkInterface },
+			want:   "wlan0",
+		},
+		{
+			name:   "Bare variable in a component label",
+			config: Config{Screens: []Screen{{Components: []Component{{Label: "$HOST CPU"}}}}},
+			check:  func(c Config) string { return c.Screens[0].Components[0].Label },
+			want:   "pi4 CPU",
+		},
+		{
+			name:   "Slice entries",
+			config: Config{Screens: []Screen{{Components: []Component{{Interfaces: []string{"eth0", "$NET_IFACE"}}}}}},
+			check:  func(c Config) string { return strings.Join(c.Screens[0].Components[0].Interfaces, ",") },
+			want:   "eth0,wlan0",
+		},
+		{
+			name:   "Nested struct",
+			config: Config{Screens: []Screen{{Components: []Component{{Mountpoint: "${DATA}/disk"}}}}},
+			check:  func(c Config) string { return c.Screens[0].Components[0].Mountpoint },
+			want:   "/mnt/data/disk",
+		},
+		{
+			name:   "Undefined expands to empty",
+			config: Config{MQTT: MQTTConfig{TopicPrefix: "oled/${MISSING}"}},
+			check:  func(c Config) string { return c.MQTT.TopicPrefix },
+			want:   "oled/",
+		},
+		{
+			name:   "Literal dollar",
+			config: Config{Screens: []Screen{{Components: []Component{{Text: "Cost: $$5"}}}}},
+			check:  func(c Config) string { return c.Screens[0].Components[0].Text },
+			want:   "Cost: $5",
+		},
+		{
+			name:    "Undefined with strict_env",
+			config:  Config{StrictEnv: true, NetworkInterface: "$MISSING", FontPath: "${OTHER}/${MISSING}"},
+			wantErr: "undefined environment variables in config: MISSING, OTHER",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := expandConfigEnv(&tt.config, lookup)
+			if tt.wantErr != "" {
+				if err == nil || err.Error() != tt.wantErr {
+					t.Fatalf("Expected error %q, got %v", tt.wantErr, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if got := tt.check(tt.config); got != tt.want {
+				t.Errorf("Expected %q, got %q", tt.want, got)
+			}
+		})
+	}
+}
@@ -0,0 +1,64 @@
+package main
+
+import (
+	"log/slog"
+	"sync"
+	"time"
+)
+
+// fetchCache keeps the last good result of a slow fetch, such as an HTTP
+// request, refreshing it in the background so the render loop never waits on
+// it. Fetches finish on another goroutine, so it is guarded by mu.
+type fetchCache[T any] struct {
+	mu      sync.Mutex
+	value   T
+	ok      bool      // a fetch has succeeded
+	attempt time.Time // when the latest fetch began
+	updated time.Time // when the last successful fetch began
+	err     error     // the latest fetch's error, nil once one succeeds
+	running bool
+}
+
+// get returns the last good value, starting fetch through async once interval
+// has passed since the last attempt, or retry after a failed one. A failed
+// fetch keeps the last good value; the first of a run of failures is logged
+// under name. ok is false until a fetch has succeeded.
+func (c *fetchCache[T]) get(name string, now time.Time, interval, retry time.Duration, async func(func()), fetch func() (T, error)) (value T, ok bool) {
+	c.mu.Lock()
+	wait := interval
+	if c.err != nil && retry < wait {
+		wait = retry
+	}
+	due := !c.running && (c.attempt.IsZero() || now.Sub(c.attempt) >= wait)
+	if due {
+		c.running, c.attempt = true, now
+	}
+	c.mu.Unlock()
+
+	if due {
+		async(func() {
+			value, err := fetch()
+			c.mu.Lock()
+			defer c.mu.Unlock()
+			if err != nil && c.err == nil {
+				slog.Warn("background fetch failed", "source", name, "err", err)
+			}
+			c.running, c.err = false, err
+			if err == nil {
+				c.value, c.ok, c.updated = value, true, now
+			}
+		})
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.value, c.ok
+}
+
+// failure returns the latest fetch's error, nil if it succeeded, and when the
+// value get returns was fetched
+func (c *fetchCache[T]) failure() (updated time.Time, err error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.updated, c.err
+}
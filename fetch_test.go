@@ -0,0 +1,57 @@
+package main
+
+import (
+	"fmt"
+	"testing"
+	"time"
+)
+
+// TestFetchCache tests that fetches run through async without blocking get,
+// that only one runs at a time, and that failures are retried sooner
+func TestFetchCache(t *testing.T) {
+	var pending []func()
+	async := func(f func()) { pending = append(pending, f) }
+	runPending := func() {
+		for _, f := range pending {
+			f()
+		}
+		pending = nil
+	}
+	calls := 0
+	result, fetchErr := "first", error(nil)
+	fetch := func() (string, error) {
+		calls++
+		return result, fetchErr
+	}
+
+	var c fetchCache[string]
+	start := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+	get := func(after time.Duration) (string, bool) {
+		return c.get("test", start.Add(after), 10*time.Minute, time.Minute, async, fetch)
+	}
+
+	if _, ok := get(0); ok || len(pending) != 1 {
+		t.Fatalf("Expected no value and one fetch started, got ok=%v and %d fetches", ok, len(pending))
+	}
+	if get(time.Second); len(pending) != 1 {
+		t.Fatalf("Expected no second fetch while one is running, got %d", len(pending))
+	}
+	runPending()
+	if value, ok := get(2 * time.Second); !ok || value != "first" {
+		t.Fatalf("Expected the fetched value, got %q (ok %v)", value, ok)
+	}
+
+	// A failure keeps the last value and is retried after a minute
+	result, fetchErr = "", fmt.Errorf("connection refused")
+	get(10 * time.Minute)
+	runPending()
+	if value, ok := get(10*time.Minute + 30*time.Second); !ok || value != "first" || len(pending) != 0 {
+		t.Fatalf("Expected the last value and no retry yet, got %q (ok %v) with %d fetches", value, ok, len(pending))
+	}
+	result, fetchErr = "second", nil
+	get(11 * time.Minute)
+	runPending()
+	if value, _ := get(11*time.Minute + time.Second); value != "second" || calls != 3 {
+		t.Errorf("Expected the retried value after 3 fetches, got %q after %d", value, calls)
+	}
+}
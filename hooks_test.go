@@ -0,0 +1,87 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+)
+
+// TestOnScreenChange tests that the hook reports the first screen and each
+// rotation, but not a redraw of the same screen
+func TestOnScreenChange(t *testing.T) {
+	timer := &MockTimer{c: make(chan time.Time), resets: make(chan time.Duration, 1)}
+	ticker := &MockTicker{c: make(chan time.Time)}
+	type change struct {
+		index int
+		name  string
+	}
+	var changes []change
+	dm := &DisplayManager{
+		dev:       NewMockDisplay(t),
+		img:       blankFrame(),
+		timeNow:   time.Now,
+		newTimer:  func(d time.Duration) screenTimer { return timer },
+		newTicker: func(d time.Duration) updateTicker { return ticker },
+		config: Config{
+			ScreenDuration: 5,
+			Screens: []Screen{
+				{Name: "Summary", Components: []Component{{Type: "text", X: 5, Y: 20, Text: "A"}}},
+				{Name: "Detail", Components: []Component{{Type: "text", X: 5, Y: 20, Text: "B"}}},
+			},
+		},
+		OnScreenChange: func(index int, name string) {
+			changes = append(changes, change{index, name})
+		},
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error)
+	go func() {
+		done <- dm.Run(ctx)
+	}()
+	timer.c <- time.Now()
+	<-timer.resets
+	ticker.c <- time.Now()
+	timer.c <- time.Now()
+	<-timer.resets
+	cancel()
+	if err := <-done; err != nil {
+		t.Fatalf("Run failed: %v", err)
+	}
+
+	want := []change{{0, "Summary"}, {1, "Detail"}, {0, "Summary"}}
+	if fmt.Sprint(changes) != fmt.Sprint(want) {
+		t.Errorf("Expected screen changes %v, got %v", want, changes)
+	}
+}
+
+// TestOnRenderError tests that the hook gets the failing component and its
+// error while the rest of the screen still renders
+func TestOnRenderError(t *testing.T) {
+	var failed []Component
+	var errs []error
+	dm := &DisplayManager{
+		metricsSource: &MockMetricsProvider{err: fmt.Errorf("sensor gone")},
+		img:           blankFrame(),
+		config: Config{Screens: []Screen{{Name: "Main", Components: []Component{
+			{Type: "text", X: 5, Y: 10, Text: "OK"},
+			{Type: "cpu", X: 5, Y: 30, Label: "CPU"},
+		}}}},
+		OnRenderError: func(comp Component, err error) {
+			failed = append(failed, comp)
+			errs = append(errs, err)
+		},
+	}
+	dm.renderFrame()
+
+	if len(failed) != 1 {
+		t.Fatalf("Expected 1 render error, got %d", len(failed))
+	}
+	if failed[0].Type != "cpu" || failed[0].Label != "CPU" {
+		t.Errorf("Expected the cpu component, got %+v", failed[0])
+	}
+	if errs[0] == nil || errs[0].Error() != "sensor gone" {
+		t.Errorf("Expected the sensor error, got %v", errs[0])
+	}
+}
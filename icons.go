@@ -0,0 +1,38 @@
+package main
+
+import (
+	"embed"
+	"fmt"
+	"path"
+	"strings"
+
+	"github.com/swilcox/go-monitor-ssd1306/render"
+)
+
+//go:embed icons/*.xbm
+var iconFiles embed.FS
+
+// icons holds the embedded icon set keyed by file name without extension
+var icons = mustLoadIcons()
+
+// mustLoadIcons parses every embedded XBM file. The files ship with the
+// binary, so a parse failure is a build mistake.
+func mustLoadIcons() map[string]*render.Icon {
+	entries, err := iconFiles.ReadDir("icons")
+	if err != nil {
+		panic(err)
+	}
+	set := make(map[string]*render.Icon, len(entries))
+	for _, entry := range entries {
+		data, err := iconFiles.ReadFile(path.Join("icons", entry.Name()))
+		if err != nil {
+			panic(err)
+		}
+		ic, err := render.ParseXBM(string(data))
+		if err != nil {
+			panic(fmt.Sprintf("icon %s: %v", entry.Name(), err))
+		}
+		set[strings.TrimSuffix(entry.Name(), ".xbm")] = ic
+	}
+	return set
+}
@@ -0,0 +1,53 @@
+package main
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/swilcox/go-monitor-ssd1306/render"
+)
+
+// TestEmbeddedIcons tests that every shipped icon parses to an 8x8 bitmap
+func TestEmbeddedIcons(t *testing.T) {
+	for _, name := range []string{"thermometer", "chip", "disk", "wifi"} {
+		ic, ok := icons[name]
+		if !ok {
+			t.Errorf("Expected icon %q to be embedded", name)
+			continue
+		}
+		if ic.Width != 8 || ic.Height != 8 {
+			t.Errorf("Icon %q: expected 8x8, got %dx%d", name, ic.Width, ic.Height)
+		}
+	}
+}
+
+// TestIconComponent tests that an icon is drawn on the baseline and shifts the text right
+func TestIconComponent(t *testing.T) {
+	dm := &DisplayManager{
+		img: blankFrame(),
+	}
+	comp := Component{Type: "text", X: 5, Y: 20, Text: "eth0", Icon: "wifi"}
+	if err := dm.renderComponent(comp); err != nil {
+		t.Fatalf("Failed to render component: %v", err)
+	}
+
+	// The top row of the wifi icon lights columns 2-5
+	for x := 0; x < 8; x++ {
+		lit := dm.img.RGBAAt(5+x, 12).R != 0
+		if want := x >= 2 && x <= 5; lit != want {
+			t.Errorf("Icon pixel %d,12: expected lit=%v, got %v", 5+x, want, lit)
+		}
+	}
+	// Nothing is drawn left of X
+	for y := 0; y < height; y++ {
+		if dm.img.RGBAAt(4, y).R != 0 {
+			t.Fatalf("Pixel 4,%d left of the icon was set", y)
+		}
+	}
+
+	want := labelImage(5+8+render.IconGap, 20, "eth0")
+	render.DrawIcon(want, icons["wifi"], 5, 12)
+	if !bytes.Equal(dm.img.Pix, want.Pix) {
+		t.Error("Expected the text to start after the icon")
+	}
+}
@@ -0,0 +1,33 @@
+package main
+
+import (
+	"log/slog"
+	"os"
+)
+
+// logLevel is shared by the default logger so a config reload can change it
+var logLevel = new(slog.LevelVar)
+
+// setupLogging installs a text logger on stderr filtered by logLevel. The
+// standard log package writes through it as well.
+func setupLogging() {
+	slog.SetDefault(slog.New(slog.NewTextHandler(os.Stderr, &slog.HandlerOptions{Level: logLevel})))
+}
+
+// parseLogLevel converts a log_level setting (debug, info, warn or error) to
+// a slog level, defaulting to info
+func parseLogLevel(name string) (slog.Level, error) {
+	var level slog.Level
+	if name == "" {
+		return slog.LevelInfo, nil
+	}
+	err := level.UnmarshalText([]byte(name))
+	return level, err
+}
+
+// applyLogLevel sets the logger's level from a validated config
+func applyLogLevel(config Config) {
+	if level, err := parseLogLevel(config.LogLevel); err == nil {
+		logLevel.Set(level)
+	}
+}
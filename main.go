@@ -1,338 +1,2788 @@
 package main
 
 import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
 	"fmt"
 	"image"
-	"image/color"
+	"image/draw"
+	"log"
+	"log/slog"
+	"math/rand"
 	"net"
+	"net/http"
 	"os"
+	"os/exec"
+	"os/signal"
+	"path/filepath"
+	"runtime"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"syscall"
 	"time"
 
 	"golang.org/x/image/font"
 	"golang.org/x/image/font/basicfont"
-	"golang.org/x/image/math/fixed"
+	"golang.org/x/image/font/opentype"
 	"gopkg.in/yaml.v3"
 
 	"github.com/shirou/gopsutil/v3/cpu"
 	"github.com/shirou/gopsutil/v3/disk"
+	pshost "github.com/shirou/gopsutil/v3/host"
+	"github.com/shirou/gopsutil/v3/load"
 	"github.com/shirou/gopsutil/v3/mem"
+	psnet "github.com/shirou/gopsutil/v3/net"
+	"github.com/shirou/gopsutil/v3/process"
+	"github.com/swilcox/go-monitor-ssd1306/render"
+	"periph.io/x/conn/v3/gpio"
+	"periph.io/x/conn/v3/gpio/gpioreg"
+	"periph.io/x/conn/v3/i2c"
 	"periph.io/x/conn/v3/i2c/i2creg"
+	"periph.io/x/conn/v3/spi/spireg"
 	"periph.io/x/devices/v3/ssd1306"
 	"periph.io/x/host/v3"
 )
 
 const (
-	width          = 128
-	height         = 64
-	barHeight      = 7
-	brightContrast = 255
-	dimContrast    = 1
-	tempFile       = "/sys/class/thermal/thermal_zone0/temp"
+	width                 = 128 // default display width
+	height                = 64  // default display height
+	brightContrast        = 255
+	dimContrast           = 1
+	tempFile              = render.DefaultTempFile
+	powerSupplyDir        = "/sys/class/power_supply"
+	fanInputGlob          = "/sys/class/hwmon/hwmon*/fan1_input"
+	defaultExecTimeout    = 5 * time.Second
+	defaultExecRefresh    = 10 * time.Second
+	defaultDockerRefresh  = 5 * time.Second
+	defaultGPUTempRefresh = 5 * time.Second
+	gpuTempTimeout        = 2 * time.Second
+	defaultAddrCacheTTL   = 5 * time.Second
+	sysClassNet           = "/sys/class/net"
+	defaultMaxMbps        = 100.0
+	defaultFontSize       = 12.0
+
+	configCheckInterval = 2 * time.Second
 )
 
-// Config represents the main configuration
-type Config struct {
-	ScreenDuration   int      `yaml:"screen_duration"`
-	NetworkInterface string   `yaml:"network_interface"`
-	InvertDuration   int      `yaml:"invert_duration"`  // seconds between invert toggles, 0 to disable
-	DayStartHour     int      `yaml:"day_start_hour"`   // hour to switch to bright mode (0-23)
-	NightStartHour   int      `yaml:"night_start_hour"` // hour to switch to dim mode (0-23)
-	Screens          []Screen `yaml:"screens"`
+// Config represents the main configuration
+type Config struct {
+	Version           int             `yaml:"version" json:"version"` // config schema version, 1 when unset
+	ScreenDuration    int             `yaml:"screen_duration" json:"screen_duration"`
+	NetworkInterface  string          `yaml:"network_interface" json:"network_interface"`
+	InvertDuration    int             `yaml:"invert_duration" json:"invert_duration"`         // seconds between invert toggles, 0 to disable
+	UpdateInterval    int             `yaml:"update_interval" json:"update_interval"`         // seconds between redraws, defaults to 1
+	SplashDuration    int             `yaml:"splash_duration" json:"splash_duration"`         // seconds to show the startup splash, 0 to disable
+	SplashText        string          `yaml:"splash_text" json:"splash_text"`                 // message shown on the splash instead of the bundled logo
+	SelfTest          bool            `yaml:"selftest" json:"selftest"`                       // light every pixel, then a checkerboard, then clear at startup
+	InvertDaytimeOnly bool            `yaml:"invert_daytime_only" json:"invert_daytime_only"` // keep the display uninverted during night hours
+	DayStartHour      int             `yaml:"day_start_hour" json:"day_start_hour"`           // hour to switch to bright mode (0-23)
+	NightStartHour    int             `yaml:"night_start_hour" json:"night_start_hour"`       // hour to switch to dim mode (0-23)
+	TransitionMinutes int             `yaml:"transition_minutes" json:"transition_minutes"`   // minutes to ramp contrast after each switch, 0 for a hard switch
+	AntiBurnin        bool            `yaml:"anti_burnin" json:"anti_burnin"`                 // shift the whole image by a pixel on a slow cycle to spread wear
+	AntiBurninMinutes int             `yaml:"anti_burnin_minutes" json:"anti_burnin_minutes"` // minutes between shifts, defaults to 3
+	DayContrast       *int            `yaml:"day_contrast" json:"day_contrast"`               // contrast in bright mode (0-255), defaults to 255
+	NightContrast     *int            `yaml:"night_contrast" json:"night_contrast"`           // contrast in dim mode (0-255), defaults to 1
+	FontPath          string          `yaml:"font_path" json:"font_path"`                     // TTF/OTF font file, basicfont when empty
+	FontSize          float64         `yaml:"font_size" json:"font_size"`                     // font size in points, defaults to 12
+	DisplayWidth      int             `yaml:"display_width" json:"display_width"`             // panel width in pixels, defaults to 128
+	DisplayHeight     int             `yaml:"display_height" json:"display_height"`           // panel height in pixels, defaults to 64
+	Connection        string          `yaml:"connection" json:"connection"`                   // "i2c" (default) or "spi"
+	I2CAddress        int             `yaml:"i2c_address" json:"i2c_address"`                 // SSD1306 I2C address, defaults to 0x3C
+	I2CBus            string          `yaml:"i2c_bus" json:"i2c_bus"`                         // I2C bus name or number, first available when empty
+	SPIBus            string          `yaml:"spi_bus" json:"spi_bus"`                         // SPI port name, first available when empty
+	DCPin             string          `yaml:"dc_pin" json:"dc_pin"`                           // SPI data/command GPIO, 3-wire SPI when empty
+	Rotate180         bool            `yaml:"rotate_180" json:"rotate_180"`                   // turn the image upside down for panels mounted that way
+	DisplayRotation   int             `yaml:"display_rotation" json:"display_rotation"`       // degrees clockwise to turn the image: 0, 90, 180 or 270
+	TemperatureUnit   string          `yaml:"temperature_unit" json:"temperature_unit"`       // "C" (default) or "F"
+	Placeholder       string          `yaml:"placeholder" json:"placeholder"`                 // shown when a value can't be read, defaults to N/A
+	Clock24h          *bool           `yaml:"clock_24h" json:"clock_24h"`                     // false defaults time and calendar components to a 12-hour clock
+	Timezone          string          `yaml:"timezone" json:"timezone"`                       // IANA zone for time and date components, local time when empty
+	HTTPPort          int             `yaml:"http_port" json:"http_port"`                     // port for the /healthz and /status server, 0 to disable
+	MetricsPort       int             `yaml:"metrics_port" json:"metrics_port"`               // port for the Prometheus /metrics endpoint, 0 to disable
+	DebugOutput       string          `yaml:"debug_output" json:"debug_output"`               // file or named pipe each new frame is written to, PNG for a .png path
+	MQTT              MQTTConfig      `yaml:"mqtt" json:"mqtt"`                               // optional MQTT publishing of sensor values
+	Weather           WeatherConfig   `yaml:"weather" json:"weather"`                         // where weather components fetch conditions from
+	Calendar          CalendarConfig  `yaml:"calendar" json:"calendar"`                       // iCal feed calendar components read from
+	NextButtonPin     string          `yaml:"next_button_pin" json:"next_button_pin"`         // GPIO of a button that shows the next screen
+	PrevButtonPin     string          `yaml:"prev_button_pin" json:"prev_button_pin"`         // GPIO of a button that shows the previous screen
+	Transition        string          `yaml:"transition" json:"transition"`                   // screen rotation animation: "none" (default), "slide_left" or "fade"
+	Rotation          string          `yaml:"rotation" json:"rotation"`                       // "sequential" (default) or "random", which never repeats a screen immediately
+	Order             []int           `yaml:"order" json:"order"`                             // screen indexes to rotate through, e.g. [2, 0, 1]; may repeat screens
+	LogLevel          string          `yaml:"log_level" json:"log_level"`                     // "debug", "info" (default), "warn" or "error"
+	StrictEnv         bool            `yaml:"strict_env" json:"strict_env"`                   // fail on undefined $VARs instead of expanding them to empty
+	InitRetries       int             `yaml:"init_retries" json:"init_retries"`               // extra attempts to open the display when the bus isn't ready, 0 to fail at once
+	InitRetryDelay    int             `yaml:"init_retry_delay" json:"init_retry_delay"`       // seconds before the first retry, doubling after each; defaults to 1
+	Screens           []Screen        `yaml:"screens" json:"screens"`
+	Displays          []DisplayConfig `yaml:"displays" json:"displays"` // several panels, each with its own screens, instead of screens
+}
+
+// supportedDisplaySizes lists the SSD1306 panel sizes that can be configured
+var supportedDisplaySizes = []image.Point{
+	{X: 128, Y: 64},
+	{X: 128, Y: 32},
+	{X: 96, Y: 16},
+	{X: 64, Y: 48},
+	{X: 64, Y: 32},
+}
+
+// displaySize returns the configured panel dimensions, defaulting to 128x64
+func (c Config) displaySize() (int, int) {
+	w, h := c.DisplayWidth, c.DisplayHeight
+	if w == 0 {
+		w = width
+	}
+	if h == 0 {
+		h = height
+	}
+	return w, h
+}
+
+// displayRotation returns how far the image is turned clockwise before it is
+// sent to the panel, treating rotate_180 as 180 degrees
+func (c Config) displayRotation() int {
+	if c.DisplayRotation == 0 && c.Rotate180 {
+		return 180
+	}
+	return c.DisplayRotation
+}
+
+// logicalSize returns the size screens are laid out in, which is the panel
+// size with width and height swapped when it is mounted on its side
+func (c Config) logicalSize() (int, int) {
+	w, h := c.displaySize()
+	if rotation := c.displayRotation(); rotation == 90 || rotation == 270 {
+		return h, w
+	}
+	return w, h
+}
+
+// placeholder returns the text shown in place of a value that can't be read
+func (c Config) placeholder() string {
+	if c.Placeholder == "" {
+		return render.DefaultPlaceholder
+	}
+	return c.Placeholder
+}
+
+// clockLayout returns the default Go layout for a time of day, 24-hour unless
+// clock_24h is false. withSeconds adds the seconds.
+func (c Config) clockLayout(withSeconds bool) string {
+	if c.Clock24h != nil && !*c.Clock24h {
+		if withSeconds {
+			return "3:04:05 PM"
+		}
+		return "3:04 PM"
+	}
+	if withSeconds {
+		return "15:04:05"
+	}
+	return "15:04"
+}
+
+// updateInterval returns how often the current screen is redrawn
+func (c Config) updateInterval() time.Duration {
+	if c.UpdateInterval == 0 {
+		return time.Second
+	}
+	return time.Duration(c.UpdateInterval) * time.Second
+}
+
+// contrastLevels returns the configured day and night contrast, falling back
+// to the bright/dim defaults
+func (c Config) contrastLevels() (int, int) {
+	day, night := brightContrast, dimContrast
+	if c.DayContrast != nil {
+		day = *c.DayContrast
+	}
+	if c.NightContrast != nil {
+		night = *c.NightContrast
+	}
+	return day, night
+}
+
+// Screen represents a single virtual screen configuration
+type Screen struct {
+	Name       string      `yaml:"name" json:"name"`
+	Enabled    *bool       `yaml:"enabled,omitempty" json:"enabled,omitempty"`       // false leaves the screen out of the rotation, defaults to true
+	Duration   int         `yaml:"duration,omitempty" json:"duration,omitempty"`     // seconds, overrides screen_duration
+	Background string      `yaml:"background,omitempty" json:"background,omitempty"` // "black" (default) or "white" for dark-on-light drawing
+	DimText    bool        `yaml:"dim_text,omitempty" json:"dim_text,omitempty"`     // dither the text of every component on the screen
+	Components []Component `yaml:"components" json:"components"`
+}
+
+// Component represents a display component configuration
+type Component = render.Component
+
+// isEnabled reports whether the screen is shown, which it is unless enabled
+// is set to false
+func (s Screen) isEnabled() bool {
+	return s.Enabled == nil || *s.Enabled
+}
+
+// anyEnabled reports whether at least one of screens is shown
+func anyEnabled(screens []Screen) bool {
+	for _, screen := range screens {
+		if screen.isEnabled() {
+			return true
+		}
+	}
+	return false
+}
+
+// NetworkChecker interface for getting IP addresses
+type NetworkChecker interface {
+	GetIPv4Address(interfaceName string) string
+	GetFirstIPv4Address(interfaceNames []string) string
+	GetIPv6Address(interfaceName string) string
+}
+
+// RealNetworkChecker implements NetworkChecker for actual network interfaces.
+// Each interface's addresses are cached for TTL, or until its operational
+// state in sysfs changes, so the update loop doesn't query them every second.
+type RealNetworkChecker struct {
+	TTL time.Duration // defaults to 5 seconds
+
+	mu        sync.Mutex
+	cache     map[string]addrLookup
+	now       func() time.Time
+	lookup    func(name string) addrLookup
+	linkState func(name string) string
+}
+
+// addrLookup is the result of reading one interface's addresses
+type addrLookup struct {
+	addrs   []net.Addr
+	missing bool  // there is no such interface
+	err     error // the interface's addresses couldn't be read
+	state   string
+	at      time.Time
+}
+
+// lookupAddrs reads an interface's addresses from the kernel
+func lookupAddrs(name string) addrLookup {
+	iface, err := net.InterfaceByName(name)
+	if err != nil {
+		return addrLookup{missing: true}
+	}
+	addrs, err := iface.Addrs()
+	return addrLookup{addrs: addrs, err: err}
+}
+
+// readOperState returns an interface's operational state such as "up" or
+// "down", or "" where sysfs doesn't report one
+func readOperState(name string) string {
+	data, err := os.ReadFile(filepath.Join(sysClassNet, name, "operstate"))
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(string(data))
+}
+
+// interfaceAddrs returns the cached lookup for an interface, reading it
+// again once it is older than the TTL or the interface went down or up
+func (r *RealNetworkChecker) interfaceAddrs(name string) addrLookup {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	now, lookup, linkState := time.Now, lookupAddrs, readOperState
+	if r.now != nil {
+		now = r.now
+	}
+	if r.lookup != nil {
+		lookup = r.lookup
+	}
+	if r.linkState != nil {
+		linkState = r.linkState
+	}
+	ttl := r.TTL
+	if ttl == 0 {
+		ttl = defaultAddrCacheTTL
+	}
+
+	state := linkState(name)
+	if cached, ok := r.cache[name]; ok && cached.state == state && now().Sub(cached.at) < ttl {
+		return cached
+	}
+	result := lookup(name)
+	result.state, result.at = state, now()
+	if r.cache == nil {
+		r.cache = make(map[string]addrLookup)
+	}
+	r.cache[name] = result
+	return result
+}
+
+// Refresh drops every cached lookup so the next call reads the interfaces
+// again
+func (r *RealNetworkChecker) Refresh() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.cache = nil
+}
+
+// GetIPv4Address gets the IPv4 address of the specified interface
+func (r *RealNetworkChecker) GetIPv4Address(interfaceName string) string {
+	result := r.interfaceAddrs(interfaceName)
+	if result.missing {
+		return fmt.Sprintf("No %s", interfaceName)
+	}
+	if result.err != nil {
+		return "No IP"
+	}
+
+	if ip4 := firstIPv4(result.addrs); ip4 != "" {
+		return ip4
+	}
+	return "No IPv4"
+}
+
+// GetFirstIPv4Address returns the IPv4 address of the first interface in
+// the list that has one, or "No IP" when none do
+func (r *RealNetworkChecker) GetFirstIPv4Address(interfaceNames []string) string {
+	for _, name := range interfaceNames {
+		result := r.interfaceAddrs(name)
+		if result.missing || result.err != nil {
+			continue
+		}
+		if ip4 := firstIPv4(result.addrs); ip4 != "" {
+			return ip4
+		}
+	}
+	return "No IP"
+}
+
+// GetIPv6Address gets the first global unicast IPv6 address of the specified
+// interface, skipping link-local fe80:: addresses
+func (r *RealNetworkChecker) GetIPv6Address(interfaceName string) string {
+	result := r.interfaceAddrs(interfaceName)
+	if result.missing {
+		return fmt.Sprintf("No %s", interfaceName)
+	}
+	if result.err != nil {
+		return "No IP"
+	}
+
+	for _, addr := range result.addrs {
+		if ipnet, ok := addr.(*net.IPNet); ok {
+			if ipnet.IP.To4() == nil && ipnet.IP.IsGlobalUnicast() {
+				return ipnet.IP.String()
+			}
+		}
+	}
+	return "No IPv6"
+}
+
+// firstIPv4 returns the first IPv4 address in addrs, or "" if there is none
+func firstIPv4(addrs []net.Addr) string {
+	for _, addr := range addrs {
+		if ipnet, ok := addr.(*net.IPNet); ok {
+			if ip4 := ipnet.IP.To4(); ip4 != nil {
+				return ip4.String()
+			}
+		}
+	}
+	return ""
+}
+
+// LoadReader interface for getting system load averages
+type LoadReader interface {
+	LoadAverage() (*load.AvgStat, error)
+	NumCPU() int
+}
+
+// RealLoadReader implements LoadReader using gopsutil
+type RealLoadReader struct{}
+
+// LoadAverage returns the 1, 5 and 15 minute load averages
+func (r *RealLoadReader) LoadAverage() (*load.AvgStat, error) {
+	return load.Avg()
+}
+
+// NumCPU returns the number of logical CPUs used to normalize the load
+func (r *RealLoadReader) NumCPU() int {
+	return runtime.NumCPU()
+}
+
+// NetCounterReader interface for getting per-interface byte counters
+type NetCounterReader interface {
+	IOCounters() ([]psnet.IOCountersStat, error)
+}
+
+// RealNetCounterReader implements NetCounterReader using gopsutil
+type RealNetCounterReader struct{}
+
+// IOCounters returns the byte counters for every network interface
+func (r *RealNetCounterReader) IOCounters() ([]psnet.IOCountersStat, error) {
+	return psnet.IOCounters(true)
+}
+
+// netSample holds the byte counters seen on a previous render
+type netSample struct {
+	bytesRecv uint64
+	bytesSent uint64
+	at        time.Time
+}
+
+// DiskCounterReader interface for getting per-device I/O counters
+type DiskCounterReader interface {
+	IOCounters(names ...string) (map[string]disk.IOCountersStat, error)
+}
+
+// RealDiskCounterReader implements DiskCounterReader using gopsutil
+type RealDiskCounterReader struct{}
+
+// IOCounters returns the I/O counters for the named block devices, or every
+// device when none are named
+func (r *RealDiskCounterReader) IOCounters(names ...string) (map[string]disk.IOCountersStat, error) {
+	return disk.IOCounters(names...)
+}
+
+// diskSample holds the byte counters seen on a previous render
+type diskSample struct {
+	readBytes  uint64
+	writeBytes uint64
+	at         time.Time
+}
+
+// UptimeReader interface for getting system uptime
+type UptimeReader interface {
+	Uptime() (uint64, error)
+}
+
+// RealUptimeReader implements UptimeReader using gopsutil
+type RealUptimeReader struct{}
+
+// Uptime returns the system uptime in seconds
+func (r *RealUptimeReader) Uptime() (uint64, error) {
+	return pshost.Uptime()
+}
+
+// SwapReader interface for getting swap usage
+type SwapReader interface {
+	SwapMemory() (*mem.SwapMemoryStat, error)
+}
+
+// RealSwapReader implements SwapReader using gopsutil
+type RealSwapReader struct{}
+
+// SwapMemory returns the current swap usage
+func (r *RealSwapReader) SwapMemory() (*mem.SwapMemoryStat, error) {
+	return mem.SwapMemory()
+}
+
+// HostInfoReader interface for getting the hostname and OS details
+type HostInfoReader interface {
+	HostInfo() (hostInfo, error)
+}
+
+// hostInfo holds the pieces the hostname component can show
+type hostInfo struct {
+	hostname string
+	os       string // e.g. "linux"
+	kernel   string // e.g. "6.1.21-v8+"
+	platform string // distribution and version, e.g. "debian 12.1"
+}
+
+// RealHostInfoReader implements HostInfoReader using os and gopsutil
+type RealHostInfoReader struct{}
+
+// HostInfo returns the hostname and OS details
+func (r *RealHostInfoReader) HostInfo() (hostInfo, error) {
+	name, err := os.Hostname()
+	if err != nil {
+		return hostInfo{}, fmt.Errorf("failed to read hostname: %v", err)
+	}
+	info, err := pshost.Info()
+	if err != nil {
+		return hostInfo{}, fmt.Errorf("failed to read host info: %v", err)
+	}
+	return hostInfo{
+		hostname: name,
+		os:       info.OS,
+		kernel:   info.KernelVersion,
+		platform: strings.TrimSpace(info.Platform + " " + info.PlatformVersion),
+	}, nil
+}
+
+// hostFields lists the pieces a hostname component can show
+var hostFields = map[string]bool{"hostname": true, "os": true, "kernel": true, "platform": true}
+
+// formatHostInfo joins the selected pieces with spaces, e.g. "rpi4 Linux 6.1".
+// The OS is capitalized and the kernel shortened to major.minor.
+func formatHostInfo(info hostInfo, fields []string) string {
+	if len(fields) == 0 {
+		fields = []string{"hostname"}
+	}
+	parts := make([]string, 0, len(fields))
+	for _, field := range fields {
+		switch field {
+		case "hostname":
+			parts = append(parts, info.hostname)
+		case "os":
+			if info.os != "" {
+				parts = append(parts, strings.ToUpper(info.os[:1])+info.os[1:])
+			}
+		case "kernel":
+			version := strings.SplitN(info.kernel, ".", 3)
+			if len(version) < 2 {
+				parts = append(parts, info.kernel)
+				break
+			}
+			minor := version[1]
+			if i := strings.IndexFunc(minor, func(r rune) bool { return r < '0' || r > '9' }); i >= 0 {
+				minor = minor[:i]
+			}
+			parts = append(parts, version[0]+"."+minor)
+		case "platform":
+			parts = append(parts, info.platform)
+		}
+	}
+	return strings.Join(parts, " ")
+}
+
+// ProcessLister interface for getting running processes
+type ProcessLister interface {
+	Pids() ([]int32, error)
+	CPUTimes() (map[int32]processCPU, error)
+}
+
+// processCPU holds a process name and its cumulative CPU time in seconds
+type processCPU struct {
+	name    string
+	seconds float64
+}
+
+// RealProcessLister implements ProcessLister using gopsutil
+type RealProcessLister struct{}
+
+// Pids returns the IDs of all running processes
+func (r *RealProcessLister) Pids() ([]int32, error) {
+	return process.Pids()
+}
+
+// CPUTimes returns the cumulative CPU time of every running process keyed
+// by PID. Processes that exit while being read are skipped.
+func (r *RealProcessLister) CPUTimes() (map[int32]processCPU, error) {
+	procs, err := process.Processes()
+	if err != nil {
+		return nil, err
+	}
+	result := make(map[int32]processCPU, len(procs))
+	for _, p := range procs {
+		name, err := p.Name()
+		if err != nil {
+			continue
+		}
+		times, err := p.Times()
+		if err != nil {
+			continue
+		}
+		result[p.Pid] = processCPU{name: name, seconds: times.User + times.System}
+	}
+	return result, nil
+}
+
+// BatteryReader interface for getting the battery charge state
+type BatteryReader interface {
+	// Battery reports the charge state, with present false when the
+	// system has no battery
+	Battery() (state batteryState, present bool, err error)
+}
+
+// batteryState holds a battery's charge percentage and whether it is charging
+type batteryState struct {
+	percent  float64
+	charging bool
+}
+
+// RealBatteryReader implements BatteryReader using sysfs
+type RealBatteryReader struct{}
+
+// Battery returns the state of the first battery under /sys/class/power_supply
+func (r *RealBatteryReader) Battery() (batteryState, bool, error) {
+	return readBattery(powerSupplyDir)
+}
+
+// readBattery reads the first power supply of type Battery below dir
+func readBattery(dir string) (batteryState, bool, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return batteryState{}, false, nil
+		}
+		return batteryState{}, false, fmt.Errorf("failed to list power supplies: %v", err)
+	}
+	for _, entry := range entries {
+		supply := filepath.Join(dir, entry.Name())
+		kind, err := os.ReadFile(filepath.Join(supply, "type"))
+		if err != nil || strings.TrimSpace(string(kind)) != "Battery" {
+			continue
+		}
+		capacity, err := os.ReadFile(filepath.Join(supply, "capacity"))
+		if err != nil {
+			return batteryState{}, false, fmt.Errorf("failed to read battery capacity: %v", err)
+		}
+		percent := float64(0)
+		if _, err := fmt.Sscanf(strings.TrimSpace(string(capacity)), "%f", &percent); err != nil {
+			return batteryState{}, false, fmt.Errorf("failed to parse battery capacity: %v", err)
+		}
+		status, _ := os.ReadFile(filepath.Join(supply, "status"))
+		return batteryState{
+			percent:  percent,
+			charging: strings.TrimSpace(string(status)) == "Charging",
+		}, true, nil
+	}
+	return batteryState{}, false, nil
+}
+
+// FanReader interface for getting fan speeds
+type FanReader interface {
+	// FanRPM reads a hwmon fan input, with present false when the file
+	// doesn't exist. An empty path means the first fan1_input found.
+	FanRPM(path string) (rpm int, present bool, err error)
+}
+
+// RealFanReader implements FanReader using sysfs
+type RealFanReader struct{}
+
+// FanRPM reads the speed in RPM from a hwmon fan input file
+func (r *RealFanReader) FanRPM(path string) (int, bool, error) {
+	if path == "" {
+		matches, err := filepath.Glob(fanInputGlob)
+		if err != nil || len(matches) == 0 {
+			return 0, false, nil
+		}
+		path = matches[0]
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return 0, false, nil
+		}
+		return 0, false, fmt.Errorf("failed to read fan speed: %v", err)
+	}
+	rpm, err := parseFanRPM(string(data))
+	if err != nil {
+		return 0, false, err
+	}
+	return rpm, true, nil
+}
+
+// parseFanRPM parses the contents of a hwmon fanN_input file, a whole
+// number of RPM such as "2400\n"
+func parseFanRPM(data string) (int, error) {
+	value := strings.TrimSpace(data)
+	rpm, err := strconv.Atoi(value)
+	if err != nil || rpm < 0 {
+		return 0, fmt.Errorf("unexpected fan speed %q", value)
+	}
+	return rpm, nil
+}
+
+// CommandRunner interface for running external programs
+type CommandRunner interface {
+	// Output runs a program until it exits or ctx is done
+	Output(ctx context.Context, name string, args ...string) ([]byte, error)
+}
+
+// RealCommandRunner implements CommandRunner using os/exec
+type RealCommandRunner struct{}
+
+// Output runs a program and returns its standard output
+func (r *RealCommandRunner) Output(ctx context.Context, name string, args ...string) ([]byte, error) {
+	cmd := exec.CommandContext(ctx, name, args...)
+	// Don't wait forever for children of a killed shell that still hold stdout
+	cmd.WaitDelay = time.Second
+	return cmd.Output()
+}
+
+// execOutput returns an exec component's latest output, running its command
+// in the background once per refresh interval so a slow command never holds
+// up the display. ok is false until a run has succeeded; err is the latest
+// run's error.
+func (dm *DisplayManager) execOutput(comp Component) (output string, ok bool, updated time.Time, err error) {
+	key := dm.scrollKey(comp) + ":" + comp.Command
+	if dm.execs == nil {
+		dm.execs = make(map[string]*fetchCache[string])
+	}
+	cache, found := dm.execs[key]
+	if !found {
+		cache = &fetchCache[string]{}
+		dm.execs[key] = cache
+	}
+	runner := dm.commandRunner
+	output, ok = cache.get("exec "+comp.Command, dm.timeNow(), refreshInterval(comp), refreshInterval(comp), dm.goAsync, func() (string, error) {
+		return runExecCommand(runner, comp)
+	})
+	updated, err = cache.failure()
+	return output, ok, updated, err
+}
+
+// runExecCommand runs an exec component's shell command, killing it after its
+// timeout, and returns the first line of its output with spaces trimmed
+func runExecCommand(runner CommandRunner, comp Component) (string, error) {
+	timeout := time.Duration(comp.Timeout) * time.Second
+	if timeout == 0 {
+		timeout = defaultExecTimeout
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	output, err := runner.Output(ctx, "sh", "-c", comp.Command)
+	if errors.Is(err, context.DeadlineExceeded) || ctx.Err() == context.DeadlineExceeded {
+		return "", fmt.Errorf("command timed out after %v", timeout)
+	}
+	if err != nil {
+		return "", err
+	}
+	line, _, _ := strings.Cut(strings.TrimSpace(string(output)), "\n")
+	return strings.TrimSpace(line), nil
+}
+
+// gpuTemperature returns the latest vcgencmd reading in Celsius, running
+// vcgencmd in the background once per refresh interval so a hung firmware
+// call never holds up the display. ok is false until a run has succeeded; err
+// is the latest run's error.
+func (dm *DisplayManager) gpuTemperature(comp Component) (celsius float64, ok bool, updated time.Time, err error) {
+	if dm.gpuTemp == nil {
+		dm.gpuTemp = &fetchCache[float64]{}
+	}
+	runner := dm.commandRunner
+	celsius, ok = dm.gpuTemp.get("gputemp", dm.timeNow(), refreshInterval(comp), refreshInterval(comp), dm.goAsync, func() (float64, error) {
+		ctx, cancel := context.WithTimeout(context.Background(), gpuTempTimeout)
+		defer cancel()
+		output, err := runner.Output(ctx, "vcgencmd", "measure_temp")
+		if err != nil {
+			return 0, fmt.Errorf("failed to run vcgencmd: %v", err)
+		}
+		return parseVcgencmdTemp(string(output))
+	})
+	updated, err = dm.gpuTemp.failure()
+	return celsius, ok, updated, err
+}
+
+// parseVcgencmdTemp parses `vcgencmd measure_temp` output such as
+// "temp=48.3'C" into degrees Celsius
+func parseVcgencmdTemp(output string) (float64, error) {
+	value, ok := strings.CutPrefix(strings.TrimSpace(output), "temp=")
+	if !ok {
+		return 0, fmt.Errorf("unexpected vcgencmd output %q", output)
+	}
+	value = strings.TrimSuffix(value, "'C")
+	temp, err := strconv.ParseFloat(value, 64)
+	if err != nil {
+		return 0, fmt.Errorf("unexpected vcgencmd output %q", output)
+	}
+	return temp, nil
+}
+
+// TemperatureReader interface for getting temperatures in Celsius
+type TemperatureReader interface {
+	FileTemperature(path string) (float64, error)
+	SensorTemperature(key string) (float64, error)
+}
+
+// RealTemperatureReader implements TemperatureReader using sysfs and gopsutil
+type RealTemperatureReader struct{}
+
+// FileTemperature reads a sysfs-style file holding millidegrees Celsius
+func (r *RealTemperatureReader) FileTemperature(path string) (float64, error) {
+	temp, err := os.ReadFile(path)
+	if err != nil {
+		return 0, fmt.Errorf("failed to read temperature: %v", err)
+	}
+	tempValue := strings.TrimSpace(string(temp))
+	tempCelsius := float64(0)
+	if _, err := fmt.Sscanf(tempValue, "%f", &tempCelsius); err != nil {
+		return 0, fmt.Errorf("failed to parse temperature: %v", err)
+	}
+	return tempCelsius / 1000.0, nil // Convert to Celsius
+}
+
+// SensorTemperature reads the gopsutil sensor with the given key
+func (r *RealTemperatureReader) SensorTemperature(key string) (float64, error) {
+	// Some sensors fail to read while others succeed, so only give up
+	// when nothing was returned
+	sensors, err := pshost.SensorsTemperatures()
+	if err != nil && len(sensors) == 0 {
+		return 0, fmt.Errorf("failed to read sensors: %v", err)
+	}
+	return findSensor(sensors, key)
+}
+
+// findSensor returns the temperature of the sensor with the given key
+func findSensor(sensors []pshost.TemperatureStat, key string) (float64, error) {
+	for _, sensor := range sensors {
+		if sensor.SensorKey == key {
+			return sensor.Temperature, nil
+		}
+	}
+	return 0, fmt.Errorf("no temperature sensor %q", key)
+}
+
+// readTemperature reads the sensor with the given key, or the sysfs file at
+// source when no key is set, falling back to the default thermal zone
+func readTemperature(r TemperatureReader, source, sensorKey string) (float64, error) {
+	if sensorKey != "" {
+		return r.SensorTemperature(sensorKey)
+	}
+	if source == "" {
+		source = tempFile
+	}
+	return r.FileTemperature(source)
+}
+
+// MetricsProvider interface for the system metrics shown by the cpu,
+// cpucores, memory, disk and temperature components
+type MetricsProvider = render.MetricsProvider
+
+// RealMetricsProvider implements MetricsProvider using gopsutil and sysfs
+type RealMetricsProvider struct {
+	temps TemperatureReader
+}
+
+// CPUPercent returns the total CPU usage since the previous call
+func (p *RealMetricsProvider) CPUPercent() (float64, error) {
+	percent, err := cpu.Percent(0, false)
+	if err != nil {
+		return 0, err
+	}
+	if len(percent) == 0 {
+		return 0, fmt.Errorf("no CPU usage reported")
+	}
+	return percent[0], nil
+}
+
+// PerCPUPercent returns the usage of each core since the previous call
+func (p *RealMetricsProvider) PerCPUPercent() ([]float64, error) {
+	return cpu.Percent(0, true)
+}
+
+// VirtualMemory returns the current memory usage
+func (p *RealMetricsProvider) VirtualMemory() (*mem.VirtualMemoryStat, error) {
+	return mem.VirtualMemory()
+}
+
+// DiskUsage returns the usage of the filesystem mounted at path
+func (p *RealMetricsProvider) DiskUsage(path string) (*disk.UsageStat, error) {
+	return disk.Usage(path)
+}
+
+// Temperature returns a reading in Celsius, see readTemperature
+func (p *RealMetricsProvider) Temperature(source, sensorKey string) (float64, error) {
+	return readTemperature(p.temps, source, sensorKey)
+}
+
+// HottestTemperature returns the highest reading among zones and the file it
+// came from, see hottestZone
+func (p *RealMetricsProvider) HottestTemperature(zones []string) (float64, string, error) {
+	return hottestZone(p.temps, zones)
+}
+
+// screenTimer is the subset of *time.Timer used to schedule screen rotation,
+// so tests can substitute a manually fired timer
+type screenTimer interface {
+	Chan() <-chan time.Time
+	Reset(d time.Duration)
+	Stop() bool
+}
+
+// realScreenTimer implements screenTimer with a *time.Timer
+type realScreenTimer struct {
+	*time.Timer
+}
+
+// Chan returns the channel the timer fires on
+func (t realScreenTimer) Chan() <-chan time.Time {
+	return t.C
+}
+
+// Reset reschedules the timer, discarding a pending fire that wasn't received
+func (t realScreenTimer) Reset(d time.Duration) {
+	if !t.Timer.Stop() {
+		select {
+		case <-t.C:
+		default:
+		}
+	}
+	t.Timer.Reset(d)
+}
+
+// updateTicker is the subset of *time.Ticker used to schedule updates, so
+// tests can substitute a manually fired ticker
+type updateTicker interface {
+	Chan() <-chan time.Time
+	Reset(d time.Duration)
+	Stop()
+}
+
+// realUpdateTicker implements updateTicker with a *time.Ticker
+type realUpdateTicker struct {
+	*time.Ticker
+}
+
+// Chan returns the channel the ticker fires on
+func (t realUpdateTicker) Chan() <-chan time.Time {
+	return t.C
+}
+
+// DisplayDevice interface defines the methods we need from a display
+type DisplayDevice interface {
+	SetContrast(contrast uint8) error
+	Invert(inverted bool) error
+	Draw(r image.Rectangle, src image.Image, sp image.Point) error
+	Halt() error
+}
+
+// DisplayManager handles screen rotation and rendering
+type DisplayManager struct {
+	config         Config
+	configPath     string
+	configModTime  time.Time
+	displayIndex   int // position in the config's displays
+	displayCount   int // number of configured displays, 0 without a displays list
+	currentScreen  int
+	networkChecker NetworkChecker
+	loadReader     LoadReader
+	netCounters    NetCounterReader
+	netSamples     map[string]netSample
+	diskCounters   DiskCounterReader
+	diskSamples    map[string]diskSample
+	uptimeReader   UptimeReader
+	swapReader     SwapReader
+	metricsSource  MetricsProvider
+	processLister  ProcessLister
+	batteryReader  BatteryReader
+	fanReader      FanReader
+	wifiReader     WifiReader
+	dockerClient   DockerClient
+	notifier       Notifier // systemd readiness and watchdog, nil outside systemd
+	pinger         Pinger
+	pings          map[string]*fetchCache[pingResult] // latest result by address
+	execs          map[string]*fetchCache[string]
+	dockers        map[string]*fetchCache[int] // running containers by socket
+	gpuTemp        *fetchCache[float64]        // vcgencmd readings in Celsius
+	asyncFunc      func(f func())              // runs background checks, a goroutine when nil
+	commandRunner  CommandRunner
+	hostReader     HostInfoReader
+	hostInfo       *hostInfo // read once, since it rarely changes
+	httpClient     HTTPGetter
+	weather        fetchCache[weatherReport]
+	calendarParser CalendarParser
+	calendar       fetchCache[[]calendarEvent]
+	procSamples    map[int32]processCPU
+	procSampleAt   time.Time
+	blinkOff       bool // alerting components are hidden on every other update
+	layers         map[string]componentLayer
+	failing        map[string]bool   // components whose last render failed, so each failure is logged once
+	recording      *render.Recording // text of the layer being sampled by renderCached, nil otherwise
+	components     *render.Renderer  // draws components, kept for their marquee, graph and trend state
+	dev            DisplayDevice
+	img            *image.RGBA
+	prevFrame      []byte
+	prevShift      image.Point // anti_burnin offset of the last frame sent
+	face           font.Face
+	isInverted     bool
+	timeNow        func() time.Time
+	newTimer       func(d time.Duration) screenTimer
+	newTicker      func(d time.Duration) updateTicker
+	contrast       uint8
+	metrics        *displayMetrics
+	commands       chan displayCommand
+	order          screenOrder
+	rng            *rand.Rand
+	paused         bool
+	nextButton     Button
+	prevButton     Button
+	sleepFunc      func(d time.Duration)
+	statusMu       sync.Mutex
+	status         displayStatus
+	frame          *image.RGBA // copy of the last rendered frame, guarded by statusMu
+	shownScreen    int         // screen last reported to OnScreenChange
+	screenShown    bool        // whether any screen has been reported yet
+
+	// OnScreenChange, when set, is called from the render loop whenever a
+	// different screen is shown, starting with the first
+	OnScreenChange func(index int, name string)
+	// OnRenderError, when set, is called from the render loop for each
+	// component that fails to render, after it is drawn as the placeholder
+	OnRenderError func(comp Component, err error)
+}
+
+// formatRate formats a byte-per-second rate using binary units, e.g. "1.2MB/s"
+func formatRate(bytesPerSec float64) string {
+	return render.HumanizeBytes(bytesPerSec) + "/s"
+}
+
+// formatUptime renders an uptime duration in one of three styles:
+// the default "up 3d 4h 12m", "compact" ("3d4h") and "verbose"
+// ("up 3 days, 4 hours, 12 minutes"). Durations under a minute show seconds.
+func formatUptime(d time.Duration, style string) string {
+	type unit struct {
+		value       int
+		short, long string
+	}
+	units := []unit{
+		{int(d / (24 * time.Hour)), "d", "day"},
+		{int(d % (24 * time.Hour) / time.Hour), "h", "hour"},
+		{int(d % time.Hour / time.Minute), "m", "minute"},
+	}
+	if d < time.Minute {
+		units = []unit{{int(d / time.Second), "s", "second"}}
+	}
+
+	var parts []string
+	for _, u := range units {
+		if u.value == 0 && len(units) > 1 {
+			continue
+		}
+		switch style {
+		case "verbose":
+			name := u.long
+			if u.value != 1 {
+				name += "s"
+			}
+			parts = append(parts, fmt.Sprintf("%d %s", u.value, name))
+		default:
+			parts = append(parts, fmt.Sprintf("%d%s", u.value, u.short))
+		}
+	}
+
+	switch style {
+	case "compact":
+		// Only the two most significant units fit a compact layout
+		if len(parts) > 2 {
+			parts = parts[:2]
+		}
+		return strings.Join(parts, "")
+	case "verbose":
+		return "up " + strings.Join(parts, ", ")
+	default:
+		return "up " + strings.Join(parts, " ")
+	}
+}
+
+// componentTypes lists the component types renderComponent knows how to draw
+var componentTypes = map[string]bool{
+	"time":        true,
+	"ip":          true,
+	"cpu":         true,
+	"memory":      true,
+	"disk":        true,
+	"diskall":     true,
+	"temperature": true,
+	"loadavg":     true,
+	"netspeed":    true,
+	"diskio":      true,
+	"uptime":      true,
+	"cpugraph":    true,
+	"memgraph":    true,
+	"cpucores":    true,
+	"swap":        true,
+	"processes":   true,
+	"battery":     true,
+	"fan":         true,
+	"gputemp":     true,
+	"weather":     true,
+	"calendar":    true,
+	"date":        true,
+	"hostname":    true,
+	"exec":        true,
+	"wifi":        true,
+	"docker":      true,
+	"ping":        true,
+	"text":        true,
+	"line":        true,
+}
+
+// alertTypes lists the percentage components that support alert thresholds
+var alertTypes = map[string]bool{
+	"cpu":      true,
+	"memory":   true,
+	"swap":     true,
+	"disk":     true,
+	"diskall":  true,
+	"battery":  true,
+	"cpugraph": true,
+	"memgraph": true,
+}
+
+// validateConfig checks a parsed config for values that would crash or
+// misrender, returning a single error that lists every problem found
+func validateConfig(config Config) error {
+	var problems []string
+
+	if config.ScreenDuration <= 0 {
+		problems = append(problems, fmt.Sprintf("screen_duration must be greater than 0, got %d", config.ScreenDuration))
+	}
+	if len(config.Displays) > 0 {
+		if len(config.Screens) > 0 {
+			problems = append(problems, "screens and displays cannot both be set; give each display its own screens")
+		}
+		problems = append(problems, validateDisplays(config)...)
+	} else if len(config.Screens) == 0 {
+		problems = append(problems, "at least one screen must be configured")
+	} else if !anyEnabled(config.Screens) {
+		problems = append(problems, "at least one screen must be enabled")
+	}
+	displayWidth, displayHeight := config.displaySize()
+	supported := false
+	for _, size := range supportedDisplaySizes {
+		if size.X == displayWidth && size.Y == displayHeight {
+			supported = true
+		}
+	}
+	if !supported {
+		problems = append(problems, fmt.Sprintf("display size %dx%d is not a supported SSD1306 size", displayWidth, displayHeight))
+	}
+	switch config.Connection {
+	case "", "i2c", "spi":
+	default:
+		problems = append(problems, fmt.Sprintf("connection must be i2c or spi, got %q", config.Connection))
+	}
+	switch config.TemperatureUnit {
+	case "", "C", "F":
+	default:
+		problems = append(problems, fmt.Sprintf("temperature_unit must be C or F, got %q", config.TemperatureUnit))
+	}
+	if config.TransitionMinutes < 0 {
+		problems = append(problems, fmt.Sprintf("transition_minutes must not be negative, got %d", config.TransitionMinutes))
+	}
+	if _, err := time.LoadLocation(config.Timezone); err != nil {
+		problems = append(problems, fmt.Sprintf("timezone: %v", err))
+	}
+	if config.AntiBurninMinutes < 0 {
+		problems = append(problems, fmt.Sprintf("anti_burnin_minutes must not be negative, got %d", config.AntiBurninMinutes))
+	}
+	if config.UpdateInterval < 0 {
+		problems = append(problems, fmt.Sprintf("update_interval must not be negative, got %d", config.UpdateInterval))
+	}
+	if config.SplashDuration < 0 {
+		problems = append(problems, fmt.Sprintf("splash_duration must not be negative, got %d", config.SplashDuration))
+	}
+	for _, level := range []struct {
+		name  string
+		value *int
+	}{{"day_contrast", config.DayContrast}, {"night_contrast", config.NightContrast}} {
+		if level.value != nil && (*level.value < 0 || *level.value > 255) {
+			problems = append(problems, fmt.Sprintf("%s must be between 0 and 255, got %d", level.name, *level.value))
+		}
+	}
+	for _, port := range []struct {
+		name  string
+		value int
+	}{{"http_port", config.HTTPPort}, {"metrics_port", config.MetricsPort}} {
+		if port.value < 0 || port.value > 65535 {
+			problems = append(problems, fmt.Sprintf("%s must be between 0 and 65535, got %d", port.name, port.value))
+		}
+	}
+	if _, err := parseLogLevel(config.LogLevel); err != nil {
+		problems = append(problems, fmt.Sprintf("log_level must be debug, info, warn or error, got %q", config.LogLevel))
+	}
+	switch config.Transition {
+	case "", "none", "slide_left", "fade":
+	default:
+		problems = append(problems, fmt.Sprintf("transition must be none, slide_left or fade, got %q", config.Transition))
+	}
+	if config.Calendar.Interval < 0 || config.Calendar.Days < 0 {
+		problems = append(problems, fmt.Sprintf("calendar interval and days must not be negative, got %d and %d", config.Calendar.Interval, config.Calendar.Days))
+	}
+	if config.Weather.Interval < 0 {
+		problems = append(problems, fmt.Sprintf("weather interval must not be negative, got %d", config.Weather.Interval))
+	}
+	if config.MQTT.Interval < 0 {
+		problems = append(problems, fmt.Sprintf("mqtt interval must not be negative, got %d", config.MQTT.Interval))
+	}
+	if config.InitRetries < 0 {
+		problems = append(problems, fmt.Sprintf("init_retries must not be negative, got %d", config.InitRetries))
+	}
+	if config.InitRetryDelay < 0 {
+		problems = append(problems, fmt.Sprintf("init_retry_delay must not be negative, got %d", config.InitRetryDelay))
+	}
+	if config.FontSize < 0 {
+		problems = append(problems, fmt.Sprintf("font_size must not be negative, got %g", config.FontSize))
+	}
+	if config.I2CAddress != 0 && !validI2CAddress(config.I2CAddress) {
+		problems = append(problems, fmt.Sprintf("i2c_address must be between 0x08 and 0x77, got %#x", config.I2CAddress))
+	}
+	switch config.DisplayRotation {
+	case 0, 90, 180, 270:
+		if config.Rotate180 && config.DisplayRotation != 0 && config.DisplayRotation != 180 {
+			problems = append(problems, fmt.Sprintf("rotate_180 conflicts with display_rotation %d", config.DisplayRotation))
+		}
+	default:
+		problems = append(problems, fmt.Sprintf("display_rotation must be 0, 90, 180 or 270, got %d", config.DisplayRotation))
+	}
+	problems = append(problems, validateScreens(config, config.Screens, "")...)
+	problems = append(problems, validateRotation(config)...)
+	if len(config.Displays) == 0 {
+		problems = append(problems, validateOrder(config.Order, len(config.Screens), "")...)
+	}
+
+	if len(problems) > 0 {
+		return fmt.Errorf("invalid config:\n  - %s", strings.Join(problems, "\n  - "))
+	}
+	return nil
+}
+
+// validateScreens checks each screen and its components against the rest of
+// the config, prefixing every problem with where it was found
+func validateScreens(config Config, screens []Screen, prefix string) []string {
+	var problems []string
+	displayWidth, displayHeight := config.logicalSize()
+	for i, screen := range screens {
+		name := fmt.Sprintf("%sscreen %d (%s)", prefix, i, screen.Name)
+		switch screen.Background {
+		case "", "black", "white":
+		default:
+			problems = append(problems, fmt.Sprintf("%s: background must be black or white, got %q", name, screen.Background))
+		}
+		if screen.Duration < 0 {
+			problems = append(problems, fmt.Sprintf("%s: duration must not be negative, got %d", name, screen.Duration))
+		}
+		if len(screen.Components) == 0 {
+			problems = append(problems, fmt.Sprintf("%s: must have at least one component", name))
+		}
+		for j, comp := range screen.Components {
+			where := fmt.Sprintf("%s component %d (%s)", name, j, comp.Type)
+			if !componentTypes[comp.Type] {
+				problems = append(problems, fmt.Sprintf("%s: unknown type %q", where, comp.Type))
+			}
+			if comp.Type == "text" && comp.Text == "" {
+				problems = append(problems, fmt.Sprintf("%s: text must not be empty", where))
+			}
+			for _, field := range comp.Fields {
+				if !hostFields[field] {
+					problems = append(problems, fmt.Sprintf("%s: unknown field %q, must be hostname, os, kernel or platform", where, field))
+				}
+			}
+			if comp.MaxRPM < 0 {
+				problems = append(problems, fmt.Sprintf("%s: max_rpm must not be negative, got %d", where, comp.MaxRPM))
+			}
+			if comp.Type == "fan" && comp.ShowBar && comp.MaxRPM == 0 {
+				problems = append(problems, fmt.Sprintf("%s: show_bar needs max_rpm to scale the bar", where))
+			}
+			if comp.Type == "exec" && comp.Command == "" {
+				problems = append(problems, fmt.Sprintf("%s: command must be set", where))
+			}
+			if comp.ShowAbsolute && comp.Type != "memory" && comp.Type != "disk" {
+				problems = append(problems, fmt.Sprintf("%s: show_absolute only applies to memory and disk", where))
+			}
+			if comp.Type == "diskall" && len(comp.Mountpoints) == 0 {
+				problems = append(problems, fmt.Sprintf("%s: mountpoints must be set", where))
+			}
+			if comp.Type == "ping" && comp.Host == "" {
+				problems = append(problems, fmt.Sprintf("%s: host must be set", where))
+			}
+			if comp.Interval < 0 {
+				problems = append(problems, fmt.Sprintf("%s: interval must not be negative, got %d", where, comp.Interval))
+			}
+			if comp.Timeout < 0 {
+				problems = append(problems, fmt.Sprintf("%s: timeout must not be negative, got %d", where, comp.Timeout))
+			}
+			if comp.Type == "diskio" && comp.Device == "" {
+				problems = append(problems, fmt.Sprintf("%s: device must be set", where))
+			}
+			if comp.Type == "weather" {
+				problems = append(problems, validateWeather(config.Weather, where)...)
+			}
+			if comp.Type == "calendar" && config.Calendar.URL == "" {
+				problems = append(problems, fmt.Sprintf("%s: calendar url must be set", where))
+			}
+			if comp.Type == "line" {
+				problems = append(problems, validateLine(comp, where, displayWidth, displayHeight)...)
+			}
+			if comp.X < 0 || comp.X > displayWidth {
+				problems = append(problems, fmt.Sprintf("%s: x %d is outside 0..%d", where, comp.X, displayWidth))
+			}
+			if comp.Y < 0 || comp.Y > displayHeight {
+				problems = append(problems, fmt.Sprintf("%s: y %d is outside 0..%d", where, comp.Y, displayHeight))
+			}
+			switch comp.Family {
+			case "", "ipv4", "ipv6":
+			default:
+				problems = append(problems, fmt.Sprintf("%s: family must be ipv4 or ipv6, got %q", where, comp.Family))
+			}
+			if _, ok := icons[comp.Icon]; comp.Icon != "" && !ok {
+				problems = append(problems, fmt.Sprintf("%s: unknown icon %q", where, comp.Icon))
+			}
+			if comp.SignalBars && comp.Icon != "" {
+				problems = append(problems, fmt.Sprintf("%s: signal_bars and icon both draw before the text, set only one", where))
+			}
+			switch comp.Orientation {
+			case "", "horizontal", "vertical":
+			default:
+				problems = append(problems, fmt.Sprintf("%s: orientation must be horizontal or vertical, got %q", where, comp.Orientation))
+			}
+			switch comp.Align {
+			case "", "left", "center", "right":
+			default:
+				problems = append(problems, fmt.Sprintf("%s: align must be left, center or right, got %q", where, comp.Align))
+			}
+			if comp.BarWidth < 0 {
+				problems = append(problems, fmt.Sprintf("%s: bar_width must not be negative, got %d", where, comp.BarWidth))
+			}
+			if comp.StartHour != nil && (*comp.StartHour < 0 || *comp.StartHour > 23) {
+				problems = append(problems, fmt.Sprintf("%s: start_hour must be 0-23, got %d", where, *comp.StartHour))
+			}
+			if comp.EndHour != nil && (*comp.EndHour < 0 || *comp.EndHour > 23) {
+				problems = append(problems, fmt.Sprintf("%s: end_hour must be 0-23, got %d", where, *comp.EndHour))
+			}
+			if comp.RefreshSeconds < 0 {
+				problems = append(problems, fmt.Sprintf("%s: refresh_seconds must not be negative, got %d", where, comp.RefreshSeconds))
+			}
+			if comp.StaleAfter < 0 {
+				problems = append(problems, fmt.Sprintf("%s: stale_after must not be negative, got %d", where, comp.StaleAfter))
+			} else if comp.StaleAfter > 0 && refreshInterval(comp) == 0 {
+				problems = append(problems, fmt.Sprintf("%s: stale_after needs refresh_seconds", where))
+			}
+			if comp.DangerThreshold != nil && (*comp.DangerThreshold < 0 || *comp.DangerThreshold > 100) {
+				problems = append(problems, fmt.Sprintf("%s: danger_threshold must be between 0 and 100, got %g", where, *comp.DangerThreshold))
+			}
+			if (comp.AlertAbove != nil || comp.AlertBelow != nil) && !alertTypes[comp.Type] {
+				problems = append(problems, fmt.Sprintf("%s: alert_above and alert_below only apply to percentage components", where))
+			}
+			if len(comp.Zones) > 0 && (comp.Source != "" || comp.SensorKey != "") {
+				problems = append(problems, fmt.Sprintf("%s: zones, source and sensor_key each pick the sensor, set only one", where))
+			}
+			for _, zone := range comp.Zones {
+				if _, err := filepath.Match(zone, ""); err != nil {
+					problems = append(problems, fmt.Sprintf("%s: bad zone pattern %q: %v", where, zone, err))
+				}
+			}
+			if comp.Wrap && comp.Scroll {
+				problems = append(problems, fmt.Sprintf("%s: wrap and scroll both handle long text, set only one", where))
+			}
+			if comp.MaxWidth < 0 {
+				problems = append(problems, fmt.Sprintf("%s: max_width must not be negative, got %d", where, comp.MaxWidth))
+			}
+			if comp.Timezone != "" {
+				if _, err := time.LoadLocation(comp.Timezone); err != nil {
+					problems = append(problems, fmt.Sprintf("%s: timezone: %v", where, err))
+				}
+			}
+			if comp.BarPadding < 0 {
+				problems = append(problems, fmt.Sprintf("%s: bar_padding must not be negative, got %d", where, comp.BarPadding))
+			}
+			if comp.Ticks < 0 {
+				problems = append(problems, fmt.Sprintf("%s: ticks must not be negative, got %d", where, comp.Ticks))
+			} else if comp.Ticks > 0 && comp.Orientation == "vertical" {
+				problems = append(problems, fmt.Sprintf("%s: ticks only apply to horizontal bars", where))
+			}
+			if comp.Reverse && comp.Orientation == "vertical" {
+				problems = append(problems, fmt.Sprintf("%s: reverse only applies to horizontal bars", where))
+			}
+			if comp.Spacing < 0 {
+				problems = append(problems, fmt.Sprintf("%s: spacing must not be negative, got %d", where, comp.Spacing))
+			}
+			if comp.Height < 0 {
+				problems = append(problems, fmt.Sprintf("%s: height must not be negative, got %d", where, comp.Height))
+			}
+		}
+	}
+	return problems
+}
+
+// validateLine checks a line component's shape and that it fits on the display
+func validateLine(comp Component, where string, displayWidth, displayHeight int) []string {
+	var problems []string
+	if comp.Length <= 0 {
+		problems = append(problems, fmt.Sprintf("%s: length must be greater than 0, got %d", where, comp.Length))
+	}
+	if comp.Thickness < 0 {
+		problems = append(problems, fmt.Sprintf("%s: thickness must not be negative, got %d", where, comp.Thickness))
+	}
+	if len(problems) > 0 {
+		return problems
+	}
+
+	w, h := comp.Length, comp.LineThickness()
+	if comp.Orientation == "vertical" {
+		w, h = h, w
+	}
+	if comp.X+w > displayWidth || comp.Y+h > displayHeight {
+		problems = append(problems, fmt.Sprintf("%s: line from %d,%d runs past the %dx%d display", where, comp.X, comp.Y, displayWidth, displayHeight))
+	}
+	return problems
+}
+
+// loadConfig reads and parses the configuration from a file, standard input
+// ("-") or a URL, as JSON when its extension is .json and as YAML otherwise
+func loadConfig(configPath string) (Config, error) {
+	configFile, err := readConfigSource(configPath)
+	if err != nil {
+		return Config{}, err
+	}
+
+	var config Config
+	unmarshal := yaml.Unmarshal
+	if configIsJSON(configPath) {
+		unmarshal = json.Unmarshal
+	}
+	if err := unmarshal(configFile, &config); err != nil {
+		return Config{}, fmt.Errorf("error parsing config file: %v", err)
+	}
+	if err := expandConfigEnv(&config, os.LookupEnv); err != nil {
+		return Config{}, err
+	}
+	migrateConfig(&config)
+	if err := validateConfig(config); err != nil {
+		return Config{}, err
+	}
+	if len(config.Displays) == 0 {
+		config = config.withoutDisabled()
+	}
+	return config, nil
+}
+
+// withoutDisabled returns the config with disabled screens and components
+// removed, so screen indexes only count the screens that are shown. Order
+// entries are renumbered to match, and those for disabled screens dropped.
+func (c Config) withoutDisabled() Config {
+	index := make(map[int]int, len(c.Screens))
+	screens := make([]Screen, 0, len(c.Screens))
+	for i, screen := range c.Screens {
+		if !screen.isEnabled() {
+			continue
+		}
+		components := make([]Component, 0, len(screen.Components))
+		for _, comp := range screen.Components {
+			if comp.IsEnabled() {
+				components = append(components, comp)
+			}
+		}
+		screen.Components = components
+		index[i] = len(screens)
+		screens = append(screens, screen)
+	}
+	c.Screens = screens
+
+	if len(c.Order) > 0 {
+		var order []int
+		for _, screen := range c.Order {
+			if i, ok := index[screen]; ok {
+				order = append(order, i)
+			}
+		}
+		c.Order = order
+	}
+	return c
+}
+
+// loadFontFace creates the face used for all text from the configured font
+// file, falling back to basicfont when no font_path is set
+func loadFontFace(config Config) (font.Face, error) {
+	if config.FontPath == "" {
+		return basicfont.Face7x13, nil
+	}
+
+	data, err := os.ReadFile(config.FontPath)
+	if err != nil {
+		return nil, fmt.Errorf("error reading font file: %v", err)
+	}
+	parsed, err := opentype.Parse(data)
+	if err != nil {
+		return nil, fmt.Errorf("error parsing font file %s: %v", config.FontPath, err)
+	}
+
+	size := config.FontSize
+	if size <= 0 {
+		size = defaultFontSize
+	}
+	face, err := opentype.NewFace(parsed, &opentype.FaceOptions{
+		Size:    size,
+		DPI:     72,
+		Hinting: font.HintingFull,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("error creating font face: %v", err)
+	}
+	return face, nil
+}
+
+// open opens the manager's SSD1306 display and its buttons
+func (dm *DisplayManager) open() error {
+	dev, err := openDisplayWithRetry(dm.config, openDisplay, time.Sleep)
+	if err != nil {
+		return err
+	}
+	dm.dev = dev
+
+	// openDisplay has initialized periph, so GPIOs can be looked up now
+	if dm.config.NextButtonPin != "" {
+		if dm.nextButton, err = openButton(dm.config.NextButtonPin); err != nil {
+			return err
+		}
+	}
+	if dm.config.PrevButtonPin != "" {
+		if dm.prevButton, err = openButton(dm.config.PrevButtonPin); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// openDisplayWithRetry calls open until it succeeds or init_retries extra
+// attempts have failed, waiting init_retry_delay seconds before the first
+// retry and doubling the wait after each. On boot the I2C bus may not be ready
+// when the service starts.
+func openDisplayWithRetry(config Config, open func(Config) (DisplayDevice, error), sleep func(time.Duration)) (DisplayDevice, error) {
+	delay := time.Duration(config.InitRetryDelay) * time.Second
+	if config.InitRetryDelay == 0 {
+		delay = time.Second
+	}
+
+	for attempt := 0; ; attempt++ {
+		dev, err := open(config)
+		if err == nil {
+			return dev, nil
+		}
+		if attempt >= config.InitRetries {
+			if attempt > 0 {
+				return nil, fmt.Errorf("%v (after %d attempts)", err, attempt+1)
+			}
+			return nil, err
+		}
+		slog.Warn("failed to open display, retrying", "err", err, "attempt", attempt+1, "delay", delay)
+		sleep(delay)
+		delay *= 2
+	}
+}
+
+// openDisplay initializes periph and opens the SSD1306 over the configured
+// connection. Both paths return the same DisplayDevice.
+func openDisplay(config Config) (DisplayDevice, error) {
+	if _, err := host.Init(); err != nil {
+		return nil, fmt.Errorf("failed to initialize periph: %v", err)
+	}
+
+	w, h := config.displaySize()
+	opts := &ssd1306.Opts{
+		W: w,
+		H: h,
+	}
+
+	if config.Connection == "spi" {
+		return openSPIDisplay(config.SPIBus, config.DCPin, opts)
+	}
+	return openI2CDisplay(config.I2CBus, config.i2cAddress(), opts)
+}
+
+// openI2CBus opens an I2C bus by name; tests replace it to avoid hardware
+var openI2CBus = func(name string) (i2c.BusCloser, error) {
+	return i2creg.Open(name)
+}
+
+// openI2CDisplay opens the SSD1306 at addr on the named I2C bus (empty for the
+// first available). Only the W and H opts are needed.
+func openI2CDisplay(busName string, addr int, opts *ssd1306.Opts) (DisplayDevice, error) {
+	bus, err := openI2CBus(busName)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open I2C: %v", err)
+	}
+
+	// The driver always uses 0x3C, so other addresses are rewritten on the way out
+	dev, err := ssd1306.NewI2C(&addressBus{Bus: bus, addr: uint16(addr)}, opts)
+	if err != nil {
+		bus.Close()
+		return nil, fmt.Errorf("failed to initialize SSD1306: %v", err)
+	}
+	return dev, nil
+}
+
+// openSPIDisplay opens the SSD1306 on an SPI port. Besides the W and H opts it
+// needs the port name (empty for the first available) and the data/command
+// GPIO for 4-wire SPI; with no DC pin the panel is driven in 3-wire mode.
+func openSPIDisplay(busName, dcPinName string, opts *ssd1306.Opts) (DisplayDevice, error) {
+	port, err := spireg.Open(busName)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open SPI %q: %v", busName, err)
+	}
+
+	// A nil pin selects 3-wire mode in the driver
+	var dc gpio.PinOut
+	if dcPinName != "" {
+		pin := gpioreg.ByName(dcPinName)
+		if pin == nil {
+			port.Close()
+			return nil, fmt.Errorf("failed to find DC pin %q", dcPinName)
+		}
+		dc = pin
+	}
+
+	dev, err := ssd1306.NewSPI(port, dc, opts)
+	if err != nil {
+		port.Close()
+		return nil, fmt.Errorf("failed to initialize SSD1306 over SPI: %v", err)
+	}
+	return dev, nil
+}
+
+// newDisplayManager sets up rendering of a single display's config without
+// touching any display hardware
+func newDisplayManager(config Config, networkChecker NetworkChecker, metrics *displayMetrics) (*DisplayManager, error) {
+	face, err := loadFontFace(config)
+	if err != nil {
+		return nil, err
+	}
+
+	displayWidth, displayHeight := config.logicalSize()
+	dm := &DisplayManager{
+		config:         config,
+		networkChecker: networkChecker,
+		loadReader:     &RealLoadReader{},
+		netCounters:    &RealNetCounterReader{},
+		netSamples:     make(map[string]netSample),
+		diskCounters:   &RealDiskCounterReader{},
+		diskSamples:    make(map[string]diskSample),
+		uptimeReader:   &RealUptimeReader{},
+		swapReader:     &RealSwapReader{},
+		metricsSource:  &RealMetricsProvider{temps: &RealTemperatureReader{}},
+		processLister:  &RealProcessLister{},
+		batteryReader:  &RealBatteryReader{},
+		fanReader:      &RealFanReader{},
+		wifiReader:     &RealWifiReader{runner: &RealCommandRunner{}},
+		dockerClient:   &RealDockerClient{},
+		pinger:         &RealPinger{},
+		notifier:       systemdNotifier(),
+		commandRunner:  &RealCommandRunner{},
+		hostReader:     &RealHostInfoReader{},
+		httpClient:     &http.Client{Timeout: httpFetchTimeout},
+		calendarParser: &RealCalendarParser{},
+		img:            image.NewRGBA(image.Rect(0, 0, displayWidth, displayHeight)),
+		face:           face,
+		timeNow:        time.Now,
+		metrics:        metrics,
+		commands:       make(chan displayCommand, commandQueueSize),
+	}
+	dm.currentScreen = dm.screenOrder().first(len(config.Screens))
+	return dm, nil
+}
+
+func (dm *DisplayManager) updateBrightness() error {
+	day, night := dm.config.contrastLevels()
+	contrast := rampContrast(dm.timeNow(), dm.config.DayStartHour, dm.config.NightStartHour, dm.config.TransitionMinutes, day, night)
+	if err := dm.dev.SetContrast(contrast); err != nil {
+		return err
+	}
+	dm.contrast = contrast
+	return nil
+}
+
+// syncInvertTicker starts, resets or stops t to match invert_duration. It
+// returns the ticker to keep and its channel, both nil when inverting is off.
+func (dm *DisplayManager) syncInvertTicker(t *time.Ticker) (*time.Ticker, <-chan time.Time) {
+	if dm.config.InvertDuration <= 0 {
+		if t != nil {
+			t.Stop()
+		}
+		return nil, nil
+	}
+	d := time.Duration(dm.config.InvertDuration) * time.Second
+	if t == nil {
+		t = time.NewTicker(d)
+	} else {
+		t.Reset(d)
+	}
+	return t, t.C
+}
+
+// toggleInvert flips the hardware invert, keeping it off at night when
+// invert_daytime_only is set. The contrast is applied again afterwards so the
+// panel always ends up at the level updateBrightness chose, whatever the
+// controller does to contrast when inverting.
+func (dm *DisplayManager) toggleInvert() error {
+	inverted := !dm.isInverted
+	if dm.config.InvertDaytimeOnly && !hourInWindow(dm.timeNow().Hour(), dm.config.DayStartHour, dm.config.NightStartHour) {
+		inverted = false
+	}
+	if err := dm.dev.Invert(inverted); err != nil {
+		return fmt.Errorf("failed to toggle invert: %v", err)
+	}
+	dm.isInverted = inverted
+	if err := dm.updateBrightness(); err != nil {
+		return fmt.Errorf("failed to update brightness: %v", err)
+	}
+	return nil
+}
+
+// rampContrast returns the contrast for the given time. The day window runs
+// from dayStart to nightStart and may wrap past midnight. For transition
+// minutes after each switch the contrast moves linearly between the night and
+// day levels instead of jumping.
+func rampContrast(now time.Time, dayStart, nightStart, transitionMinutes, dayContrast, nightContrast int) uint8 {
+	if dayStart == nightStart {
+		return uint8(nightContrast)
+	}
+	minute := now.Hour()*60 + now.Minute()
+	from, to := dayContrast, nightContrast
+	since := (minute - nightStart*60 + 24*60) % (24 * 60)
+	if hourInWindow(now.Hour(), dayStart, nightStart) {
+		from, to = nightContrast, dayContrast
+		since = (minute - dayStart*60 + 24*60) % (24 * 60)
+	}
+	if since >= transitionMinutes {
+		return uint8(to)
+	}
+	return uint8(from + (to-from)*since/transitionMinutes)
+}
+
+// reloadConfig re-parses the config file when its modification time changes and
+// swaps it in, keeping the current config if parsing fails. It reports whether
+// a new config was applied.
+func (dm *DisplayManager) reloadConfig() (bool, error) {
+	info, err := os.Stat(dm.configPath)
+	if err != nil {
+		return false, fmt.Errorf("error reading config file: %v", err)
+	}
+	if info.ModTime().Equal(dm.configModTime) {
+		return false, nil
+	}
+	// Remember the mod time even on failure so a bad file is only reported once
+	dm.configModTime = info.ModTime()
+
+	config, err := loadConfig(dm.configPath)
+	if err != nil {
+		return false, err
+	}
+	if len(config.Displays) != dm.displayCount {
+		return false, fmt.Errorf("number of displays cannot change without a restart")
+	}
+	if dm.displayCount > 0 {
+		config = config.forDisplay(dm.displayIndex)
+	}
+
+	// The panel is opened once at startup, so its size can't change on reload
+	oldWidth, oldHeight := dm.config.displaySize()
+	if newWidth, newHeight := config.displaySize(); newWidth != oldWidth || newHeight != oldHeight {
+		return false, fmt.Errorf("display size cannot change from %dx%d to %dx%d without a restart", oldWidth, oldHeight, newWidth, newHeight)
+	}
+	oldWidth, oldHeight = dm.config.logicalSize()
+	if newWidth, newHeight := config.logicalSize(); newWidth != oldWidth || newHeight != oldHeight {
+		return false, fmt.Errorf("display_rotation cannot switch between landscape and portrait without a restart")
+	}
+	if config.Connection != dm.config.Connection || config.SPIBus != dm.config.SPIBus || config.DCPin != dm.config.DCPin || config.I2CBus != dm.config.I2CBus || config.i2cAddress() != dm.config.i2cAddress() {
+		return false, fmt.Errorf("display connection cannot change without a restart")
+	}
+
+	if config.FontPath != dm.config.FontPath || config.FontSize != dm.config.FontSize {
+		face, err := loadFontFace(config)
+		if err != nil {
+			return false, err
+		}
+		dm.face = face
+	}
+
+	if len(config.Screens) != len(dm.config.Screens) {
+		dm.currentScreen = 0
+	}
+	if config.Rotation != dm.config.Rotation || !slices.Equal(config.Order, dm.config.Order) {
+		dm.order = nil
+	}
+	if config.displayRotation() != dm.config.displayRotation() {
+		// Force a redraw even if the frame's content is unchanged
+		dm.prevFrame = nil
+	}
+	dm.config = config
+	applyLogLevel(config)
+	// Cached component layers may show settings that just changed
+	dm.layers = nil
+	if checker, ok := dm.networkChecker.(interface{ Refresh() }); ok {
+		checker.Refresh()
+	}
+	return true, nil
+}
+
+// nextScreen returns the screen the timer rotates to next
+func (dm *DisplayManager) nextScreen() int {
+	return dm.screenOrder().next(dm.currentScreen, len(dm.config.Screens))
+}
+
+// screenOrder returns the configured rotation order, creating it on first
+// use and after a reload changed it
+func (dm *DisplayManager) screenOrder() screenOrder {
+	if dm.order == nil {
+		if dm.rng == nil {
+			dm.rng = rand.New(rand.NewSource(time.Now().UnixNano()))
+		}
+		dm.order = newScreenOrder(dm.config, dm.rng)
+	}
+	return dm.order
+}
+
+// screenDuration returns how long the current screen stays up, using its own
+// duration when set and the global screen_duration otherwise
+func (dm *DisplayManager) screenDuration() time.Duration {
+	seconds := dm.config.ScreenDuration
+	if d := dm.config.Screens[dm.currentScreen].Duration; d > 0 {
+		seconds = d
+	}
+	return time.Duration(seconds) * time.Second
+}
+
+// startScreenTimer creates the timer that drives screen rotation
+func (dm *DisplayManager) startScreenTimer(d time.Duration) screenTimer {
+	if dm.newTimer != nil {
+		return dm.newTimer(d)
+	}
+	return realScreenTimer{time.NewTimer(d)}
+}
+
+// startUpdateTicker returns the ticker that redraws the current screen
+func (dm *DisplayManager) startUpdateTicker(d time.Duration) updateTicker {
+	if dm.newTicker != nil {
+		return dm.newTicker(d)
+	}
+	return realUpdateTicker{time.NewTicker(d)}
+}
+
+// Run renders screens until ctx is cancelled or SIGINT/SIGTERM is received,
+// then blanks and halts the display
+func (dm *DisplayManager) Run(ctx context.Context) error {
+	ctx, stop := signal.NotifyContext(ctx, os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	if dm.commands == nil {
+		dm.commands = make(chan displayCommand, commandQueueSize)
+	}
+	dm.ensureScreens()
+
+	// Screens can have their own durations, so the timer is rescheduled after each rotation
+	screenTimer := dm.startScreenTimer(dm.screenDuration())
+	defer screenTimer.Stop()
+
+	// Update values every update_interval
+	updateTicker := dm.startUpdateTicker(dm.config.updateInterval())
+	defer updateTicker.Stop()
+
+	// Invert toggling can be turned on or off by a reload, so the ticker may come and go
+	invertTicker, invertChan := dm.syncInvertTicker(nil)
+	defer func() {
+		if invertTicker != nil {
+			invertTicker.Stop()
+		}
+	}()
+
+	// Initialize brightness based on current time
+	if err := dm.updateBrightness(); err != nil {
+		return fmt.Errorf("failed to set initial brightness: %v", err)
+	}
+
+	// Check brightness every minute
+	brightnessTicker := time.NewTicker(1 * time.Minute)
+	defer brightnessTicker.Stop()
+
+	// Watch the config file for changes
+	var reloadChan <-chan time.Time
+	if dm.configPath != "" {
+		reloadTicker := time.NewTicker(configCheckInterval)
+		defer reloadTicker.Stop()
+		reloadChan = reloadTicker.C
+	}
+
+	if dm.config.SelfTest {
+		if err := dm.runSelfTest(); err != nil {
+			return err
+		}
+		screenTimer.Reset(dm.screenDuration())
+	}
+
+	// Show the splash, then give the first screen its full duration
+	if dm.config.SplashDuration > 0 {
+		if err := dm.showSplash(ctx); err != nil {
+			return err
+		}
+		screenTimer.Reset(dm.screenDuration())
+	}
+
+	// Render initial screen
+	if err := dm.renderCurrentScreen(); err != nil {
+		return err
+	}
+	dm.notify(notifyReady)
+
+	if dm.config.HTTPPort > 0 {
+		server := startHTTPServer(fmt.Sprintf(":%d", dm.config.HTTPPort), dm.statusHandler())
+		defer stopHTTPServer(server)
+	}
+	if dm.nextButton != nil {
+		go dm.watchButton(ctx, dm.nextButton, displayCommand{kind: cmdNextScreen})
+	}
+	if dm.prevButton != nil {
+		go dm.watchButton(ctx, dm.prevButton, displayCommand{kind: cmdPrevScreen})
+	}
+	if dm.config.MQTT.Broker != "" {
+		publisher := newMQTTPublisher(dm.config.MQTT)
+		defer publisher.Close()
+		go dm.runMQTT(ctx, publisher, dm.config.MQTT, dm.config.NetworkInterface)
+	}
+	if dm.config.MetricsPort > 0 && dm.metrics != nil {
+		server := startHTTPServer(fmt.Sprintf(":%d", dm.config.MetricsPort), dm.metrics.handler())
+		defer stopHTTPServer(server)
+	}
+
+	for {
+		select {
+		case <-ctx.Done():
+			dm.notify(notifyStopping)
+			return dm.shutdown()
+
+		case <-screenTimer.Chan():
+			// A fire can already be queued when rotation is paused
+			if dm.paused {
+				break
+			}
+			from := append([]byte(nil), dm.prevFrame...)
+			dm.currentScreen = dm.nextScreen()
+			screenTimer.Reset(dm.screenDuration())
+			if err := dm.transitionTo(from); err != nil {
+				return err
+			}
+
+		case <-updateTicker.Chan():
+			dm.advanceScrolls()
+			dm.blinkOff = !dm.blinkOff
+			if err := dm.renderCurrentScreen(); err != nil {
+				return err
+			}
+			// A loop stuck anywhere stops these, so systemd restarts the service
+			dm.notify(notifyWatchdog)
+
+		case cmd := <-dm.commands:
+			if err := dm.handleCommand(cmd, screenTimer); err != nil {
+				return err
+			}
+
+		case <-invertChan:
+			if err := dm.toggleInvert(); err != nil {
+				return err
+			}
+
+		case <-brightnessTicker.C:
+			if err := dm.updateBrightness(); err != nil {
+				return fmt.Errorf("failed to update brightness: %v", err)
+			}
+
+		case <-reloadChan:
+			reloaded, err := dm.reloadConfig()
+			if err != nil {
+				slog.Error("failed to reload config, keeping current config", "err", err)
+				break
+			}
+			if !reloaded {
+				break
+			}
+			if !dm.paused {
+				screenTimer.Reset(dm.screenDuration())
+			}
+			invertTicker, invertChan = dm.syncInvertTicker(invertTicker)
+			if invertTicker == nil && dm.isInverted {
+				if err := dm.dev.Invert(false); err != nil {
+					return fmt.Errorf("failed to toggle invert: %v", err)
+				}
+				dm.isInverted = false
+			}
+			updateTicker.Reset(dm.config.updateInterval())
+			if err := dm.updateBrightness(); err != nil {
+				return fmt.Errorf("failed to update brightness: %v", err)
+			}
+			if err := dm.renderCurrentScreen(); err != nil {
+				return err
+			}
+		}
+	}
 }
 
-// Screen represents a single virtual screen configuration
-type Screen struct {
-	Name       string      `yaml:"name"`
-	Components []Component `yaml:"components"`
+// reportScreenChange calls OnScreenChange when the screen just rendered isn't
+// the one last reported
+func (dm *DisplayManager) reportScreenChange() {
+	if dm.OnScreenChange == nil || (dm.screenShown && dm.shownScreen == dm.currentScreen) {
+		return
+	}
+	dm.shownScreen, dm.screenShown = dm.currentScreen, true
+	dm.OnScreenChange(dm.currentScreen, dm.config.Screens[dm.currentScreen].Name)
 }
 
-// Component represents a display component configuration
-type Component struct {
-	Type       string `yaml:"type"`
-	X          int    `yaml:"x"`
-	Y          int    `yaml:"y"`
-	Label      string `yaml:"label,omitempty"`
-	ShowBar    bool   `yaml:"show_bar,omitempty"`
-	BarWidth   int    `yaml:"bar_width,omitempty"`
-	TimeFormat string `yaml:"time_format,omitempty"`
+// shutdown blanks the display so the last frame isn't left burned in, then halts it
+func (dm *DisplayManager) shutdown() error {
+	dm.clearImage()
+	if err := dm.drawToDevice(dm.img); err != nil {
+		return fmt.Errorf("failed to clear display: %v", err)
+	}
+	dm.prevFrame = nil
+	if err := dm.dev.Halt(); err != nil {
+		return fmt.Errorf("failed to halt display: %v", err)
+	}
+	return nil
 }
 
-// NetworkChecker interface for getting IP addresses
-type NetworkChecker interface {
-	GetIPv4Address(interfaceName string) string
+// clearImage resets every pixel of the frame buffer, which is sized to the
+// configured display dimensions, to opaque black
+func (dm *DisplayManager) clearImage() {
+	render.Clear(dm.img)
+}
+
+func (dm *DisplayManager) renderCurrentScreen() error {
+	dm.renderFrame()
+	return dm.presentFrame()
 }
 
-// RealNetworkChecker implements NetworkChecker for actual network interfaces
-type RealNetworkChecker struct{}
+// presentFrame publishes the frame renderFrame drew and sends it to the
+// display, unless it matches the one already shown
+func (dm *DisplayManager) presentFrame() error {
+	dm.publishStatus()
+	dm.publishFrame()
+	dm.reportScreenChange()
 
-// GetIPv4Address gets the IPv4 address of the specified interface
-func (r *RealNetworkChecker) GetIPv4Address(interfaceName string) string {
-	iface, err := net.InterfaceByName(interfaceName)
-	if err != nil {
-		return fmt.Sprintf("No %s", interfaceName)
+	// Skip the bus write when nothing on screen changed since the last frame
+	shift := dm.burninShift()
+	if dm.prevFrame != nil && bytes.Equal(dm.prevFrame, dm.img.Pix) && shift == dm.prevShift {
+		return nil
+	}
+	if err := dm.drawToDevice(dm.img); err != nil {
+		return err
 	}
+	dm.prevShift = shift
+	if dm.config.DebugOutput != "" {
+		if err := writeDebugFrame(dm.debugOutputPath(), dm.img); err != nil {
+			slog.Debug("failed to write debug frame", "path", dm.debugOutputPath(), "err", err)
+		}
+	}
+	dm.prevFrame = append(dm.prevFrame[:0], dm.img.Pix...)
+	return nil
+}
 
-	addrs, err := iface.Addrs()
-	if err != nil {
-		return "No IP"
+// drawToDevice sends a frame to the panel, shifted by anti_burnin and
+// turned by the configured rotation
+func (dm *DisplayManager) drawToDevice(img *image.RGBA) error {
+	if shift := clampShift(img, dm.burninShift()); shift != (image.Point{}) {
+		img = render.Shift(img, shift.X, shift.Y)
+	}
+	if rotation := dm.config.displayRotation(); rotation != 0 {
+		img = render.Rotate(img, rotation)
 	}
+	return dm.dev.Draw(img.Bounds(), img, image.Point{0, 0})
+}
 
-	for _, addr := range addrs {
-		if ipnet, ok := addr.(*net.IPNet); ok {
-			if ip4 := ipnet.IP.To4(); ip4 != nil {
-				return ip4.String()
+// fallbackScreenDuration is how long the fallback screen is shown before it
+// is redrawn, in seconds
+const fallbackScreenDuration = 60
+
+// ensureScreens gives a manager without screens a single screen saying so.
+// Validation rejects such configs, but a manager built directly would
+// otherwise divide by zero or index past the end when rotating.
+func (dm *DisplayManager) ensureScreens() {
+	if len(dm.config.Screens) > 0 {
+		return
+	}
+	b := dm.img.Bounds()
+	lineHeight := dm.fontFace().Metrics().Height.Ceil()
+	y := (b.Dy() - 2*lineHeight) / 2
+	dm.config.Screens = []Screen{{
+		Name:     "No screens",
+		Duration: fallbackScreenDuration,
+		Components: []Component{
+			{Type: "text", X: b.Dx() / 2, Y: y + lineHeight, Align: "center", Text: "No screens"},
+			{Type: "text", X: b.Dx() / 2, Y: y + 2*lineHeight, Align: "center", Text: "configured"},
+		},
+	}}
+	dm.config.Order = nil
+	dm.order = nil
+	dm.currentScreen = 0
+}
+
+// renderFrame draws the current screen into the image buffer. It can't fail:
+// a component that does is drawn as the placeholder.
+func (dm *DisplayManager) renderFrame() {
+	dm.ensureScreens()
+	// Clear the image
+	dm.clearImage()
+	r := dm.renderer()
+	r.BeginFrame()
+	defer r.EndFrame()
+
+	screen := dm.config.Screens[dm.currentScreen]
+	for i, comp := range screen.Components {
+		comp.DimText = comp.DimText || screen.DimText
+		// A failed sensor read only affects its own component. A component
+		// that keeps failing is logged when it starts and when it recovers,
+		// not on every update.
+		key := dm.scrollKey(comp) + ":" + comp.Type
+		err := dm.renderComponent(comp)
+		switch {
+		case err != nil:
+			if !dm.failing[key] {
+				slog.Warn("failed to render component", "screen", screen.Name, "component", i, "type", comp.Type, "err", err)
+				if dm.failing == nil {
+					dm.failing = make(map[string]bool)
+				}
+				dm.failing[key] = true
+			}
+			dm.drawPlaceholder(comp, comp.Label)
+			if dm.OnRenderError != nil {
+				dm.OnRenderError(comp, err)
 			}
+		case dm.failing[key]:
+			slog.Info("component recovered", "screen", screen.Name, "component", i, "type", comp.Type)
+			delete(dm.failing, key)
 		}
 	}
-	return "No IPv4"
+	// Components always draw white on black; a white background swaps the two
+	// in the frame buffer, leaving the hardware invert free for burn-in toggling
+	if screen.Background == "white" {
+		render.Invert(dm.img)
+	}
 }
 
-// DisplayDevice interface defines the methods we need from a display
-type DisplayDevice interface {
-	SetContrast(contrast uint8) error
-	Invert(inverted bool) error
-	Draw(r image.Rectangle, src image.Image, sp image.Point) error
-	Halt() error
+// renderer returns the manager's component renderer, pointed at the current
+// frame buffer and settings
+func (dm *DisplayManager) renderer() *render.Renderer {
+	if dm.components == nil {
+		dm.components = render.NewRenderer(dm.img, dm.metricsSource)
+		dm.components.Icons = icons
+		dm.components.Other = dm.drawOtherComponent
+	}
+	r := dm.components
+	r.Img, r.Metrics, r.Face, r.Now = dm.img, dm.metricsSource, dm.face, dm.timeNow
+	r.Recorder = dm.metrics
+	r.Placeholder = dm.config.placeholder()
+	r.TemperatureUnit = dm.config.TemperatureUnit
+	r.TimeLayout = dm.config.clockLayout(true)
+	r.Timezone = dm.config.Timezone
+	r.Screen, r.BlinkOff, r.Recording = dm.currentScreen, dm.blinkOff, dm.recording
+	return r
 }
 
-// DisplayManager handles screen rotation and rendering
-type DisplayManager struct {
-	config         Config
-	currentScreen  int
-	networkChecker NetworkChecker
-	dev            DisplayDevice
-	img            *image.RGBA
-	isInverted     bool
-	timeNow        func() time.Time
+// drawPlaceholder draws the configured placeholder after label, or alone when
+// there is no label, for a value that couldn't be read
+func (dm *DisplayManager) drawPlaceholder(comp Component, label string) {
+	dm.renderer().DrawPlaceholder(comp, label)
 }
 
-// addLabel adds a text label to the image
-func addLabel(img *image.RGBA, x, y int, label string) {
-	point := fixed.Point26_6{X: fixed.I(x), Y: fixed.I(y)}
-	d := &font.Drawer{
-		Dst:  img,
-		Src:  image.NewUniform(color.White),
-		Face: basicfont.Face7x13,
-		Dot:  point,
-	}
-	d.DrawString(label)
+// drawText draws a component's text at its position, honoring its alignment
+func (dm *DisplayManager) drawText(comp Component, text string) {
+	dm.renderer().DrawText(comp, text)
 }
 
-// drawBar draws a horizontal progress bar
-func drawBar(img *image.RGBA, x, y, width, height int, percentage float64) {
-	// Draw border
-	for i := x; i < x+width; i++ {
-		img.Set(i, y, color.White)
-		img.Set(i, y+height, color.White)
-	}
-	for i := y; i < y+height; i++ {
-		img.Set(x, i, color.White)
-		img.Set(x+width, i, color.White)
-	}
+// drawIconText draws text like drawText with ic, if not nil, in front of it
+func (dm *DisplayManager) drawIconText(comp Component, ic *render.Icon, text string) {
+	dm.renderer().DrawIconText(comp, ic, text)
+}
 
-	// Fill bar based on percentage
-	fillWidth := int(float64(width-2) * percentage)
-	for i := x + 1; i < x+1+fillWidth; i++ {
-		for j := y + 1; j < y+height; j++ {
-			img.Set(i, j, color.White)
-		}
+// drawComponentBar draws a component's bar below its text
+func (dm *DisplayManager) drawComponentBar(comp Component, percentage float64) {
+	dm.renderer().DrawComponentBar(comp, percentage)
+}
+
+// alertHidden reports whether a component is past one of its alert
+// thresholds and in the off half of its blink
+func (dm *DisplayManager) alertHidden(comp Component, percent float64) bool {
+	return dm.renderer().AlertHidden(comp, percent)
+}
+
+// scrollKey identifies a component's own state, such as its marquee offset or
+// graph history, by screen and position
+func (dm *DisplayManager) scrollKey(comp Component) string {
+	return dm.renderer().Key(comp)
+}
+
+// advanceScrolls moves every marquee along by one step
+func (dm *DisplayManager) advanceScrolls() {
+	dm.renderer().AdvanceScrolls()
+}
+
+// fontFace returns the face text is drawn with, defaulting to basicfont
+func (dm *DisplayManager) fontFace() font.Face {
+	if dm.face == nil {
+		return basicfont.Face7x13
 	}
+	return dm.face
 }
 
-func NewDisplayManager(configPath string, networkChecker NetworkChecker) (*DisplayManager, error) {
-	// Read configuration
-	configFile, err := os.ReadFile(configPath)
+// sampleNetSpeed returns the receive and transmit rates of an interface in
+// bytes per second. Each component, identified by key, diffs against its own
+// previous sample, so several can watch the same interface. ok is false until
+// a previous sample exists to diff against.
+func (dm *DisplayManager) sampleNetSpeed(key, interfaceName string) (rx, tx float64, ok bool, err error) {
+	counters, err := dm.netCounters.IOCounters()
 	if err != nil {
-		return nil, fmt.Errorf("error reading config file: %v", err)
+		return 0, 0, false, err
 	}
 
-	var config Config
-	if err := yaml.Unmarshal(configFile, &config); err != nil {
-		return nil, fmt.Errorf("error parsing config file: %v", err)
+	var current *psnet.IOCountersStat
+	for i := range counters {
+		if counters[i].Name == interfaceName {
+			current = &counters[i]
+			break
+		}
 	}
-
-	// Initialize display
-	if _, err := host.Init(); err != nil {
-		return nil, fmt.Errorf("failed to initialize periph: %v", err)
+	if current == nil {
+		return 0, 0, false, fmt.Errorf("no counters for interface %s", interfaceName)
 	}
 
-	bus, err := i2creg.Open("")
-	if err != nil {
-		return nil, fmt.Errorf("failed to open I2C: %v", err)
+	if dm.netSamples == nil {
+		dm.netSamples = make(map[string]netSample)
+	}
+	now := dm.timeNow()
+	prev, seen := dm.netSamples[key]
+	dm.netSamples[key] = netSample{
+		bytesRecv: current.BytesRecv,
+		bytesSent: current.BytesSent,
+		at:        now,
 	}
 
-	dev, err := ssd1306.NewI2C(bus, &ssd1306.Opts{
-		W: width,
-		H: height,
-	})
-	if err != nil {
-		return nil, fmt.Errorf("failed to initialize SSD1306: %v", err)
+	elapsed := now.Sub(prev.at).Seconds()
+	// Counters that went backwards were reset, so wait for the next sample
+	if !seen || elapsed <= 0 || current.BytesRecv < prev.bytesRecv || current.BytesSent < prev.bytesSent {
+		return 0, 0, false, nil
 	}
 
-	return &DisplayManager{
-		config:         config,
-		currentScreen:  0,
-		networkChecker: networkChecker,
-		dev:            dev,
-		img:            image.NewRGBA(image.Rect(0, 0, width, height)),
-		timeNow:        time.Now,
-	}, nil
+	rx = float64(current.BytesRecv-prev.bytesRecv) / elapsed
+	tx = float64(current.BytesSent-prev.bytesSent) / elapsed
+	return rx, tx, true, nil
 }
 
-func (dm *DisplayManager) updateBrightness() error {
-	hour := dm.timeNow().Hour()
-	isDaytime := hour >= dm.config.DayStartHour && hour < dm.config.NightStartHour
+// sampleDiskIO returns the read and write rates of a block device in bytes per
+// second. Each component, identified by key, diffs against its own previous
+// sample, so several can watch the same device. ok is false until a previous
+// sample exists to diff against.
+func (dm *DisplayManager) sampleDiskIO(key, device string) (read, write float64, ok bool, err error) {
+	counters, err := dm.diskCounters.IOCounters(device)
+	if err != nil {
+		return 0, 0, false, fmt.Errorf("failed to read disk counters: %v", err)
+	}
+	current, found := counters[device]
+	if !found {
+		return 0, 0, false, fmt.Errorf("no counters for device %s", device)
+	}
 
-	contrast := dimContrast
-	if isDaytime {
-		contrast = brightContrast
+	if dm.diskSamples == nil {
+		dm.diskSamples = make(map[string]diskSample)
+	}
+	now := dm.timeNow()
+	prev, seen := dm.diskSamples[key]
+	dm.diskSamples[key] = diskSample{
+		readBytes:  current.ReadBytes,
+		writeBytes: current.WriteBytes,
+		at:         now,
+	}
+
+	elapsed := now.Sub(prev.at).Seconds()
+	// Counters that went backwards were reset, so wait for the next sample
+	if !seen || elapsed <= 0 || current.ReadBytes < prev.readBytes || current.WriteBytes < prev.writeBytes {
+		return 0, 0, false, nil
 	}
 
-	return dm.dev.SetContrast(uint8(contrast))
+	read = float64(current.ReadBytes-prev.readBytes) / elapsed
+	write = float64(current.WriteBytes-prev.writeBytes) / elapsed
+	return read, write, true, nil
 }
 
-func (dm *DisplayManager) Run() error {
-	screenTicker := time.NewTicker(time.Duration(dm.config.ScreenDuration) * time.Second)
-	defer screenTicker.Stop()
+// sampleTopProcess returns the process that used the most CPU since the
+// previous call, as a percentage of one core. ok is false until two samples
+// have been taken. Processes that started or exited in between are ignored.
+func (dm *DisplayManager) sampleTopProcess() (name string, percent float64, ok bool, err error) {
+	current, err := dm.processLister.CPUTimes()
+	if err != nil {
+		return "", 0, false, err
+	}
 
-	// Update values every second
-	updateTicker := time.NewTicker(1 * time.Second)
-	defer updateTicker.Stop()
+	now := dm.timeNow()
+	prev, prevAt := dm.procSamples, dm.procSampleAt
+	dm.procSamples, dm.procSampleAt = current, now
 
-	var invertTicker *time.Ticker
-	var invertChan <-chan time.Time
-	if dm.config.InvertDuration > 0 {
-		invertTicker = time.NewTicker(time.Duration(dm.config.InvertDuration) * time.Second)
-		defer invertTicker.Stop()
-		invertChan = invertTicker.C
+	elapsed := now.Sub(prevAt).Seconds()
+	if prev == nil || elapsed <= 0 {
+		return "", 0, false, nil
 	}
 
-	// Initialize brightness based on current time
-	if err := dm.updateBrightness(); err != nil {
-		return fmt.Errorf("failed to set initial brightness: %v", err)
+	for pid, cur := range current {
+		before, seen := prev[pid]
+		// A lower CPU time means the PID was reused by a new process
+		if !seen || cur.seconds < before.seconds {
+			continue
+		}
+		pct := (cur.seconds - before.seconds) / elapsed * 100
+		if !ok || pct > percent || (pct == percent && cur.name < name) {
+			name, percent, ok = cur.name, pct, true
+		}
 	}
+	return name, percent, ok, nil
+}
 
-	// Check brightness every minute
-	brightnessTicker := time.NewTicker(1 * time.Minute)
-	defer brightnessTicker.Stop()
+// firstIPv6Address returns the IPv6 address of the first of the component's
+// interfaces (or the global interface) that has one, or "" when none do
+func (dm *DisplayManager) firstIPv6Address(comp Component) string {
+	interfaces := comp.Interfaces
+	if len(interfaces) == 0 {
+		interfaces = []string{dm.config.NetworkInterface}
+	}
+	for _, name := range interfaces {
+		if addr := dm.networkChecker.GetIPv6Address(name); net.ParseIP(addr) != nil {
+			return addr
+		}
+	}
+	return ""
+}
 
-	// Render initial screen
-	if err := dm.renderCurrentScreen(); err != nil {
-		return err
+// hourInWindow reports whether hour falls in [start, end), wrapping past
+// midnight when start is after end. Equal hours are an empty window.
+func hourInWindow(hour, start, end int) bool {
+	if start <= end {
+		return hour >= start && hour < end
 	}
+	return hour >= start || hour < end
+}
 
-	for {
-		select {
-		case <-screenTicker.C:
-			dm.currentScreen = (dm.currentScreen + 1) % len(dm.config.Screens)
-			if err := dm.renderCurrentScreen(); err != nil {
-				return err
-			}
+// componentVisible reports whether a component's start_hour/end_hour
+// schedule allows it to be drawn now. Unscheduled components always are.
+func (dm *DisplayManager) componentVisible(comp Component) bool {
+	if comp.StartHour == nil && comp.EndHour == nil {
+		return true
+	}
+	start, end := 0, 24
+	if comp.StartHour != nil {
+		start = *comp.StartHour
+	}
+	if comp.EndHour != nil {
+		end = *comp.EndHour
+	}
+	return hourInWindow(dm.timeNow().Hour(), start, end)
+}
 
-		case <-updateTicker.C:
-			if err := dm.renderCurrentScreen(); err != nil {
-				return err
-			}
+func (dm *DisplayManager) renderComponent(comp Component) error {
+	if !dm.componentVisible(comp) {
+		return nil
+	}
+	if refreshInterval(comp) > 0 && !backgroundTypes[comp.Type] {
+		return dm.renderCached(comp)
+	}
+	return dm.drawComponent(comp)
+}
 
-		case <-invertChan:
-			dm.isInverted = !dm.isInverted
-			if err := dm.dev.Invert(dm.isInverted); err != nil {
-				return fmt.Errorf("failed to toggle invert: %v", err)
-			}
+// backgroundTypes lists the component types that fetch on their own schedule
+// in the background. Caching their rendering too would only delay new values.
+var backgroundTypes = map[string]bool{
+	"exec":    true,
+	"docker":  true,
+	"gputemp": true,
+}
 
-		case <-brightnessTicker.C:
-			if err := dm.updateBrightness(); err != nil {
-				return fmt.Errorf("failed to update brightness: %v", err)
-			}
+// stale reports whether a value last updated at updated is more than
+// stale_after refresh intervals old
+func stale(c Component, now, updated time.Time) bool {
+	return c.StaleAfter > 0 && now.Sub(updated) > time.Duration(c.StaleAfter)*refreshInterval(c)
+}
+
+// refreshInterval returns how long a component's value is reused, 0 for
+// re-sampling on every update. Background types fetch once per interval.
+// Exec components default to 10 seconds so a command isn't started every
+// second, docker to 5 to spare the daemon and gputemp to 5 so vcgencmd isn't
+// forked on every update.
+func refreshInterval(c Component) time.Duration {
+	if c.RefreshSeconds == 0 {
+		switch c.Type {
+		case "exec":
+			return defaultExecRefresh
+		case "docker":
+			return defaultDockerRefresh
+		case "gputemp":
+			return defaultGPUTempRefresh
 		}
 	}
+	return time.Duration(c.RefreshSeconds) * time.Second
 }
 
-func (dm *DisplayManager) renderCurrentScreen() error {
-	// Clear the image
-	for i := 0; i < width*height*4; i++ {
-		dm.img.Pix[i] = 0
-	}
+// componentLayer is a component's last sample, reused until it is due for a
+// refresh. Text is kept apart from the pixels so marquees keep scrolling, and
+// an alerting layer keeps blinking.
+type componentLayer struct {
+	at               time.Time   // last sample, successful or not
+	updated          time.Time   // last successful sample
+	img              *image.RGBA // everything drawn but text, such as bars and graphs
+	render.Recording             // the text, and whether the sample was past an alert threshold
+}
 
-	screen := dm.config.Screens[dm.currentScreen]
-	for _, comp := range screen.Components {
-		if err := dm.renderComponent(comp); err != nil {
-			return fmt.Errorf("error rendering component: %v", err)
+// renderCached draws a component with refresh_seconds from its cached layer,
+// re-sampling its data source only once the interval has passed. With
+// stale_after, a failed sample keeps the last good layer, which is dimmed once
+// it is more than stale_after intervals old.
+func (dm *DisplayManager) renderCached(comp Component) error {
+	if dm.layers == nil {
+		dm.layers = make(map[string]componentLayer)
+	}
+	key := dm.scrollKey(comp) + ":" + comp.Type
+	now := dm.timeNow()
+	layer, ok := dm.layers[key]
+	if !ok || now.Sub(layer.at) >= refreshInterval(comp) {
+		// Render alone into a blank frame so the layer holds only this component
+		frame := dm.img
+		dm.img = image.NewRGBA(frame.Bounds())
+		dm.recording = &render.Recording{}
+		err := dm.drawComponent(comp)
+		sample := &componentLayer{img: dm.img, Recording: *dm.recording}
+		dm.img, dm.recording = frame, nil
+		switch {
+		case err == nil:
+			sample.at, sample.updated = now, now
+			layer = *sample
+		case ok && comp.StaleAfter > 0:
+			// Try again after another interval, showing the last value meanwhile
+			slog.Warn("failed to refresh component, showing its last value", "type", comp.Type, "err", err)
+			layer.at = now
+		default:
+			return err
 		}
+		dm.layers[key] = layer
+	}
+	if layer.Alerting && dm.blinkOff {
+		return nil
 	}
 
-	return dm.dev.Draw(dm.img.Bounds(), dm.img, image.Point{0, 0})
+	img := image.NewRGBA(dm.img.Bounds())
+	copy(img.Pix, layer.img.Pix)
+	frame := dm.img
+	dm.img = img
+	for _, t := range layer.Texts {
+		dm.drawIconText(t.Comp, t.Icon, t.Text)
+	}
+	dm.img = frame
+	if stale(comp, now, layer.updated) {
+		img = render.Dim(img)
+	}
+	draw.Draw(dm.img, dm.img.Bounds(), img, image.Point{}, draw.Over)
+	return nil
 }
 
-func (dm *DisplayManager) renderComponent(comp Component) error {
+// drawComponent samples a component's data source and draws it
+func (dm *DisplayManager) drawComponent(comp Component) error {
+	return dm.renderer().Draw(comp)
+}
+
+// drawOtherComponent draws the component types whose data comes from the
+// manager's own readers and clients rather than the metrics provider
+func (dm *DisplayManager) drawOtherComponent(comp Component) error {
 	switch comp.Type {
-	case "time":
-		timeFormat := comp.TimeFormat
-		if timeFormat == "" {
-			timeFormat = "15:04:05" // default to 24-hour time with seconds
-		}
-		currentTime := time.Now().Format(timeFormat)
-		addLabel(dm.img, comp.X, comp.Y, fmt.Sprintf("%s%s",
-			func() string {
-				if comp.Label != "" {
-					return comp.Label + ": "
-				}
-				return ""
-			}(),
-			currentTime))
+	case "hostname":
+		if dm.hostInfo == nil {
+			info, err := dm.hostReader.HostInfo()
+			if err != nil {
+				return err
+			}
+			dm.hostInfo = &info
+		}
+		text := formatHostInfo(*dm.hostInfo, comp.Fields)
+		text = render.Labeled(comp.Label, text)
+		dm.drawText(comp, text)
 
 	case "ip":
-		ipAddr := dm.networkChecker.GetIPv4Address(dm.config.NetworkInterface)
-		addLabel(dm.img, comp.X, comp.Y, fmt.Sprintf("%s: %s", comp.Label, ipAddr))
+		var ipAddr string
+		switch {
+		case comp.Family == "ipv6":
+			ipAddr = dm.firstIPv6Address(comp)
+		case len(comp.Interfaces) > 0:
+			ipAddr = dm.networkChecker.GetFirstIPv4Address(comp.Interfaces)
+		default:
+			ipAddr = dm.networkChecker.GetIPv4Address(dm.config.NetworkInterface)
+		}
+		// The checker describes a missing address, e.g. "No IP"
+		if net.ParseIP(ipAddr) == nil {
+			ipAddr = dm.config.placeholder()
+		}
+		dm.drawText(comp, render.Labeled(comp.Label, ipAddr))
 
-	case "cpu":
-		cpuPercent, err := cpu.Percent(0, false)
+	case "swap":
+		swapInfo, err := dm.swapReader.SwapMemory()
 		if err != nil {
 			return err
 		}
-		addLabel(dm.img, comp.X, comp.Y, fmt.Sprintf("%s: %.1f%%", comp.Label, cpuPercent[0]))
+		// Without swap configured the used percent is meaningless
+		if swapInfo.Total == 0 {
+			dm.drawText(comp, render.Labeled(comp.Label, "off"))
+			return nil
+		}
+		if dm.alertHidden(comp, swapInfo.UsedPercent) {
+			return nil
+		}
+		dm.drawText(comp, render.Labeled(comp.Label, fmt.Sprintf("%.1f%%", swapInfo.UsedPercent)))
+		if comp.ShowBar {
+			dm.drawComponentBar(comp, swapInfo.UsedPercent/100.0)
+		}
+
+	case "diskall":
+		used, total, ok := dm.diskTotal(comp.Mountpoints)
+		if !ok {
+			dm.drawPlaceholder(comp, comp.Label)
+			return nil
+		}
+		percent := float64(used) / float64(total) * 100
+		if dm.alertHidden(comp, percent) {
+			return nil
+		}
+		dm.drawText(comp, render.Labeled(comp.Label, fmt.Sprintf("%.0f%% %s", percent, render.FormatUsedTotal(used, total))))
+		if comp.ShowBar {
+			dm.drawComponentBar(comp, percent/100.0)
+		}
+
+	case "gputemp":
+		label := comp.Label
+		if label == "" {
+			label = "GPU"
+		}
+		tempCelsius, ok, updated, err := dm.gpuTemperature(comp)
+		switch {
+		case !ok || (err != nil && comp.StaleAfter == 0):
+			// vcgencmd only exists on a Raspberry Pi, and the first reading
+			// may still be running
+			dm.drawPlaceholder(comp, label)
+			return nil
+		case stale(comp, dm.timeNow(), updated):
+			comp.DimText = true
+		}
+		dm.metrics.SetTemperature("vcgencmd", tempCelsius)
+		unit := dm.config.TemperatureUnit
+		if unit == "" {
+			unit = "C"
+		}
+		dm.drawText(comp, render.Labeled(label, fmt.Sprintf("%.1f %s", render.ConvertTemperature(tempCelsius, unit), unit)))
 		if comp.ShowBar {
-			drawBar(dm.img, comp.X, comp.Y+5, comp.BarWidth, barHeight, cpuPercent[0]/100.0)
+			dm.drawComponentBar(comp, tempCelsius/100.0)
+		}
+
+	case "weather":
+		report, ok := dm.currentWeather()
+		if !ok {
+			label := comp.Label
+			if label == "" {
+				label = "weather"
+			}
+			dm.drawPlaceholder(comp, label)
+			return nil
+		}
+		unit := dm.config.TemperatureUnit
+		if unit == "" {
+			unit = "C"
+		}
+		text := fmt.Sprintf("%.0f%s %s", render.ConvertTemperature(report.tempCelsius, unit), unit, report.description)
+		text = render.Labeled(comp.Label, text)
+		dm.drawText(comp, text)
+
+	case "calendar":
+		label := comp.Label
+		if label == "" {
+			label = "Next"
+		}
+		event, found, ok := dm.nextEvent()
+		switch {
+		case !ok:
+			dm.drawPlaceholder(comp, label)
+		case !found:
+			dm.drawText(comp, "No events")
+		default:
+			timeFormat := comp.TimeFormat
+			if timeFormat == "" {
+				timeFormat = dm.config.clockLayout(false)
+			}
+			dm.drawText(comp, render.Labeled(label, fmt.Sprintf("%s %s", event.title, formatEventTime(event, dm.timeNow(), timeFormat))))
 		}
 
-	case "memory":
-		memInfo, err := mem.VirtualMemory()
+	case "battery":
+		state, present, err := dm.batteryReader.Battery()
 		if err != nil {
 			return err
 		}
-		addLabel(dm.img, comp.X, comp.Y, fmt.Sprintf("%s: %.1f%%", comp.Label, memInfo.UsedPercent))
+		if !present {
+			dm.drawText(comp, render.Labeled(comp.Label, "AC"))
+			return nil
+		}
+		if dm.alertHidden(comp, state.percent) {
+			return nil
+		}
+		// basicfont has no lightning glyph, so + marks charging
+		text := render.Labeled(comp.Label, fmt.Sprintf("%.0f%%", state.percent))
+		if state.charging {
+			text += " +"
+		}
+		dm.drawText(comp, text)
 		if comp.ShowBar {
-			drawBar(dm.img, comp.X, comp.Y+5, comp.BarWidth, barHeight, float64(memInfo.UsedPercent)/100.0)
+			dm.drawComponentBar(comp, state.percent/100.0)
 		}
 
-	case "disk":
-		usage, err := disk.Usage("/")
+	case "fan":
+		label := comp.Label
+		if label == "" {
+			label = "Fan"
+		}
+		rpm, present, err := dm.fanReader.FanRPM(comp.Source)
 		if err != nil {
 			return err
 		}
-		addLabel(dm.img, comp.X, comp.Y, fmt.Sprintf("%s: %.1f%%", comp.Label, usage.UsedPercent))
+		if !present {
+			dm.drawPlaceholder(comp, label)
+			return nil
+		}
+		dm.drawText(comp, render.Labeled(label, fmt.Sprintf("%d rpm", rpm)))
+		if comp.ShowBar {
+			dm.drawComponentBar(comp, float64(rpm)/float64(comp.MaxRPM))
+		}
+
+	case "loadavg":
+		avg, err := dm.loadReader.LoadAverage()
+		if err != nil {
+			// Load averages aren't available on every platform
+			dm.drawPlaceholder(comp, comp.Label)
+			return nil
+		}
+		dm.drawText(comp, render.Labeled(comp.Label, fmt.Sprintf("%.2f %.2f %.2f", avg.Load1, avg.Load5, avg.Load15)))
 		if comp.ShowBar {
-			drawBar(dm.img, comp.X, comp.Y+5, comp.BarWidth, barHeight, float64(usage.UsedPercent)/100.0)
+			cores := dm.loadReader.NumCPU()
+			if cores < 1 {
+				cores = 1
+			}
+			// A load equal to the core count means a fully saturated machine
+			loadPercent := avg.Load1 / float64(cores)
+			if loadPercent > 1 {
+				loadPercent = 1
+			}
+			dm.drawComponentBar(comp, loadPercent)
+		}
+
+	case "uptime":
+		seconds, err := dm.uptimeReader.Uptime()
+		if err != nil {
+			return fmt.Errorf("failed to read uptime: %v", err)
 		}
+		uptime := formatUptime(time.Duration(seconds)*time.Second, comp.TimeFormat)
+		uptime = render.Labeled(comp.Label, uptime)
+		dm.drawText(comp, uptime)
 
-	case "temperature":
-		temp, err := os.ReadFile(tempFile)
+	case "processes":
+		if comp.Top {
+			name, percent, ok, err := dm.sampleTopProcess()
+			if err != nil {
+				return err
+			}
+			if !ok {
+				dm.drawText(comp, render.Labeled(comp.Label, "--"))
+				return nil
+			}
+			dm.drawText(comp, render.Labeled(comp.Label, fmt.Sprintf("%s %.0f%%", name, percent)))
+			return nil
+		}
+		pids, err := dm.processLister.Pids()
 		if err != nil {
-			return fmt.Errorf("failed to read temperature: %v", err)
+			return err
+		}
+		dm.drawText(comp, render.Labeled(comp.Label, strconv.Itoa(len(pids))))
+
+	case "netspeed":
+		label := comp.Label
+		if label == "" {
+			label = dm.config.NetworkInterface
 		}
-		tempValue := string(temp)
-		if len(tempValue) > 0 {
-			tempValue = tempValue[:len(tempValue)-1] // Remove newline
+		rx, tx, ok, err := dm.sampleNetSpeed(dm.scrollKey(comp), dm.config.NetworkInterface)
+		if err != nil {
+			return err
 		}
-		tempCelsius := float64(0)
-		if _, err := fmt.Sscanf(tempValue, "%f", &tempCelsius); err != nil {
-			return fmt.Errorf("failed to parse temperature: %v", err)
+		if !ok {
+			dm.drawText(comp, render.Labeled(label, "--"))
+			return nil
 		}
-		tempCelsius /= 1000.0 // Convert to Celsius
-		addLabel(dm.img, comp.X, comp.Y, fmt.Sprintf("%s: %.1f C", comp.Label, tempCelsius))
+		// basicfont has no arrow glyphs, so v/^ stand in for down/up
+		dm.drawText(comp, render.Labeled(label, fmt.Sprintf("%sv %s^", formatRate(rx), formatRate(tx))))
 		if comp.ShowBar {
-			drawBar(dm.img, comp.X, comp.Y+5, comp.BarWidth, barHeight, tempCelsius/100.0)
+			maxMbps := comp.MaxMbps
+			if maxMbps <= 0 {
+				maxMbps = defaultMaxMbps
+			}
+			mbps := (rx + tx) * 8 / 1000000
+			barPercent := mbps / maxMbps
+			if barPercent > 1 {
+				barPercent = 1
+			}
+			dm.drawComponentBar(comp, barPercent)
+		}
+
+	case "exec":
+		value, ok, updated, err := dm.execOutput(comp)
+		switch {
+		case err != nil && (!ok || comp.StaleAfter == 0):
+			// Show the failure until the next run instead of older output
+			value = "ERR"
+		case !ok:
+			// The first run hasn't finished yet
+			value = dm.config.placeholder()
+		case stale(comp, dm.timeNow(), updated):
+			comp.DimText = true
+		}
+		dm.drawText(comp, render.Labeled(comp.Label, value))
+
+	case "wifi":
+		text, bars, err := dm.wifiText(comp)
+		if err != nil {
+			return err
+		}
+		if comp.SignalBars {
+			dm.drawIconText(comp, signalIcon(bars), text)
+		} else {
+			dm.drawText(comp, text)
+		}
+
+	case "docker":
+		label := comp.Label
+		if label == "" {
+			label = "Docker"
+		}
+		socket := comp.Source
+		if socket == "" {
+			socket = defaultDockerSocket
+		}
+		running, ok, updated, err := dm.dockerRunning(comp, socket)
+		switch {
+		case !ok || (err != nil && comp.StaleAfter == 0):
+			// Docker not installed or not running is a normal state to show,
+			// as is waiting for the first request
+			dm.drawPlaceholder(comp, label)
+			return nil
+		case stale(comp, dm.timeNow(), updated):
+			comp.DimText = true
+		}
+		dm.drawText(comp, render.Labeled(label, fmt.Sprintf("%d up", running)))
+
+	case "ping":
+		label := comp.Label
+		if label == "" {
+			label = comp.Host
 		}
+		result, checked := dm.latestPing(comp)
+		if !checked {
+			// The first check is still running
+			dm.drawPlaceholder(comp, label)
+			return nil
+		}
+		dm.drawText(comp, pingText(label, result))
+
+	case "diskio":
+		// Counters are keyed by kernel name, so accept /dev/sda as well as sda
+		device := strings.TrimPrefix(comp.Device, "/dev/")
+		label := comp.Label
+		if label == "" {
+			label = device
+		}
+		read, write, ok, err := dm.sampleDiskIO(dm.scrollKey(comp), device)
+		if err != nil {
+			return err
+		}
+		if !ok {
+			dm.drawText(comp, render.Labeled(label, "--"))
+			return nil
+		}
+		dm.drawText(comp, render.Labeled(label, fmt.Sprintf("%s r %s w", formatRate(read), formatRate(write))))
 
 	}
 
@@ -340,13 +2790,39 @@ func (dm *DisplayManager) renderComponent(comp Component) error {
 }
 
 func main() {
+	configPath := flag.String("config", "config.yaml", "configuration file, - for standard input or an http(s) URL fetched at startup; parsed as JSON when it ends in .json and as YAML otherwise")
+	preview := flag.String("preview", "", "render each screen to numbered PNG files (e.g. out.png -> out0.png, out1.png) instead of driving the display")
+	check := flag.Bool("check", false, "validate the config (the -config file, or the file named after the flags), print its screens and exit without driving the display")
+	flag.Parse()
+
+	setupLogging()
+
+	if *check {
+		path := *configPath
+		if flag.NArg() > 0 {
+			path = flag.Arg(0)
+		}
+		if err := checkConfig(path, os.Stdout); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+		return
+	}
+
+	if *preview != "" {
+		if err := writePreview(*configPath, *preview); err != nil {
+			log.Fatalf("failed to write preview: %v", err)
+		}
+		return
+	}
+
 	networkChecker := &RealNetworkChecker{}
-	dm, err := NewDisplayManager("config.yaml", networkChecker)
+	managers, err := NewDisplayManagers(*configPath, networkChecker)
 	if err != nil {
-		panic(fmt.Sprintf("failed to initialize display manager: %v", err))
+		log.Fatalf("failed to initialize display manager: %v", err)
 	}
 
-	if err := dm.Run(); err != nil {
-		panic(fmt.Sprintf("display manager error: %v", err))
+	if err := runDisplays(context.Background(), managers); err != nil {
+		log.Fatalf("display manager error: %v", err)
 	}
 }
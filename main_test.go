@@ -1,11 +1,33 @@
 package main
 
 import (
+	"bytes"
+	"context"
 	"fmt"
 	"image"
+	"image/color"
+	"image/draw"
+	"log/slog"
+	"math"
+	"net"
 	"os"
+	"os/exec"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"sync/atomic"
 	"testing"
 	"time"
+
+	"github.com/shirou/gopsutil/v3/disk"
+	pshost "github.com/shirou/gopsutil/v3/host"
+	"github.com/shirou/gopsutil/v3/load"
+	"github.com/shirou/gopsutil/v3/mem"
+	psnet "github.com/shirou/gopsutil/v3/net"
+	"github.com/swilcox/go-monitor-ssd1306/render"
+	"golang.org/x/image/font"
+	"golang.org/x/image/font/basicfont"
+	"golang.org/x/image/font/gofont/goregular"
 )
 
 // MockDisplay implements the DisplayDevice interface for testing
@@ -13,6 +35,8 @@ type MockDisplay struct {
 	contrast  uint8
 	inverted  bool
 	lastImage *image.RGBA
+	drawCount int
+	halted    bool
 	t         *testing.T  // for debug output
 }
 
@@ -35,6 +59,7 @@ func (d *MockDisplay) Invert(inverted bool) error {
 
 func (d *MockDisplay) Draw(r image.Rectangle, src image.Image, sp image.Point) error {
 	d.t.Logf("Draw called with bounds: %v", r)
+	d.drawCount++
 	if src == nil {
 		d.t.Log("Draw called with nil source image")
 		return fmt.Errorf("nil source image")
@@ -51,60 +76,10 @@ func (d *MockDisplay) Draw(r image.Rectangle, src image.Image, sp image.Point) e
 }
 
 func (d *MockDisplay) Halt() error {
+	d.halted = true
 	return nil
 }
 
-// TestDrawBar tests the drawBar function
-func TestDrawBar(t *testing.T) {
-	tests := []struct {
-		name       string
-		percentage float64
-		wantEmpty  bool
-		wantFull   bool
-	}{
-		{
-			name:       "Empty bar",
-			percentage: 0.0,
-			wantEmpty:  true,
-			wantFull:   false,
-		},
-		{
-			name:       "Full bar",
-			percentage: 1.0,
-			wantEmpty:  false,
-			wantFull:   true,
-		},
-		{
-			name:       "Half bar",
-			percentage: 0.5,
-			wantEmpty:  false,
-			wantFull:   false,
-		},
-	}
-
-	for _, tt := range tests {
-		t.Run(tt.name, func(t *testing.T) {
-			img := image.NewRGBA(image.Rect(0, 0, width, height))
-			drawBar(img, 10, 10, 50, barHeight, tt.percentage)
-
-			// Check if bar is drawn correctly
-			middle := img.RGBAAt(35, 13) // Point in middle of bar
-			if tt.wantEmpty && middle.R != 0 {
-				t.Errorf("Expected empty bar, but got color at middle point")
-			}
-			if tt.wantFull && middle.R == 0 {
-				t.Errorf("Expected full bar, but got no color at middle point")
-			}
-
-			// Check border
-			border := img.RGBAAt(10, 10) // Top-left corner
-			if border.R == 0 {
-				t.Errorf("Expected border to be drawn")
-			}
-		})
-	}
-}
-
 // TestDisplayManager tests the display manager functionality
 func TestDisplayManager(t *testing.T) {
 	// Create a temporary config file for testing
@@ -154,7 +129,7 @@ screens:
 	dm := &DisplayManager{
 		dev:            mockDisplay,
 		networkChecker: checker,
-		img:            image.NewRGBA(image.Rect(0, 0, width, height)),
+		img:            blankFrame(),
 		timeNow:        timeNow,
 		config: Config{
 			DayStartHour:   7,
@@ -216,10 +191,3713 @@ screens:
 
 // MockNetworkChecker implements NetworkChecker for testing
 type MockNetworkChecker struct {
-	ipAddress string
+	ipAddress   string
+	addresses   map[string]string // per-interface addresses for list lookups
+	ipv6Address string
 }
 
 func (m *MockNetworkChecker) GetIPv4Address(interfaceName string) string {
 	return m.ipAddress
 }
 
+func (m *MockNetworkChecker) GetFirstIPv4Address(interfaceNames []string) string {
+	for _, name := range interfaceNames {
+		if addr, ok := m.addresses[name]; ok {
+			return addr
+		}
+	}
+	return "No IP"
+}
+
+func (m *MockNetworkChecker) GetIPv6Address(interfaceName string) string {
+	if m.ipv6Address == "" {
+		return "No IPv6"
+	}
+	return m.ipv6Address
+}
+
+// MockLoadReader implements LoadReader for testing
+type MockLoadReader struct {
+	avg   *load.AvgStat
+	cores int
+	err   error
+}
+
+func (m *MockLoadReader) LoadAverage() (*load.AvgStat, error) {
+	return m.avg, m.err
+}
+
+func (m *MockLoadReader) NumCPU() int {
+	return m.cores
+}
+
+// blankFrame returns a display-sized frame cleared to opaque black, as
+// renderFrame clears the frame buffer
+func blankFrame() *image.RGBA {
+	img := image.NewRGBA(image.Rect(0, 0, width, height))
+	render.Clear(img)
+	return img
+}
+
+// labelImage renders a label onto a blank image for comparison
+func labelImage(x, y int, label string) *image.RGBA {
+	img := blankFrame()
+	render.AddLabel(img, basicfont.Face7x13, x, y, label)
+	return img
+}
+
+// TestLoadAvgComponent tests the loadavg component with a fake load source
+func TestLoadAvgComponent(t *testing.T) {
+	tests := []struct {
+		name      string
+		reader    *MockLoadReader
+		showBar   bool
+		wantLabel string
+		wantFull  bool
+	}{
+		{
+			name:      "Normal load",
+			reader:    &MockLoadReader{avg: &load.AvgStat{Load1: 0.42, Load5: 0.31, Load15: 0.28}, cores: 4},
+			wantLabel: "Load: 0.42 0.31 0.28",
+		},
+		{
+			name:      "Saturated load with bar",
+			reader:    &MockLoadReader{avg: &load.AvgStat{Load1: 4, Load5: 3, Load15: 2}, cores: 4},
+			showBar:   true,
+			wantLabel: "Load: 4.00 3.00 2.00",
+			wantFull:  true,
+		},
+		{
+			name:      "Unavailable",
+			reader:    &MockLoadReader{err: fmt.Errorf("not implemented")},
+			showBar:   true,
+			wantLabel: "Load: N/A",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dm := &DisplayManager{
+				loadReader: tt.reader,
+				img:        blankFrame(),
+			}
+			comp := Component{Type: "loadavg", X: 5, Y: 12, Label: "Load", ShowBar: tt.showBar, BarWidth: 100}
+			if err := dm.renderComponent(comp); err != nil {
+				t.Fatalf("Failed to render component: %v", err)
+			}
+
+			want := labelImage(5, 12, tt.wantLabel)
+			if tt.wantFull {
+				render.DrawBar(want, 5, 17, 100, render.BarHeight, 1.0, 1)
+			}
+			if !bytes.Equal(dm.img.Pix, want.Pix) {
+				t.Errorf("Rendered image does not match %q", tt.wantLabel)
+			}
+		})
+	}
+}
+
+// MockNetCounterReader implements NetCounterReader, returning one snapshot per call
+type MockNetCounterReader struct {
+	snapshots [][]psnet.IOCountersStat
+	calls     int
+}
+
+func (m *MockNetCounterReader) IOCounters() ([]psnet.IOCountersStat, error) {
+	snapshot := m.snapshots[m.calls]
+	m.calls++
+	return snapshot, nil
+}
+
+// TestNetSpeedComponent tests rate computation between successive renders
+func TestNetSpeedComponent(t *testing.T) {
+	start := time.Date(2024, 1, 1, 12, 0, 0, 0, time.Local)
+	now := start
+	reader := &MockNetCounterReader{
+		snapshots: [][]psnet.IOCountersStat{
+			{{Name: "lo", BytesRecv: 999, BytesSent: 999}, {Name: "eth0", BytesRecv: 1000, BytesSent: 500}},
+			{{Name: "lo", BytesRecv: 999, BytesSent: 999}, {Name: "eth0", BytesRecv: 1000 + 2*1024*1024, BytesSent: 500 + 2048}},
+		},
+	}
+	dm := &DisplayManager{
+		config:      Config{NetworkInterface: "eth0"},
+		netCounters: reader,
+		img:         blankFrame(),
+		timeNow:     func() time.Time { return now },
+	}
+	comp := Component{Type: "netspeed", X: 5, Y: 12}
+
+	// First render has nothing to diff against
+	if err := dm.renderComponent(comp); err != nil {
+		t.Fatalf("Failed to render component: %v", err)
+	}
+	if want := labelImage(5, 12, "eth0: --"); !bytes.Equal(dm.img.Pix, want.Pix) {
+		t.Error("Expected placeholder on first render")
+	}
+
+	now = start.Add(2 * time.Second)
+	rx, tx, ok, err := dm.sampleNetSpeed(dm.scrollKey(comp), "eth0")
+	if err != nil || !ok {
+		t.Fatalf("Expected a rate on second sample, got ok=%v err=%v", ok, err)
+	}
+	if rx != 1024*1024 {
+		t.Errorf("Expected rx of 1MB/s, got %v", rx)
+	}
+	if tx != 1024 {
+		t.Errorf("Expected tx of 1KB/s, got %v", tx)
+	}
+	if got := formatRate(rx); got != "1.0MB/s" {
+		t.Errorf("formatRate(%v) = %q, want %q", rx, got, "1.0MB/s")
+	}
+	if got := formatRate(1<<20 - 1); got != "1.0MB/s" {
+		t.Errorf("formatRate(1<<20 - 1) = %q, want %q", got, "1.0MB/s")
+	}
+}
+
+// TestNetSpeedSharedInterface tests that two netspeed components on the same
+// interface each show a rate once they have a previous sample
+func TestNetSpeedSharedInterface(t *testing.T) {
+	start := time.Date(2024, 1, 1, 12, 0, 0, 0, time.Local)
+	now := start
+	first := []psnet.IOCountersStat{{Name: "eth0", BytesRecv: 1000, BytesSent: 500}}
+	second := []psnet.IOCountersStat{{Name: "eth0", BytesRecv: 1000 + 2048, BytesSent: 500}}
+	dm := &DisplayManager{
+		config:      Config{NetworkInterface: "eth0"},
+		netCounters: &MockNetCounterReader{snapshots: [][]psnet.IOCountersStat{first, first, second, second}},
+		img:         blankFrame(),
+		timeNow:     func() time.Time { return now },
+	}
+	comps := []Component{{Type: "netspeed", X: 5, Y: 12}, {Type: "netspeed", X: 5, Y: 40}}
+	for _, comp := range comps {
+		if _, _, ok, err := dm.sampleNetSpeed(dm.scrollKey(comp), "eth0"); err != nil || ok {
+			t.Fatalf("Expected no rate on a component's first sample, got ok=%v err=%v", ok, err)
+		}
+	}
+
+	now = start.Add(2 * time.Second)
+	for i, comp := range comps {
+		rx, _, ok, err := dm.sampleNetSpeed(dm.scrollKey(comp), "eth0")
+		if err != nil || !ok {
+			t.Fatalf("Component %d: expected a rate, got ok=%v err=%v", i, ok, err)
+		}
+		if rx != 1024 {
+			t.Errorf("Component %d: expected rx of 1KB/s, got %v", i, rx)
+		}
+	}
+}
+
+// MockDiskCounterReader implements DiskCounterReader, returning one snapshot per call
+type MockDiskCounterReader struct {
+	snapshots []map[string]disk.IOCountersStat
+	calls     int
+}
+
+func (m *MockDiskCounterReader) IOCounters(names ...string) (map[string]disk.IOCountersStat, error) {
+	snapshot := m.snapshots[m.calls]
+	m.calls++
+	return snapshot, nil
+}
+
+// TestDiskIOComponent tests rate computation between successive renders
+func TestDiskIOComponent(t *testing.T) {
+	start := time.Date(2024, 1, 1, 12, 0, 0, 0, time.Local)
+	now := start
+	reader := &MockDiskCounterReader{
+		snapshots: []map[string]disk.IOCountersStat{
+			{"sda": {Name: "sda", ReadBytes: 5000, WriteBytes: 7000}},
+			{"sda": {Name: "sda", ReadBytes: 5000 + 8*1024*1024, WriteBytes: 7000 + 2*1024*1024}},
+		},
+	}
+	dm := &DisplayManager{
+		diskCounters: reader,
+		img:          blankFrame(),
+		timeNow:      func() time.Time { return now },
+	}
+	comp := Component{Type: "diskio", X: 5, Y: 12, Device: "/dev/sda"}
+
+	// First render has nothing to diff against
+	if err := dm.renderComponent(comp); err != nil {
+		t.Fatalf("Failed to render component: %v", err)
+	}
+	if want := labelImage(5, 12, "sda: --"); !bytes.Equal(dm.img.Pix, want.Pix) {
+		t.Error("Expected placeholder on first render")
+	}
+
+	now = start.Add(2 * time.Second)
+	dm.clearImage()
+	if err := dm.renderComponent(comp); err != nil {
+		t.Fatalf("Failed to render component: %v", err)
+	}
+	if want := labelImage(5, 12, "sda: 4.0MB/s r 1.0MB/s w"); !bytes.Equal(dm.img.Pix, want.Pix) {
+		t.Error("Expected 4MB/s read and 1MB/s write on the second render")
+	}
+}
+
+// TestDiskIOSharedDevice tests that two diskio components on the same device
+// each diff against their own previous sample, rather than the other's
+func TestDiskIOSharedDevice(t *testing.T) {
+	start := time.Date(2024, 1, 1, 12, 0, 0, 0, time.Local)
+	now := start
+	first := map[string]disk.IOCountersStat{"sda": {Name: "sda", ReadBytes: 5000, WriteBytes: 7000}}
+	second := map[string]disk.IOCountersStat{"sda": {Name: "sda", ReadBytes: 5000 + 2048, WriteBytes: 7000}}
+	dm := &DisplayManager{
+		diskCounters: &MockDiskCounterReader{snapshots: []map[string]disk.IOCountersStat{first, first, second, second}},
+		img:          blankFrame(),
+		timeNow:      func() time.Time { return now },
+	}
+	comps := []Component{{Type: "diskio", X: 5, Y: 12, Device: "sda"}, {Type: "diskio", X: 5, Y: 40, Device: "sda"}}
+	for _, comp := range comps {
+		if _, _, ok, err := dm.sampleDiskIO(dm.scrollKey(comp), "sda"); err != nil || ok {
+			t.Fatalf("Expected no rate on a component's first sample, got ok=%v err=%v", ok, err)
+		}
+	}
+
+	now = start.Add(2 * time.Second)
+	for i, comp := range comps {
+		read, _, ok, err := dm.sampleDiskIO(dm.scrollKey(comp), "sda")
+		if err != nil || !ok {
+			t.Fatalf("Component %d: expected a rate, got ok=%v err=%v", i, ok, err)
+		}
+		if read != 1024 {
+			t.Errorf("Component %d: expected a read rate of 1KB/s, got %v", i, read)
+		}
+	}
+}
+
+// MockUptimeReader implements UptimeReader for testing
+type MockUptimeReader struct {
+	seconds uint64
+}
+
+func (m *MockUptimeReader) Uptime() (uint64, error) {
+	return m.seconds, nil
+}
+
+// TestFormatUptime tests uptime formatting at unit boundaries
+func TestFormatUptime(t *testing.T) {
+	tests := []struct {
+		duration time.Duration
+		style    string
+		want     string
+	}{
+		{59 * time.Second, "", "up 59s"},
+		{60 * time.Second, "", "up 1m"},
+		{24 * time.Hour, "", "up 1d"},
+		{25 * time.Hour, "", "up 1d 1h"},
+		{3*24*time.Hour + 4*time.Hour + 12*time.Minute, "", "up 3d 4h 12m"},
+		{59 * time.Second, "compact", "59s"},
+		{25 * time.Hour, "compact", "1d1h"},
+		{3*24*time.Hour + 4*time.Hour + 12*time.Minute, "compact", "3d4h"},
+		{59 * time.Second, "verbose", "up 59 seconds"},
+		{60 * time.Second, "verbose", "up 1 minute"},
+		{24 * time.Hour, "verbose", "up 1 day"},
+		{3*24*time.Hour + 4*time.Hour, "verbose", "up 3 days, 4 hours"},
+	}
+
+	for _, tt := range tests {
+		if got := formatUptime(tt.duration, tt.style); got != tt.want {
+			t.Errorf("formatUptime(%v, %q) = %q, want %q", tt.duration, tt.style, got, tt.want)
+		}
+	}
+}
+
+// TestUptimeComponent tests that the uptime component honors its label
+func TestUptimeComponent(t *testing.T) {
+	dm := &DisplayManager{
+		uptimeReader: &MockUptimeReader{seconds: 45},
+		img:          blankFrame(),
+	}
+	comp := Component{Type: "uptime", X: 5, Y: 12, Label: "Sys"}
+	if err := dm.renderComponent(comp); err != nil {
+		t.Fatalf("Failed to render component: %v", err)
+	}
+	if want := labelImage(5, 12, "Sys: up 45s"); !bytes.Equal(dm.img.Pix, want.Pix) {
+		t.Error("Rendered image does not match \"Sys: up 45s\"")
+	}
+}
+
+// TestDiskComponentMountpoint tests the disk component with an explicit mountpoint
+func TestDiskComponentMountpoint(t *testing.T) {
+	t.Run("Invalid mountpoint", func(t *testing.T) {
+		dm := &DisplayManager{
+			metricsSource: &MockMetricsProvider{},
+			img:           blankFrame(),
+		}
+		comp := Component{Type: "disk", X: 5, Y: 12, Mountpoint: "/does/not/exist", ShowBar: true, BarWidth: 100}
+		if err := dm.renderComponent(comp); err != nil {
+			t.Fatalf("Expected invalid mountpoint to render, got error: %v", err)
+		}
+		if want := labelImage(5, 12, "/does/not/exist: N/A"); !bytes.Equal(dm.img.Pix, want.Pix) {
+			t.Error("Expected N/A to be rendered for an invalid mountpoint")
+		}
+	})
+
+	t.Run("Explicit label", func(t *testing.T) {
+		dm := &DisplayManager{
+			metricsSource: &MockMetricsProvider{},
+			img:           blankFrame(),
+		}
+		comp := Component{Type: "disk", X: 5, Y: 12, Label: "Data", Mountpoint: "/does/not/exist"}
+		if err := dm.renderComponent(comp); err != nil {
+			t.Fatalf("Failed to render component: %v", err)
+		}
+		if want := labelImage(5, 12, "Data: N/A"); !bytes.Equal(dm.img.Pix, want.Pix) {
+			t.Error("Expected the explicit label to be used")
+		}
+	})
+}
+
+// TestRunShutdown tests that cancelling Run blanks and halts the display
+func TestRunShutdown(t *testing.T) {
+	mockDisplay := NewMockDisplay(t)
+	dm := &DisplayManager{
+		dev:            mockDisplay,
+		networkChecker: &MockNetworkChecker{ipAddress: "192.168.1.100"},
+		img:            blankFrame(),
+		timeNow:        time.Now,
+		config: Config{
+			ScreenDuration: 5,
+			Screens: []Screen{
+				{
+					Name:       "Test Screen",
+					Components: []Component{{Type: "ip", X: 5, Y: 20, Label: "IP"}},
+				},
+			},
+		},
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if err := dm.Run(ctx); err != nil {
+		t.Fatalf("Expected clean shutdown, got: %v", err)
+	}
+
+	if !mockDisplay.halted {
+		t.Error("Expected Halt to be called on shutdown")
+	}
+	if mockDisplay.lastImage == nil {
+		t.Fatal("Expected a blank frame to be drawn on shutdown")
+	}
+	if !bytes.Equal(mockDisplay.lastImage.Pix, blankFrame().Pix) {
+		t.Fatal("Expected the final frame to be blank")
+	}
+}
+
+// TestReloadConfig tests that config file changes are picked up and bad edits are ignored
+func TestReloadConfig(t *testing.T) {
+	writeConfig := func(path, contents string, modTime time.Time) {
+		t.Helper()
+		if err := os.WriteFile(path, []byte(contents), 0644); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.Chtimes(path, modTime, modTime); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	modTime := time.Date(2024, 1, 1, 12, 0, 0, 0, time.Local)
+	writeConfig(path, `
+screen_duration: 5
+screens:
+  - name: One
+    components: [{type: time}]
+  - name: Two
+    components: [{type: time}]
+`, modTime)
+
+	config, err := loadConfig(path)
+	if err != nil {
+		t.Fatalf("Failed to load config: %v", err)
+	}
+	dm := &DisplayManager{
+		config:        config,
+		configPath:    path,
+		configModTime: modTime,
+		currentScreen: 1,
+	}
+
+	// Unchanged file is not reloaded
+	if reloaded, err := dm.reloadConfig(); err != nil || reloaded {
+		t.Fatalf("Expected no reload for unchanged file, got reloaded=%v err=%v", reloaded, err)
+	}
+
+	// Changed duration with the same number of screens keeps the current screen
+	writeConfig(path, `
+screen_duration: 10
+screens:
+  - name: One
+    components: [{type: time}]
+  - name: Two
+    components: [{type: time}]
+`, modTime.Add(time.Minute))
+	if reloaded, err := dm.reloadConfig(); err != nil || !reloaded {
+		t.Fatalf("Expected reload, got reloaded=%v err=%v", reloaded, err)
+	}
+	if dm.config.ScreenDuration != 10 {
+		t.Errorf("Expected screen duration 10, got %d", dm.config.ScreenDuration)
+	}
+	if dm.currentScreen != 1 {
+		t.Errorf("Expected current screen to be kept, got %d", dm.currentScreen)
+	}
+
+	// A parse error keeps the previous config
+	writeConfig(path, "screen_duration: [not a number", modTime.Add(2*time.Minute))
+	if _, err := dm.reloadConfig(); err == nil {
+		t.Error("Expected an error for an unparseable config")
+	}
+	if dm.config.ScreenDuration != 10 {
+		t.Errorf("Expected previous config to be kept, got duration %d", dm.config.ScreenDuration)
+	}
+
+	// Changing the number of screens resets rotation
+	writeConfig(path, `
+screen_duration: 3
+screens:
+  - name: Only
+    components: [{type: time}]
+`, modTime.Add(3*time.Minute))
+	if reloaded, err := dm.reloadConfig(); err != nil || !reloaded {
+		t.Fatalf("Expected reload, got reloaded=%v err=%v", reloaded, err)
+	}
+	if dm.currentScreen != 0 {
+		t.Errorf("Expected current screen to reset to 0, got %d", dm.currentScreen)
+	}
+}
+
+// TestValidateConfig tests that each kind of invalid config is reported
+func TestValidateConfig(t *testing.T) {
+	valid := func() Config {
+		return Config{
+			ScreenDuration: 5,
+			Screens: []Screen{
+				{
+					Name:       "Test Screen",
+					Components: []Component{{Type: "cpu", X: 5, Y: 20, Label: "CPU", ShowBar: true, BarWidth: 88}},
+				},
+			},
+		}
+	}
+
+	tests := []struct {
+		name    string
+		modify  func(c *Config)
+		wantErr []string
+	}{
+		{
+			name:   "Valid config",
+			modify: func(c *Config) {},
+		},
+		{
+			name:    "Zero screen duration",
+			modify:  func(c *Config) { c.ScreenDuration = 0 },
+			wantErr: []string{"screen_duration must be greater than 0"},
+		},
+		{
+			name:    "Negative transition minutes",
+			modify:  func(c *Config) { c.TransitionMinutes = -10 },
+			wantErr: []string{"transition_minutes must not be negative"},
+		},
+		{
+			name:    "Negative anti-burnin minutes",
+			modify:  func(c *Config) { c.AntiBurnin, c.AntiBurninMinutes = true, -3 },
+			wantErr: []string{"anti_burnin_minutes must not be negative"},
+		},
+		{
+			name:    "Exec without a command",
+			modify:  func(c *Config) { c.Screens[0].Components[0] = Component{Type: "exec", X: 5, Y: 20, Timeout: -1} },
+			wantErr: []string{"command must be set", "timeout must not be negative, got -1"},
+		},
+		{
+			name:    "Disk I/O without a device",
+			modify:  func(c *Config) { c.Screens[0].Components[0] = Component{Type: "diskio", X: 5, Y: 20} },
+			wantErr: []string{"device must be set"},
+		},
+		{
+			name:    "Total storage without mountpoints",
+			modify:  func(c *Config) { c.Screens[0].Components[0] = Component{Type: "diskall", X: 5, Y: 20} },
+			wantErr: []string{"mountpoints must be set"},
+		},
+		{
+			name:    "Absolute values on cpu",
+			modify:  func(c *Config) { c.Screens[0].Components[0] = Component{Type: "cpu", X: 5, Y: 20, ShowAbsolute: true} },
+			wantErr: []string{"show_absolute only applies to memory and disk"},
+		},
+		{
+			name: "Fan bar without max_rpm",
+			modify: func(c *Config) {
+				c.Screens[0].Components[0] = Component{Type: "fan", X: 5, Y: 20, ShowBar: true}
+			},
+			wantErr: []string{"show_bar needs max_rpm to scale the bar"},
+		},
+		{
+			name:    "Negative update interval",
+			modify:  func(c *Config) { c.UpdateInterval = -1 },
+			wantErr: []string{"update_interval must not be negative, got -1"},
+		},
+		{
+			name:    "Negative splash duration",
+			modify:  func(c *Config) { c.SplashDuration = -1 },
+			wantErr: []string{"splash_duration must not be negative, got -1"},
+		},
+		{
+			name: "Contrast out of range",
+			modify: func(c *Config) {
+				day, night := 256, -1
+				c.DayContrast, c.NightContrast = &day, &night
+			},
+			wantErr: []string{"day_contrast must be between 0 and 255, got 256", "night_contrast must be between 0 and 255, got -1"},
+		},
+		{
+			name:    "HTTP port out of range",
+			modify:  func(c *Config) { c.HTTPPort = 70000 },
+			wantErr: []string{"http_port must be between 0 and 65535"},
+		},
+		{
+			name:    "Unknown transition",
+			modify:  func(c *Config) { c.Transition = "wipe" },
+			wantErr: []string{`transition must be none, slide_left or fade, got "wipe"`},
+		},
+		{
+			name: "Alert on a non-percentage component",
+			modify: func(c *Config) {
+				threshold := 50.0
+				c.Screens[0].Components[0] = Component{Type: "uptime", X: 5, Y: 20, AlertAbove: &threshold}
+			},
+			wantErr: []string{"alert_above and alert_below only apply to percentage components"},
+		},
+		{
+			name: "Unknown hostname field",
+			modify: func(c *Config) {
+				c.Screens[0].Components[0] = Component{Type: "hostname", X: 5, Y: 20, Fields: []string{"hostname", "arch"}}
+			},
+			wantErr: []string{`unknown field "arch", must be hostname, os, kernel or platform`},
+		},
+		{
+			name:    "Unknown log level",
+			modify:  func(c *Config) { c.LogLevel = "verbose" },
+			wantErr: []string{`log_level must be debug, info, warn or error, got "verbose"`},
+		},
+		{
+			name:    "No screens",
+			modify:  func(c *Config) { c.Screens = nil },
+			wantErr: []string{"at least one screen"},
+		},
+		{
+			name:    "Unknown background",
+			modify:  func(c *Config) { c.Screens[0].Background = "grey" },
+			wantErr: []string{`background must be black or white, got "grey"`},
+		},
+		{
+			name:    "Screen without components",
+			modify:  func(c *Config) { c.Screens[0].Components = nil },
+			wantErr: []string{"must have at least one component"},
+		},
+		{
+			name:    "Unknown component type",
+			modify:  func(c *Config) { c.Screens[0].Components[0].Type = "bogus" },
+			wantErr: []string{`unknown type "bogus"`},
+		},
+		{
+			name:    "Empty text",
+			modify:  func(c *Config) { c.Screens[0].Components[0] = Component{Type: "text", X: 5, Y: 20} },
+			wantErr: []string{"text must not be empty"},
+		},
+		{
+			name:    "Line past the edge",
+			modify:  func(c *Config) { c.Screens[0].Components[0] = Component{Type: "line", X: 100, Y: 20, Length: 40} },
+			wantErr: []string{"line from 100,20 runs past the 128x64 display"},
+		},
+		{
+			name: "Bad line shape",
+			modify: func(c *Config) {
+				c.Screens[0].Components[0] = Component{Type: "line", Orientation: "diagonal", Thickness: -1}
+			},
+			wantErr: []string{`orientation must be horizontal or vertical, got "diagonal"`, "length must be greater than 0", "thickness must not be negative"},
+		},
+		{
+			name:    "Unknown icon",
+			modify:  func(c *Config) { c.Screens[0].Components[0].Icon = "rocket" },
+			wantErr: []string{`unknown icon "rocket"`},
+		},
+		{
+			name: "Signal bars with icon",
+			modify: func(c *Config) {
+				c.Screens[0].Components[0] = Component{Type: "wifi", X: 5, Y: 20, Icon: "wifi", SignalBars: true}
+			},
+			wantErr: []string{"signal_bars and icon both draw before the text"},
+		},
+		{
+			name:    "X off screen",
+			modify:  func(c *Config) { c.Screens[0].Components[0].X = width + 1 },
+			wantErr: []string{"x 129 is outside"},
+		},
+		{
+			name:    "Negative Y",
+			modify:  func(c *Config) { c.Screens[0].Components[0].Y = -1 },
+			wantErr: []string{"y -1 is outside"},
+		},
+		{
+			name:    "Negative bar width",
+			modify:  func(c *Config) { c.Screens[0].Components[0].BarWidth = -5 },
+			wantErr: []string{"bar_width must not be negative"},
+		},
+		{
+			name: "Ticks on a vertical bar",
+			modify: func(c *Config) {
+				c.Screens[0].Components[0].Ticks = 4
+				c.Screens[0].Components[0].Orientation = "vertical"
+			},
+			wantErr: []string{"ticks only apply to horizontal bars"},
+		},
+		{
+			name: "Reverse on a vertical bar",
+			modify: func(c *Config) {
+				c.Screens[0].Components[0].Reverse = true
+				c.Screens[0].Components[0].Orientation = "vertical"
+			},
+			wantErr: []string{"reverse only applies to horizontal bars"},
+		},
+		{
+			name: "128x32 display",
+			modify: func(c *Config) {
+				c.DisplayWidth = 128
+				c.DisplayHeight = 32
+			},
+		},
+		{
+			name: "Unsupported display size",
+			modify: func(c *Config) {
+				c.DisplayWidth = 100
+				c.DisplayHeight = 50
+			},
+			wantErr: []string{"display size 100x50 is not a supported SSD1306 size"},
+		},
+		{
+			name: "Y below a 128x32 display",
+			modify: func(c *Config) {
+				c.DisplayHeight = 32
+				c.Screens[0].Components[0].Y = 40
+			},
+			wantErr: []string{"y 40 is outside 0..32"},
+		},
+		{
+			name: "Portrait layout",
+			modify: func(c *Config) {
+				c.DisplayRotation = 90
+				c.Screens[0].Components[0].Y = 120
+			},
+		},
+		{
+			name: "X past a portrait layout",
+			modify: func(c *Config) {
+				c.DisplayRotation = 270
+				c.Screens[0].Components[0].X = 100
+			},
+			wantErr: []string{"x 100 is outside 0..64"},
+		},
+		{
+			name:    "Unsupported display rotation",
+			modify:  func(c *Config) { c.DisplayRotation = 45 },
+			wantErr: []string{"display_rotation must be 0, 90, 180 or 270, got 45"},
+		},
+		{
+			name: "rotate_180 with a portrait rotation",
+			modify: func(c *Config) {
+				c.Rotate180 = true
+				c.DisplayRotation = 90
+			},
+			wantErr: []string{"rotate_180 conflicts with display_rotation 90"},
+		},
+		{
+			name:   "SPI connection",
+			modify: func(c *Config) { c.Connection = "spi" },
+		},
+		{
+			name:    "Unknown connection",
+			modify:  func(c *Config) { c.Connection = "uart" },
+			wantErr: []string{`connection must be i2c or spi, got "uart"`},
+		},
+		{
+			name: "Weather",
+			modify: func(c *Config) {
+				c.Weather = WeatherConfig{APIKey: "secret", Location: "London,uk"}
+				c.Screens[0].Components[0] = Component{Type: "weather", X: 5, Y: 20}
+			},
+		},
+		{
+			name:    "Weather without settings",
+			modify:  func(c *Config) { c.Screens[0].Components[0] = Component{Type: "weather", X: 5, Y: 20} },
+			wantErr: []string{"weather location must be set", "weather api_key must be set"},
+		},
+		{
+			name: "Weather with a bad url",
+			modify: func(c *Config) {
+				c.Weather = WeatherConfig{URL: "api.example.com/weather", Location: "London,uk"}
+				c.Screens[0].Components[0] = Component{Type: "weather", X: 5, Y: 20}
+			},
+			wantErr: []string{`weather url must be an http or https URL, got "api.example.com/weather"`},
+		},
+		{
+			name:    "Unknown temperature unit",
+			modify:  func(c *Config) { c.TemperatureUnit = "K" },
+			wantErr: []string{`temperature_unit must be C or F, got "K"`},
+		},
+		{
+			name: "Multiple problems are aggregated",
+			modify: func(c *Config) {
+				c.ScreenDuration = 0
+				c.Screens[0].Components[0].Type = "bogus"
+				c.Screens[0].Components[0].X = -3
+			},
+			wantErr: []string{"screen_duration", `unknown type "bogus"`, "x -3 is outside"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config := valid()
+			tt.modify(&config)
+			err := validateConfig(config)
+			if len(tt.wantErr) == 0 {
+				if err != nil {
+					t.Errorf("Expected no error, got: %v", err)
+				}
+				return
+			}
+			if err == nil {
+				t.Fatal("Expected an error, got nil")
+			}
+			for _, want := range tt.wantErr {
+				if !strings.Contains(err.Error(), want) {
+					t.Errorf("Expected error to contain %q, got: %v", want, err)
+				}
+			}
+		})
+	}
+}
+
+// leftmostLitX returns the smallest X coordinate with a lit pixel, or -1
+func leftmostLitX(img *image.RGBA) int {
+	b := img.Bounds()
+	for x := b.Min.X; x < b.Max.X; x++ {
+		for y := b.Min.Y; y < b.Max.Y; y++ {
+			if img.RGBAAt(x, y).R != 0 {
+				return x
+			}
+		}
+	}
+	return -1
+}
+
+// TestTextAlignment tests that alignment shifts the rendered text relative to X
+func TestTextAlignment(t *testing.T) {
+	text := "IP: 10.0.0.1"
+	textWidth := font.MeasureString(basicfont.Face7x13, text).Ceil()
+
+	leftmost := make(map[string]int)
+	for _, align := range []string{"left", "center", "right"} {
+		dm := &DisplayManager{
+			networkChecker: &MockNetworkChecker{ipAddress: "10.0.0.1"},
+			img:            blankFrame(),
+		}
+		comp := Component{Type: "ip", X: 100, Y: 20, Label: "IP", Align: align}
+		if err := dm.renderComponent(comp); err != nil {
+			t.Fatalf("Failed to render component: %v", err)
+		}
+
+		want := labelImage(render.AlignX(basicfont.Face7x13, comp.X, text, align), 20, text)
+		if !bytes.Equal(dm.img.Pix, want.Pix) {
+			t.Errorf("%s: rendered image does not match expected placement", align)
+		}
+		leftmost[align] = leftmostLitX(dm.img)
+	}
+
+	if render.AlignX(basicfont.Face7x13, 100, text, "right") != 100-textWidth {
+		t.Errorf("Expected right-aligned text to end at X")
+	}
+	if render.AlignX(basicfont.Face7x13, 100, text, "center") != 100-textWidth/2 {
+		t.Errorf("Expected centered text to be balanced around X")
+	}
+	if leftmost["left"] == leftmost["center"] || leftmost["center"] == leftmost["right"] || leftmost["left"] == leftmost["right"] {
+		t.Errorf("Expected leftmost lit pixel to differ per alignment, got %v", leftmost)
+	}
+}
+
+// TestLoadFontFace tests loading a TTF font and the startup errors for bad font files
+func TestLoadFontFace(t *testing.T) {
+	dir := t.TempDir()
+	fontPath := filepath.Join(dir, "goregular.ttf")
+	if err := os.WriteFile(fontPath, goregular.TTF, 0644); err != nil {
+		t.Fatal(err)
+	}
+	corruptPath := filepath.Join(dir, "corrupt.ttf")
+	if err := os.WriteFile(corruptPath, []byte("not a font"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	t.Run("Default font", func(t *testing.T) {
+		face, err := loadFontFace(Config{})
+		if err != nil {
+			t.Fatalf("Expected no error, got: %v", err)
+		}
+		if face != basicfont.Face7x13 {
+			t.Error("Expected basicfont when no font_path is set")
+		}
+	})
+
+	t.Run("TTF font", func(t *testing.T) {
+		face, err := loadFontFace(Config{FontPath: fontPath, FontSize: 20})
+		if err != nil {
+			t.Fatalf("Expected no error, got: %v", err)
+		}
+		if got := face.Metrics().Height.Ceil(); got < 20 {
+			t.Errorf("Expected line height of at least 20px for a 20pt font, got %d", got)
+		}
+
+		dm := &DisplayManager{
+			networkChecker: &MockNetworkChecker{ipAddress: "10.0.0.1"},
+			img:            blankFrame(),
+			face:           face,
+		}
+		if err := dm.renderComponent(Component{Type: "ip", X: 5, Y: 30, Label: "IP"}); err != nil {
+			t.Fatalf("Failed to render component: %v", err)
+		}
+		if bytes.Equal(dm.img.Pix, labelImage(5, 30, "IP: 10.0.0.1").Pix) {
+			t.Error("Expected the TTF face to be used instead of basicfont")
+		}
+	})
+
+	t.Run("Missing font file", func(t *testing.T) {
+		_, err := loadFontFace(Config{FontPath: filepath.Join(dir, "missing.ttf")})
+		if err == nil || !strings.Contains(err.Error(), "error reading font file") {
+			t.Errorf("Expected a read error, got: %v", err)
+		}
+	})
+
+	t.Run("Corrupt font file", func(t *testing.T) {
+		_, err := loadFontFace(Config{FontPath: corruptPath})
+		if err == nil || !strings.Contains(err.Error(), "error parsing font file") {
+			t.Errorf("Expected a parse error, got: %v", err)
+		}
+	})
+}
+
+// TestMemGraphComponent tests that memgraph draws the memory history as
+// filled columns over a baseline, keeping the history between renders
+func TestMemGraphComponent(t *testing.T) {
+	const x, y, graphWidth, graphHeight = 10, 20, 6, 8
+
+	provider := &MockMetricsProvider{}
+	dm := &DisplayManager{metricsSource: provider}
+	comp := Component{Type: "memgraph", X: x, Y: y, BarWidth: graphWidth, Height: graphHeight}
+	for _, used := range []float64{25, 50, 0, 100} {
+		provider.memory = used
+		dm.img = blankFrame()
+		if err := dm.renderComponent(comp); err != nil {
+			t.Fatalf("Failed to render component: %v", err)
+		}
+	}
+
+	// Two empty columns before the first sample still show the baseline
+	wantHeights := []int{1, 1, 2, 4, 1, 8}
+	for col, want := range wantHeights {
+		for row := 0; row < graphHeight; row++ {
+			lit := dm.img.RGBAAt(x+col, y+row).R != 0
+			if below := row >= graphHeight-want; lit != below {
+				t.Errorf("Column %d row %d: lit = %v, want %v", col, row, lit, below)
+			}
+		}
+	}
+	if dm.img.RGBAAt(x+graphWidth, y+graphHeight-1).R != 0 {
+		t.Error("Expected the baseline to end at the graph's width")
+	}
+}
+
+// MockTimer implements screenTimer, firing only when the test says so and
+// reporting every reschedule on resets
+type MockTimer struct {
+	c      chan time.Time
+	resets chan time.Duration
+}
+
+func (m *MockTimer) Chan() <-chan time.Time {
+	return m.c
+}
+
+func (m *MockTimer) Reset(d time.Duration) {
+	m.resets <- d
+}
+
+func (m *MockTimer) Stop() bool {
+	return true
+}
+
+// TestPerScreenDuration tests that a screen with its own duration stays up for that long
+func TestPerScreenDuration(t *testing.T) {
+	timer := &MockTimer{c: make(chan time.Time), resets: make(chan time.Duration)}
+	var initial time.Duration
+	dm := &DisplayManager{
+		dev:            NewMockDisplay(t),
+		networkChecker: &MockNetworkChecker{ipAddress: "192.168.1.100"},
+		img:            blankFrame(),
+		timeNow:        time.Now,
+		newTimer: func(d time.Duration) screenTimer {
+			initial = d
+			return timer
+		},
+		config: Config{
+			ScreenDuration: 3,
+			Screens: []Screen{
+				{Name: "Summary", Duration: 10, Components: []Component{{Type: "ip", X: 5, Y: 20, Label: "IP"}}},
+				{Name: "Detail", Components: []Component{{Type: "ip", X: 5, Y: 20, Label: "IP"}}},
+			},
+		},
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error)
+	go func() {
+		done <- dm.Run(ctx)
+	}()
+
+	// Each fire rotates the screen and reschedules for the new screen's duration
+	want := []struct {
+		screen   int
+		duration time.Duration
+	}{
+		{1, 3 * time.Second},
+		{0, 10 * time.Second},
+		{1, 3 * time.Second},
+	}
+	for i, w := range want {
+		timer.c <- time.Now()
+		got := <-timer.resets
+		if dm.currentScreen != w.screen {
+			t.Errorf("Rotation %d: expected screen %d, got %d", i, w.screen, dm.currentScreen)
+		}
+		if got != w.duration {
+			t.Errorf("Rotation %d: expected duration %v, got %v", i, w.duration, got)
+		}
+	}
+
+	cancel()
+	if err := <-done; err != nil {
+		t.Fatalf("Run returned error: %v", err)
+	}
+	if initial != 10*time.Second {
+		t.Errorf("Expected first screen to use its own duration of 10s, got %v", initial)
+	}
+}
+
+// MockSwapReader implements SwapReader for testing
+type MockSwapReader struct {
+	swap *mem.SwapMemoryStat
+}
+
+func (m *MockSwapReader) SwapMemory() (*mem.SwapMemoryStat, error) {
+	return m.swap, nil
+}
+
+// TestSwapComponent tests the swap component with and without swap configured
+func TestSwapComponent(t *testing.T) {
+	tests := []struct {
+		name      string
+		swap      *mem.SwapMemoryStat
+		wantLabel string
+		wantBar   float64
+	}{
+		{
+			name:      "Swap off",
+			swap:      &mem.SwapMemoryStat{},
+			wantLabel: "Swap: off",
+			wantBar:   -1,
+		},
+		{
+			name:      "Swap in use",
+			swap:      &mem.SwapMemoryStat{Total: 1000, Used: 250, UsedPercent: 25},
+			wantLabel: "Swap: 25.0%",
+			wantBar:   0.25,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dm := &DisplayManager{
+				swapReader: &MockSwapReader{swap: tt.swap},
+				img:        blankFrame(),
+			}
+			comp := Component{Type: "swap", X: 5, Y: 12, Label: "Swap", ShowBar: true, BarWidth: 100}
+			if err := dm.renderComponent(comp); err != nil {
+				t.Fatalf("Failed to render component: %v", err)
+			}
+
+			want := labelImage(5, 12, tt.wantLabel)
+			if tt.wantBar >= 0 {
+				render.DrawBar(want, 5, 17, 100, render.BarHeight, tt.wantBar, 1)
+			}
+			if !bytes.Equal(dm.img.Pix, want.Pix) {
+				t.Errorf("Rendered image does not match %q", tt.wantLabel)
+			}
+		})
+	}
+}
+
+// TestRender128x32 tests rendering a screen on a 128x32 panel
+func TestRender128x32(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	configYAML := []byte(`
+screen_duration: 5
+display_width: 128
+display_height: 32
+screens:
+  - name: Small
+    components:
+      - type: ip
+        x: 5
+        y: 12
+        label: IP
+`)
+	if err := os.WriteFile(path, configYAML, 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	mockDisplay := NewMockDisplay(t)
+	managers, err := newDisplayManagers(path, &MockNetworkChecker{ipAddress: "10.0.0.1"})
+	if err != nil {
+		t.Fatalf("Failed to create display manager: %v", err)
+	}
+	dm := managers[0]
+	dm.dev = mockDisplay
+
+	if err := dm.renderCurrentScreen(); err != nil {
+		t.Fatalf("Failed to render screen: %v", err)
+	}
+	if b := mockDisplay.lastImage.Bounds(); b.Dx() != 128 || b.Dy() != 32 {
+		t.Fatalf("Expected a 128x32 frame, got %v", b)
+	}
+
+	want := image.NewRGBA(image.Rect(0, 0, 128, 32))
+	render.Clear(want)
+	render.AddLabel(want, basicfont.Face7x13, 5, 12, "IP: 10.0.0.1")
+	if !bytes.Equal(mockDisplay.lastImage.Pix, want.Pix) {
+		t.Error("Rendered 128x32 frame does not match expected label")
+	}
+
+	// Rendering again clears the whole smaller buffer without overrunning it
+	if err := dm.renderCurrentScreen(); err != nil {
+		t.Fatalf("Failed to re-render screen: %v", err)
+	}
+}
+
+// MockTemperatureReader implements TemperatureReader for testing
+type MockTemperatureReader struct {
+	files   map[string]float64
+	sensors []pshost.TemperatureStat
+}
+
+func (m *MockTemperatureReader) FileTemperature(path string) (float64, error) {
+	temp, ok := m.files[path]
+	if !ok {
+		return 0, fmt.Errorf("failed to read temperature: no such file %s", path)
+	}
+	return temp, nil
+}
+
+func (m *MockTemperatureReader) SensorTemperature(key string) (float64, error) {
+	return findSensor(m.sensors, key)
+}
+
+// MockMetricsProvider implements MetricsProvider for testing
+type MockMetricsProvider struct {
+	cpu    float64
+	cores  []float64
+	memory float64
+	disks  map[string]float64         // used percent by mountpoint
+	usages map[string]*disk.UsageStat // full usage by mountpoint, checked before disks
+	vm     *mem.VirtualMemoryStat     // full memory stats, returned instead of memory when set
+	temps  TemperatureReader
+	err    error
+}
+
+func (m *MockMetricsProvider) PerCPUPercent() ([]float64, error) {
+	if m.err != nil {
+		return nil, m.err
+	}
+	return m.cores, nil
+}
+
+func (m *MockMetricsProvider) CPUPercent() (float64, error) {
+	if m.err != nil {
+		return 0, m.err
+	}
+	return m.cpu, nil
+}
+
+func (m *MockMetricsProvider) VirtualMemory() (*mem.VirtualMemoryStat, error) {
+	if m.err != nil {
+		return nil, m.err
+	}
+	if m.vm != nil {
+		return m.vm, nil
+	}
+	return &mem.VirtualMemoryStat{UsedPercent: m.memory}, nil
+}
+
+func (m *MockMetricsProvider) DiskUsage(path string) (*disk.UsageStat, error) {
+	if usage, ok := m.usages[path]; ok {
+		return usage, nil
+	}
+	percent, ok := m.disks[path]
+	if !ok {
+		return nil, fmt.Errorf("no such file or directory: %s", path)
+	}
+	return &disk.UsageStat{Path: path, UsedPercent: percent}, nil
+}
+
+func (m *MockMetricsProvider) Temperature(source, sensorKey string) (float64, error) {
+	return readTemperature(m.temps, source, sensorKey)
+}
+
+func (m *MockMetricsProvider) HottestTemperature(zones []string) (float64, string, error) {
+	return hottestZone(m.temps, zones)
+}
+
+// TestRealTemperatureReaderFile tests parsing a sysfs temperature file
+func TestRealTemperatureReaderFile(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "temp")
+	if err := os.WriteFile(path, []byte("48312\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	reader := &RealTemperatureReader{}
+	temp, err := reader.FileTemperature(path)
+	if err != nil {
+		t.Fatalf("Failed to read temperature: %v", err)
+	}
+	if temp != 48.312 {
+		t.Errorf("Expected 48.312, got %v", temp)
+	}
+
+	if _, err := reader.FileTemperature(filepath.Join(dir, "missing")); err == nil {
+		t.Error("Expected an error for a missing temperature file")
+	}
+}
+
+// TestFindSensor tests selecting a gopsutil sensor by key
+func TestFindSensor(t *testing.T) {
+	sensors := []pshost.TemperatureStat{
+		{SensorKey: "acpitz", Temperature: 27.8},
+		{SensorKey: "coretemp_package_id_0", Temperature: 52.0},
+	}
+
+	temp, err := findSensor(sensors, "coretemp_package_id_0")
+	if err != nil {
+		t.Fatalf("Failed to find sensor: %v", err)
+	}
+	if temp != 52.0 {
+		t.Errorf("Expected 52.0, got %v", temp)
+	}
+
+	if _, err := findSensor(sensors, "nvme_composite"); err == nil {
+		t.Error("Expected an error for a missing sensor")
+	}
+}
+
+// TestTemperatureComponentSources tests the temperature component's source selection
+func TestTemperatureComponentSources(t *testing.T) {
+	reader := &MockTemperatureReader{
+		files: map[string]float64{
+			tempFile:                                40.0,
+			"/sys/class/thermal/thermal_zone2/temp": 55.5,
+		},
+		sensors: []pshost.TemperatureStat{{SensorKey: "coretemp_package_id_0", Temperature: 61.0}},
+	}
+
+	tests := []struct {
+		name      string
+		comp      Component
+		wantLabel string
+		wantErr   bool
+	}{
+		{
+			name:      "Default zone",
+			comp:      Component{Type: "temperature", X: 5, Y: 12, Label: "Temp"},
+			wantLabel: "Temp: 40.0 C",
+		},
+		{
+			name:      "Source override",
+			comp:      Component{Type: "temperature", X: 5, Y: 12, Label: "Temp", Source: "/sys/class/thermal/thermal_zone2/temp"},
+			wantLabel: "Temp: 55.5 C",
+		},
+		{
+			name:      "Sensor key",
+			comp:      Component{Type: "temperature", X: 5, Y: 12, Label: "Temp", SensorKey: "coretemp_package_id_0"},
+			wantLabel: "Temp: 61.0 C",
+		},
+		{
+			name:    "Missing sensor",
+			comp:    Component{Type: "temperature", X: 5, Y: 12, Label: "Temp", SensorKey: "missing"},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dm := &DisplayManager{
+				metricsSource: &MockMetricsProvider{temps: reader},
+				img:           blankFrame(),
+			}
+			err := dm.renderComponent(tt.comp)
+			if tt.wantErr {
+				if err == nil {
+					t.Error("Expected an error, got nil")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Failed to render component: %v", err)
+			}
+			if want := labelImage(5, 12, tt.wantLabel); !bytes.Equal(dm.img.Pix, want.Pix) {
+				t.Errorf("Rendered image does not match %q", tt.wantLabel)
+			}
+		})
+	}
+}
+
+// TestShowAbsolute tests that memory and disk show used/total bytes in place
+// of the percentage when show_absolute is set
+func TestShowAbsolute(t *testing.T) {
+	metrics := &MockMetricsProvider{
+		vm: &mem.VirtualMemoryStat{Used: 3435973837, Total: 8375186227, UsedPercent: 41},
+		usages: map[string]*disk.UsageStat{
+			"/": {Path: "/", Used: 210 << 30, Total: 512 << 30, UsedPercent: 41},
+		},
+	}
+
+	tests := []struct {
+		name      string
+		comp      Component
+		wantLabel string
+	}{
+		{name: "Memory", comp: Component{Type: "memory", X: 5, Y: 20, Label: "MEM", ShowAbsolute: true}, wantLabel: "MEM: 3.2/7.8 GB"},
+		{name: "Memory percent", comp: Component{Type: "memory", X: 5, Y: 20, Label: "MEM"}, wantLabel: "MEM: 41.0%"},
+		{name: "Disk", comp: Component{Type: "disk", X: 5, Y: 20, ShowAbsolute: true}, wantLabel: "/: 210/512 GB"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dm := &DisplayManager{metricsSource: metrics, img: blankFrame()}
+			if err := dm.renderComponent(tt.comp); err != nil {
+				t.Fatalf("Failed to render component: %v", err)
+			}
+			if want := labelImage(5, 20, tt.wantLabel); !bytes.Equal(dm.img.Pix, want.Pix) {
+				t.Errorf("Rendered image does not match %q", tt.wantLabel)
+			}
+		})
+	}
+}
+
+// TestTemperatureComponentFahrenheit tests rendering with temperature_unit F
+func TestTemperatureComponentFahrenheit(t *testing.T) {
+	dm := &DisplayManager{
+		config:        Config{TemperatureUnit: "F"},
+		metricsSource: &MockMetricsProvider{temps: &MockTemperatureReader{files: map[string]float64{tempFile: 22.3}}},
+		img:           blankFrame(),
+	}
+	comp := Component{Type: "temperature", X: 5, Y: 12, Label: "Temp", ShowBar: true, BarWidth: 100}
+	if err := dm.renderComponent(comp); err != nil {
+		t.Fatalf("Failed to render component: %v", err)
+	}
+
+	want := labelImage(5, 12, "Temp: 72.1 F")
+	render.DrawBar(want, 5, 17, 100, render.BarHeight, 0.223, 1)
+	if !bytes.Equal(dm.img.Pix, want.Pix) {
+		t.Error("Rendered image does not match \"Temp: 72.1 F\" with a Celsius-scaled bar")
+	}
+}
+
+// TestMetricComponents tests the cpu, memory, cpugraph and disk components
+// against mocked readings
+func TestMetricComponents(t *testing.T) {
+	provider := &MockMetricsProvider{
+		cpu:    37.5,
+		memory: 62.25,
+		disks:  map[string]float64{"/": 48.0, "/data": 91.5},
+	}
+	graph := func(img *image.RGBA) {
+		h := render.NewHistory(20)
+		h.Push(37.5)
+		render.DrawGraph(img, 5, 17, 20, render.DefaultGraphHeight, h.Values())
+	}
+
+	tests := []struct {
+		name      string
+		comp      Component
+		wantLabel string
+		wantBar   float64 // -1 for no bar
+		wantGraph bool
+	}{
+		{
+			name:      "CPU",
+			comp:      Component{Type: "cpu", X: 5, Y: 12, Label: "CPU", ShowBar: true, BarWidth: 100},
+			wantLabel: "CPU: 37.5%",
+			wantBar:   0.375,
+		},
+		{
+			name:      "Memory",
+			comp:      Component{Type: "memory", X: 5, Y: 12, Label: "Mem", ShowBar: true, BarWidth: 100},
+			wantLabel: "Mem: 62.2%",
+			wantBar:   0.6225,
+		},
+		{
+			name:      "Memory without bar",
+			comp:      Component{Type: "memory", X: 5, Y: 12, Label: "Mem"},
+			wantLabel: "Mem: 62.2%",
+			wantBar:   -1,
+		},
+		{
+			name:      "CPU graph",
+			comp:      Component{Type: "cpugraph", X: 5, Y: 12, Label: "CPU", BarWidth: 20},
+			wantLabel: "CPU: 37.5%",
+			wantBar:   -1,
+			wantGraph: true,
+		},
+		{
+			name:      "Root disk",
+			comp:      Component{Type: "disk", X: 5, Y: 12, ShowBar: true, BarWidth: 100},
+			wantLabel: "/: 48.0%",
+			wantBar:   0.48,
+		},
+		{
+			name:      "Labelled mountpoint",
+			comp:      Component{Type: "disk", X: 5, Y: 12, Label: "Data", Mountpoint: "/data"},
+			wantLabel: "Data: 91.5%",
+			wantBar:   -1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dm := &DisplayManager{
+				metricsSource: provider,
+				img:           blankFrame(),
+			}
+			if err := dm.renderComponent(tt.comp); err != nil {
+				t.Fatalf("Failed to render component: %v", err)
+			}
+
+			want := labelImage(5, 12, tt.wantLabel)
+			if tt.wantBar >= 0 {
+				render.DrawBar(want, 5, 17, 100, render.BarHeight, tt.wantBar, 1)
+			}
+			if tt.wantGraph {
+				graph(want)
+			}
+			if !bytes.Equal(dm.img.Pix, want.Pix) {
+				t.Errorf("Rendered image does not match %q", tt.wantLabel)
+			}
+		})
+	}
+}
+
+// TestCPUCoresComponent tests that one vertical bar is drawn per reported
+// core, spaced by the component's bar width and spacing
+func TestCPUCoresComponent(t *testing.T) {
+	cores := []float64{10, 50, 75, 100}
+	tests := []struct {
+		name      string
+		comp      Component
+		wantX     []int
+		barsY     int
+		barWidth  int
+		barHeight int
+		label     string
+	}{
+		{
+			name:      "Defaults",
+			comp:      Component{Type: "cpucores", X: 5, Y: 20},
+			wantX:     []int{5, 14, 23, 32},
+			barsY:     20,
+			barWidth:  render.BarHeight,
+			barHeight: render.DefaultGraphHeight,
+		},
+		{
+			name:      "Width, spacing and label",
+			comp:      Component{Type: "cpucores", X: 10, Y: 12, Label: "Cores", BarWidth: 5, Spacing: 4, Height: 20},
+			wantX:     []int{10, 19, 28, 37},
+			barsY:     17,
+			barWidth:  5,
+			barHeight: 20,
+			label:     "Cores",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dm := &DisplayManager{
+				metricsSource: &MockMetricsProvider{cores: cores},
+				img:           blankFrame(),
+			}
+			if err := dm.renderComponent(tt.comp); err != nil {
+				t.Fatalf("Failed to render component: %v", err)
+			}
+
+			want := blankFrame()
+			if tt.label != "" {
+				want = labelImage(tt.comp.X, tt.comp.Y, tt.label)
+			}
+			for i, x := range tt.wantX {
+				render.DrawVBar(want, x, tt.barsY, tt.barWidth, tt.barHeight, cores[i]/100, 1)
+			}
+			if !bytes.Equal(dm.img.Pix, want.Pix) {
+				t.Errorf("Expected bars at x %v", tt.wantX)
+			}
+
+			// Each bar's bottom border starts at its X and nothing follows the last
+			bottom := tt.barsY + tt.barHeight - 1
+			for _, x := range tt.wantX {
+				if dm.img.RGBAAt(x, bottom).R == 0 {
+					t.Errorf("Expected a bar starting at x %d", x)
+				}
+			}
+			next := tt.wantX[len(tt.wantX)-1] + tt.barWidth
+			for x := next; x < width; x++ {
+				if dm.img.RGBAAt(x, bottom).R != 0 {
+					t.Fatalf("Expected only %d bars, found a pixel at x %d", len(cores), x)
+				}
+			}
+		})
+	}
+}
+
+// TestMetricComponentErrors tests that failed cpu and memory readings are
+// reported rather than drawn as zero
+func TestMetricComponentErrors(t *testing.T) {
+	for _, compType := range []string{"cpu", "memory", "cpugraph", "cpucores"} {
+		t.Run(compType, func(t *testing.T) {
+			dm := &DisplayManager{
+				metricsSource: &MockMetricsProvider{err: fmt.Errorf("not supported")},
+				img:           blankFrame(),
+			}
+			comp := Component{Type: compType, X: 5, Y: 12, Label: "X", BarWidth: 20}
+			if err := dm.renderComponent(comp); err == nil {
+				t.Error("Expected an error, got nil")
+			}
+			if !bytes.Equal(dm.img.Pix, blankFrame().Pix) {
+				t.Error("Expected nothing to be drawn")
+			}
+		})
+	}
+}
+
+// countingMetricsProvider is a MockMetricsProvider that counts CPU reads
+type countingMetricsProvider struct {
+	MockMetricsProvider
+	cpuCalls, coreCalls int
+}
+
+func (c *countingMetricsProvider) CPUPercent() (float64, error) {
+	c.cpuCalls++
+	return c.MockMetricsProvider.CPUPercent()
+}
+
+func (c *countingMetricsProvider) PerCPUPercent() ([]float64, error) {
+	c.coreCalls++
+	return c.MockMetricsProvider.PerCPUPercent()
+}
+
+// TestCPUSampledOncePerFrame tests that components sharing a frame share one
+// CPU reading
+func TestCPUSampledOncePerFrame(t *testing.T) {
+	provider := &countingMetricsProvider{MockMetricsProvider: MockMetricsProvider{cpu: 50, cores: []float64{10, 20}}}
+	dm := &DisplayManager{
+		metricsSource: provider,
+		img:           blankFrame(),
+		config: Config{Screens: []Screen{{Name: "CPU", Components: []Component{
+			{Type: "cpu", X: 5, Y: 10},
+			{Type: "cpugraph", X: 5, Y: 20, BarWidth: 4},
+			{Type: "cpugraph", X: 60, Y: 20, BarWidth: 4},
+			{Type: "cpucores", X: 5, Y: 40},
+			{Type: "cpucores", X: 60, Y: 40},
+		}}}},
+	}
+	for frame := 1; frame <= 2; frame++ {
+		dm.renderFrame()
+		if provider.cpuCalls != frame || provider.coreCalls != frame {
+			t.Errorf("Frame %d: expected one read of each, got %d total and %d per-core reads", frame, provider.cpuCalls, provider.coreCalls)
+		}
+	}
+}
+
+// TestTimeComponentUsesClock tests that the time and date components render the injected clock
+func TestTimeComponentUsesClock(t *testing.T) {
+	fixed := time.Date(2024, 3, 9, 14, 5, 7, 0, time.Local)
+	tests := []struct {
+		name      string
+		comp      Component
+		wantLabel string
+	}{
+		{
+			name:      "Default format",
+			comp:      Component{Type: "time", X: 5, Y: 12},
+			wantLabel: "14:05:07",
+		},
+		{
+			name:      "Custom format with label",
+			comp:      Component{Type: "time", X: 5, Y: 12, Label: "Date", TimeFormat: "Mon 02-Jan"},
+			wantLabel: "Date: Sat 09-Mar",
+		},
+		{
+			name:      "Date default format",
+			comp:      Component{Type: "date", X: 5, Y: 12},
+			wantLabel: "Sat Mar 9",
+		},
+		{
+			name:      "Date custom format with label",
+			comp:      Component{Type: "date", X: 5, Y: 12, Label: "Today", TimeFormat: "2006-01-02"},
+			wantLabel: "Today: 2024-03-09",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dm := &DisplayManager{
+				img:     blankFrame(),
+				timeNow: func() time.Time { return fixed },
+			}
+			if err := dm.renderComponent(tt.comp); err != nil {
+				t.Fatalf("Failed to render component: %v", err)
+			}
+			if want := labelImage(5, 12, tt.wantLabel); !bytes.Equal(dm.img.Pix, want.Pix) {
+				t.Errorf("Rendered image does not match %q", tt.wantLabel)
+			}
+		})
+	}
+}
+
+// TestTimeComponentBlinkColon tests that blink_colon hides the colons on odd
+// seconds only when the format shows seconds
+func TestTimeComponentBlinkColon(t *testing.T) {
+	even := time.Date(2024, 3, 9, 14, 5, 6, 0, time.Local)
+	odd := even.Add(time.Second)
+	tests := []struct {
+		name      string
+		comp      Component
+		now       time.Time
+		wantLabel string
+	}{
+		{name: "Even second", comp: Component{Type: "time", X: 5, Y: 12, BlinkColon: true}, now: even, wantLabel: "14:05:06"},
+		{name: "Odd second", comp: Component{Type: "time", X: 5, Y: 12, BlinkColon: true}, now: odd, wantLabel: "14 05 07"},
+		{name: "Label keeps its colon", comp: Component{Type: "time", X: 5, Y: 12, Label: "Now", BlinkColon: true}, now: odd, wantLabel: "Now: 14 05 07"},
+		{name: "12-hour with seconds", comp: Component{Type: "time", X: 5, Y: 12, TimeFormat: "3:04:05 PM", BlinkColon: true}, now: odd, wantLabel: "2 05 07 PM"},
+		{name: "No seconds in format", comp: Component{Type: "time", X: 5, Y: 12, TimeFormat: "15:04", BlinkColon: true}, now: odd, wantLabel: "14:05"},
+		{name: "Disabled", comp: Component{Type: "time", X: 5, Y: 12}, now: odd, wantLabel: "14:05:07"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dm := &DisplayManager{
+				img:     blankFrame(),
+				timeNow: func() time.Time { return tt.now },
+			}
+			if err := dm.renderComponent(tt.comp); err != nil {
+				t.Fatalf("Failed to render component: %v", err)
+			}
+			if want := labelImage(5, 12, tt.wantLabel); !bytes.Equal(dm.img.Pix, want.Pix) {
+				t.Errorf("Rendered image does not match %q", tt.wantLabel)
+			}
+		})
+	}
+}
+
+// TestClock24h tests that clock_24h picks the time component's default
+// format and that an explicit time_format still wins
+func TestClock24h(t *testing.T) {
+	fixed := time.Date(2024, 3, 9, 14, 5, 7, 0, time.Local)
+	on, off := true, false
+	tests := []struct {
+		name      string
+		clock24h  *bool
+		comp      Component
+		wantLabel string
+	}{
+		{name: "Unset", comp: Component{Type: "time", X: 5, Y: 12}, wantLabel: "14:05:07"},
+		{name: "24-hour", clock24h: &on, comp: Component{Type: "time", X: 5, Y: 12}, wantLabel: "14:05:07"},
+		{name: "12-hour", clock24h: &off, comp: Component{Type: "time", X: 5, Y: 12}, wantLabel: "2:05:07 PM"},
+		{name: "Explicit format", clock24h: &off, comp: Component{Type: "time", X: 5, Y: 12, TimeFormat: "15:04"}, wantLabel: "14:05"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dm := &DisplayManager{
+				config:  Config{Clock24h: tt.clock24h},
+				img:     blankFrame(),
+				timeNow: func() time.Time { return fixed },
+			}
+			if err := dm.renderComponent(tt.comp); err != nil {
+				t.Fatalf("Failed to render component: %v", err)
+			}
+			if want := labelImage(5, 12, tt.wantLabel); !bytes.Equal(dm.img.Pix, want.Pix) {
+				t.Errorf("Rendered image does not match %q", tt.wantLabel)
+			}
+		})
+	}
+
+	// Calendar start times follow the same setting without seconds
+	if got := (Config{Clock24h: &off}).clockLayout(false); got != "3:04 PM" {
+		t.Errorf("Expected a 12-hour layout without seconds, got %q", got)
+	}
+}
+
+// TestClockTimezone tests that time and date components follow their own or
+// the global timezone rather than the clock's zone
+func TestClockTimezone(t *testing.T) {
+	fixed := time.Date(2024, 3, 9, 19, 5, 7, 0, time.UTC)
+	tests := []struct {
+		name      string
+		global    string
+		comp      Component
+		wantLabel string
+	}{
+		{name: "Unset", comp: Component{Type: "time", X: 5, Y: 12}, wantLabel: "19:05:07"},
+		{name: "Global", global: "America/New_York", comp: Component{Type: "time", X: 5, Y: 12}, wantLabel: "14:05:07"},
+		{name: "Component", global: "America/New_York", comp: Component{Type: "time", X: 5, Y: 12, Timezone: "Asia/Tokyo"}, wantLabel: "04:05:07"},
+		{name: "Date", comp: Component{Type: "date", X: 5, Y: 12, Timezone: "Asia/Tokyo"}, wantLabel: "Sun Mar 10"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dm := &DisplayManager{
+				config:  Config{Timezone: tt.global},
+				img:     blankFrame(),
+				timeNow: func() time.Time { return fixed },
+			}
+			if err := dm.renderComponent(tt.comp); err != nil {
+				t.Fatalf("Failed to render component: %v", err)
+			}
+			if want := labelImage(5, 12, tt.wantLabel); !bytes.Equal(dm.img.Pix, want.Pix) {
+				t.Errorf("Rendered image does not match %q", tt.wantLabel)
+			}
+		})
+	}
+
+	if err := validateConfig(Config{ScreenDuration: 5, Timezone: "Mars/Olympus_Mons", Screens: []Screen{
+		{Name: "A", Components: []Component{{Type: "time", X: 5, Y: 12, Timezone: "Nowhere/Special"}}},
+	}}); err == nil || !strings.Contains(err.Error(), "Mars/Olympus_Mons") || !strings.Contains(err.Error(), "Nowhere/Special") {
+		t.Errorf("Expected both unknown timezones to be reported, got %v", err)
+	}
+}
+
+// TestSkipUnchangedFrame tests that identical frames aren't redrawn
+func TestSkipUnchangedFrame(t *testing.T) {
+	mockDisplay := NewMockDisplay(t)
+	checker := &MockNetworkChecker{ipAddress: "192.168.1.100"}
+	dm := &DisplayManager{
+		dev:            mockDisplay,
+		networkChecker: checker,
+		img:            blankFrame(),
+		timeNow:        time.Now,
+		config: Config{
+			Screens: []Screen{
+				{Name: "Test Screen", Components: []Component{{Type: "ip", X: 5, Y: 20, Label: "IP"}}},
+			},
+		},
+	}
+
+	for i := 0; i < 2; i++ {
+		if err := dm.renderCurrentScreen(); err != nil {
+			t.Fatalf("Failed to render screen: %v", err)
+		}
+	}
+	if mockDisplay.drawCount != 1 {
+		t.Errorf("Expected 1 Draw for unchanged content, got %d", mockDisplay.drawCount)
+	}
+
+	checker.ipAddress = "192.168.1.101"
+	if err := dm.renderCurrentScreen(); err != nil {
+		t.Fatalf("Failed to render screen: %v", err)
+	}
+	if mockDisplay.drawCount != 2 {
+		t.Errorf("Expected a second Draw after content changed, got %d", mockDisplay.drawCount)
+	}
+}
+
+// TestComponentSchedule tests that scheduled components are drawn only inside their window
+func TestComponentSchedule(t *testing.T) {
+	hour := func(h int) *int { return &h }
+
+	tests := []struct {
+		name     string
+		start    *int
+		end      *int
+		at       int
+		wantDraw bool
+	}{
+		{"Unscheduled at midnight", nil, nil, 0, true},
+		{"Daytime window at noon", hour(8), hour(20), 12, true},
+		{"Daytime window at midnight", hour(8), hour(20), 0, false},
+		{"Start only before start", hour(8), nil, 7, false},
+		{"End only after end", nil, hour(20), 21, false},
+		{"Overnight window at midnight", hour(22), hour(6), 0, true},
+		{"Overnight window at noon", hour(22), hour(6), 12, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			now := time.Date(2024, 1, 1, tt.at, 0, 0, 0, time.Local)
+			dm := &DisplayManager{
+				networkChecker: &MockNetworkChecker{ipAddress: "192.168.1.100"},
+				img:            blankFrame(),
+				timeNow:        func() time.Time { return now },
+			}
+			comp := Component{Type: "ip", X: 5, Y: 20, Label: "IP", StartHour: tt.start, EndHour: tt.end}
+			if err := dm.renderComponent(comp); err != nil {
+				t.Fatalf("Failed to render component: %v", err)
+			}
+
+			drawn := leftmostLitX(dm.img) != -1
+			if drawn != tt.wantDraw {
+				t.Errorf("Expected drawn=%v at %02d:00, got %v", tt.wantDraw, tt.at, drawn)
+			}
+		})
+	}
+}
+
+// TestIPComponentInterfaces tests the ip component's interface list fallback
+func TestIPComponentInterfaces(t *testing.T) {
+	checker := &MockNetworkChecker{
+		ipAddress: "192.168.1.100",
+		addresses: map[string]string{
+			"wlan0": "192.168.1.50",
+			"usb0":  "10.0.0.2",
+		},
+	}
+
+	tests := []struct {
+		name       string
+		interfaces []string
+		wantLabel  string
+	}{
+		{"Global interface", nil, "IP: 192.168.1.100"},
+		{"First interface down", []string{"eth0", "wlan0", "usb0"}, "IP: 192.168.1.50"},
+		{"First interface up", []string{"usb0", "wlan0"}, "IP: 10.0.0.2"},
+		{"No interface up", []string{"eth0", "eth1"}, "IP: N/A"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dm := &DisplayManager{
+				config:         Config{NetworkInterface: "eth0"},
+				networkChecker: checker,
+				img:            blankFrame(),
+			}
+			comp := Component{Type: "ip", X: 5, Y: 20, Label: "IP", Interfaces: tt.interfaces}
+			if err := dm.renderComponent(comp); err != nil {
+				t.Fatalf("Failed to render component: %v", err)
+			}
+			if want := labelImage(5, 20, tt.wantLabel); !bytes.Equal(dm.img.Pix, want.Pix) {
+				t.Errorf("Rendered image does not match %q", tt.wantLabel)
+			}
+		})
+	}
+}
+
+// TestRealNetworkCheckerFirstIPv4 tests the real checker against the loopback interface
+func TestRealNetworkCheckerFirstIPv4(t *testing.T) {
+	ifaces, err := net.Interfaces()
+	if err != nil {
+		t.Skipf("Cannot list interfaces: %v", err)
+	}
+	loopback := ""
+	for _, iface := range ifaces {
+		if iface.Flags&net.FlagLoopback != 0 {
+			loopback = iface.Name
+		}
+	}
+	if loopback == "" {
+		t.Skip("No loopback interface")
+	}
+
+	checker := &RealNetworkChecker{}
+	if got := checker.GetFirstIPv4Address([]string{"does-not-exist0", loopback}); got != "127.0.0.1" {
+		t.Errorf("Expected fallback to loopback 127.0.0.1, got %q", got)
+	}
+	if got := checker.GetFirstIPv4Address([]string{"does-not-exist0"}); got != "No IP" {
+		t.Errorf("Expected \"No IP\", got %q", got)
+	}
+}
+
+// TestNetworkCheckerCache tests that addresses are looked up at most once
+// per TTL, and again when the interface goes down or up or on Refresh
+func TestNetworkCheckerCache(t *testing.T) {
+	now := time.Date(2024, 3, 9, 14, 0, 0, 0, time.UTC)
+	state := "up"
+	lookups := 0
+	checker := &RealNetworkChecker{
+		TTL: 5 * time.Second,
+		now: func() time.Time { return now },
+		lookup: func(name string) addrLookup {
+			lookups++
+			if state != "up" {
+				return addrLookup{}
+			}
+			return addrLookup{addrs: []net.Addr{
+				&net.IPNet{IP: net.ParseIP("192.168.1.100"), Mask: net.CIDRMask(24, 32)},
+				&net.IPNet{IP: net.ParseIP("2001:db8::1"), Mask: net.CIDRMask(64, 128)},
+			}}
+		},
+		linkState: func(name string) string { return state },
+	}
+
+	steps := []struct {
+		name        string
+		change      func()
+		want        string
+		wantLookups int
+	}{
+		{name: "First call", change: func() {}, want: "192.168.1.100", wantLookups: 1},
+		{name: "Within TTL", change: func() { now = now.Add(4 * time.Second) }, want: "192.168.1.100", wantLookups: 1},
+		{name: "TTL expired", change: func() { now = now.Add(time.Second) }, want: "192.168.1.100", wantLookups: 2},
+		{name: "Interface down", change: func() { state = "down" }, want: "No IPv4", wantLookups: 3},
+		{name: "Still down", change: func() {}, want: "No IPv4", wantLookups: 3},
+		{name: "Interface up", change: func() { state = "up" }, want: "192.168.1.100", wantLookups: 4},
+		{name: "Refresh", change: func() { checker.Refresh() }, want: "192.168.1.100", wantLookups: 5},
+	}
+	for _, step := range steps {
+		step.change()
+		if got := checker.GetIPv4Address("eth0"); got != step.want {
+			t.Errorf("%s: expected %q, got %q", step.name, step.want, got)
+		}
+		// The other lookups share the cached result
+		checker.GetFirstIPv4Address([]string{"eth0"})
+		checker.GetIPv6Address("eth0")
+		if lookups != step.wantLookups {
+			t.Errorf("%s: expected %d lookups, got %d", step.name, step.wantLookups, lookups)
+		}
+	}
+
+	// Interfaces are cached separately
+	checker.GetIPv4Address("wlan0")
+	if lookups != 6 {
+		t.Errorf("Expected a separate lookup for another interface, got %d lookups", lookups)
+	}
+}
+
+// TestIPv6Component tests rendering an IPv6 address with the ip component
+func TestIPv6Component(t *testing.T) {
+	tests := []struct {
+		name      string
+		checker   *MockNetworkChecker
+		wantLabel string
+	}{
+		{"IPv6 address", &MockNetworkChecker{ipAddress: "10.0.0.1", ipv6Address: "2001:db8::1"}, "IP6: 2001:db8::1"},
+		{"No IPv6 address", &MockNetworkChecker{ipAddress: "10.0.0.1"}, "IP6: N/A"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dm := &DisplayManager{
+				config:         Config{NetworkInterface: "eth0"},
+				networkChecker: tt.checker,
+				img:            blankFrame(),
+			}
+			comp := Component{Type: "ip", X: 5, Y: 20, Label: "IP6", Family: "ipv6"}
+			if err := dm.renderComponent(comp); err != nil {
+				t.Fatalf("Failed to render component: %v", err)
+			}
+			if want := labelImage(5, 20, tt.wantLabel); !bytes.Equal(dm.img.Pix, want.Pix) {
+				t.Errorf("Rendered image does not match %q", tt.wantLabel)
+			}
+		})
+	}
+}
+
+// TestUpdateBrightness tests day/night contrast selection, including windows
+// that wrap past midnight
+func TestUpdateBrightness(t *testing.T) {
+	tests := []struct {
+		name         string
+		dayStart     int
+		nightStart   int
+		hour         int
+		wantContrast uint8
+	}{
+		{"Normal window, day", 7, 18, 12, brightContrast},
+		{"Normal window, day start", 7, 18, 7, brightContrast},
+		{"Normal window, night start", 7, 18, 18, dimContrast},
+		{"Normal window, early morning", 7, 18, 3, dimContrast},
+		{"Wrapped window, late evening", 8, 2, 23, brightContrast},
+		{"Wrapped window, after midnight", 8, 2, 1, brightContrast},
+		{"Wrapped window, night start", 8, 2, 2, dimContrast},
+		{"Wrapped window, night", 8, 2, 4, dimContrast},
+		{"Wrapped window, day start", 8, 2, 8, brightContrast},
+		{"Equal hours", 9, 9, 9, dimContrast},
+		{"Equal hours, other hour", 9, 9, 15, dimContrast},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockDisplay := NewMockDisplay(t)
+			mockDisplay.contrast = 0
+			now := time.Date(2024, 3, 9, tt.hour, 30, 0, 0, time.Local)
+			dm := &DisplayManager{
+				config:  Config{DayStartHour: tt.dayStart, NightStartHour: tt.nightStart},
+				dev:     mockDisplay,
+				timeNow: func() time.Time { return now },
+			}
+			if err := dm.updateBrightness(); err != nil {
+				t.Fatalf("updateBrightness failed: %v", err)
+			}
+			if mockDisplay.contrast != tt.wantContrast {
+				t.Errorf("Expected contrast %d, got %d", tt.wantContrast, mockDisplay.contrast)
+			}
+		})
+	}
+}
+
+// TestRampContrast tests the contrast interpolation across transition windows
+func TestRampContrast(t *testing.T) {
+	at := func(hour, minute int) time.Time {
+		return time.Date(2024, 3, 9, hour, minute, 0, 0, time.Local)
+	}
+	tests := []struct {
+		name       string
+		dayStart   int
+		nightStart int
+		transition int
+		now        time.Time
+		want       uint8
+	}{
+		{"No transition, day", 7, 18, 0, at(7, 0), brightContrast},
+		{"No transition, night", 7, 18, 0, at(18, 0), dimContrast},
+		{"Dawn start", 7, 18, 30, at(7, 0), dimContrast},
+		{"Dawn midway", 7, 18, 30, at(7, 15), 128},
+		{"Dawn end", 7, 18, 30, at(7, 30), brightContrast},
+		{"Full day", 7, 18, 30, at(12, 0), brightContrast},
+		{"Dusk start", 7, 18, 30, at(18, 0), brightContrast},
+		{"Dusk midway", 7, 18, 30, at(18, 15), 128},
+		{"Dusk nearly done", 7, 18, 30, at(18, 29), 10},
+		{"Full night", 7, 18, 30, at(23, 0), dimContrast},
+		{"Wrapped window, dusk after midnight", 8, 0, 60, at(0, 30), 128},
+		{"Wrapped window, dawn", 22, 6, 60, at(22, 30), 128},
+		{"Wrapped window, before dawn", 22, 6, 60, at(21, 59), dimContrast},
+		{"Equal hours", 9, 9, 30, at(9, 10), dimContrast},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := rampContrast(tt.now, tt.dayStart, tt.nightStart, tt.transition, brightContrast, dimContrast); got != tt.want {
+				t.Errorf("rampContrast() = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
+
+// TestConfiguredContrast tests that day_contrast and night_contrast reach the display
+func TestConfiguredContrast(t *testing.T) {
+	day, night := 180, 0
+	tests := []struct {
+		name         string
+		config       Config
+		hour         int
+		wantContrast uint8
+	}{
+		{"Configured day", Config{DayStartHour: 7, NightStartHour: 18, DayContrast: &day, NightContrast: &night}, 12, 180},
+		{"Configured night", Config{DayStartHour: 7, NightStartHour: 18, DayContrast: &day, NightContrast: &night}, 22, 0},
+		{"Default day", Config{DayStartHour: 7, NightStartHour: 18}, 12, brightContrast},
+		{"Default night", Config{DayStartHour: 7, NightStartHour: 18}, 22, dimContrast},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockDisplay := NewMockDisplay(t)
+			mockDisplay.contrast = 42
+			now := time.Date(2024, 3, 9, tt.hour, 0, 0, 0, time.Local)
+			dm := &DisplayManager{
+				config:  tt.config,
+				dev:     mockDisplay,
+				timeNow: func() time.Time { return now },
+			}
+			if err := dm.updateBrightness(); err != nil {
+				t.Fatalf("updateBrightness failed: %v", err)
+			}
+			if mockDisplay.contrast != tt.wantContrast {
+				t.Errorf("Expected contrast %d, got %d", tt.wantContrast, mockDisplay.contrast)
+			}
+		})
+	}
+}
+
+// MockProcessLister implements ProcessLister for testing
+type MockProcessLister struct {
+	pids    []int32
+	samples []map[int32]processCPU // returned in order by successive CPUTimes calls
+}
+
+func (m *MockProcessLister) Pids() ([]int32, error) {
+	return m.pids, nil
+}
+
+func (m *MockProcessLister) CPUTimes() (map[int32]processCPU, error) {
+	sample := m.samples[0]
+	if len(m.samples) > 1 {
+		m.samples = m.samples[1:]
+	}
+	return sample, nil
+}
+
+// TestProcessesComponent tests the process count rendering
+func TestProcessesComponent(t *testing.T) {
+	dm := &DisplayManager{
+		processLister: &MockProcessLister{pids: []int32{1, 2, 3, 42, 1337}},
+		img:           blankFrame(),
+	}
+	comp := Component{Type: "processes", X: 5, Y: 12, Label: "Procs"}
+	if err := dm.renderComponent(comp); err != nil {
+		t.Fatalf("Failed to render component: %v", err)
+	}
+	if want := labelImage(5, 12, "Procs: 5"); !bytes.Equal(dm.img.Pix, want.Pix) {
+		t.Errorf("Rendered image does not match %q", "Procs: 5")
+	}
+}
+
+// TestSampleTopProcess tests picking the busiest process between samples,
+// including processes that appear, vanish or have their PID reused
+func TestSampleTopProcess(t *testing.T) {
+	start := time.Date(2024, 3, 9, 14, 0, 0, 0, time.Local)
+	now := start
+	lister := &MockProcessLister{samples: []map[int32]processCPU{
+		{
+			1:  {name: "init", seconds: 10},
+			20: {name: "chrome", seconds: 100},
+			30: {name: "gone", seconds: 50},
+			40: {name: "old", seconds: 500},
+		},
+		{
+			1:  {name: "init", seconds: 10.1},
+			20: {name: "chrome", seconds: 100.68},
+			40: {name: "new", seconds: 1},     // PID reused by a new process
+			50: {name: "started", seconds: 9}, // not in the previous sample
+		},
+	}}
+	dm := &DisplayManager{
+		processLister: lister,
+		timeNow:       func() time.Time { return now },
+	}
+
+	if _, _, ok, err := dm.sampleTopProcess(); err != nil || ok {
+		t.Fatalf("First sample: expected ok=false and no error, got ok=%v err=%v", ok, err)
+	}
+
+	now = start.Add(2 * time.Second)
+	name, percent, ok, err := dm.sampleTopProcess()
+	if err != nil || !ok {
+		t.Fatalf("Second sample: expected ok=true and no error, got ok=%v err=%v", ok, err)
+	}
+	if name != "chrome" || math.Abs(percent-34) > 0.001 {
+		t.Errorf("Expected chrome at 34%%, got %s at %.3f%%", name, percent)
+	}
+}
+
+// MockBatteryReader implements BatteryReader for testing
+type MockBatteryReader struct {
+	state   batteryState
+	present bool
+}
+
+func (m *MockBatteryReader) Battery() (batteryState, bool, error) {
+	return m.state, m.present, nil
+}
+
+// TestBatteryComponent tests rendering fake battery states
+func TestBatteryComponent(t *testing.T) {
+	tests := []struct {
+		name      string
+		reader    *MockBatteryReader
+		wantLabel string
+		wantBar   float64
+	}{
+		{"No battery", &MockBatteryReader{}, "Bat: AC", -1},
+		{"Empty", &MockBatteryReader{state: batteryState{percent: 0}, present: true}, "Bat: 0%", 0},
+		{"Full", &MockBatteryReader{state: batteryState{percent: 100}, present: true}, "Bat: 100%", 1},
+		{"Charging", &MockBatteryReader{state: batteryState{percent: 42, charging: true}, present: true}, "Bat: 42% +", 0.42},
+		{"Discharging", &MockBatteryReader{state: batteryState{percent: 87}, present: true}, "Bat: 87%", 0.87},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dm := &DisplayManager{
+				batteryReader: tt.reader,
+				img:           blankFrame(),
+			}
+			comp := Component{Type: "battery", X: 5, Y: 12, Label: "Bat", ShowBar: true, BarWidth: 100}
+			if err := dm.renderComponent(comp); err != nil {
+				t.Fatalf("Failed to render component: %v", err)
+			}
+
+			want := labelImage(5, 12, tt.wantLabel)
+			if tt.wantBar >= 0 {
+				render.DrawBar(want, 5, 17, 100, render.BarHeight, tt.wantBar, 1)
+			}
+			if !bytes.Equal(dm.img.Pix, want.Pix) {
+				t.Errorf("Rendered image does not match %q", tt.wantLabel)
+			}
+		})
+	}
+}
+
+// TestReadBattery tests reading a battery from a sysfs-style directory
+func TestReadBattery(t *testing.T) {
+	dir := t.TempDir()
+	writeSupply := func(name string, files map[string]string) {
+		supply := filepath.Join(dir, name)
+		if err := os.Mkdir(supply, 0755); err != nil {
+			t.Fatal(err)
+		}
+		for file, content := range files {
+			if err := os.WriteFile(filepath.Join(supply, file), []byte(content), 0644); err != nil {
+				t.Fatal(err)
+			}
+		}
+	}
+
+	if _, present, err := readBattery(filepath.Join(dir, "missing")); err != nil || present {
+		t.Errorf("Missing directory: expected no battery and no error, got present=%v err=%v", present, err)
+	}
+
+	writeSupply("AC", map[string]string{"type": "Mains\n", "online": "1\n"})
+	if _, present, err := readBattery(dir); err != nil || present {
+		t.Errorf("Mains only: expected no battery and no error, got present=%v err=%v", present, err)
+	}
+
+	writeSupply("BAT0", map[string]string{"type": "Battery\n", "capacity": "76\n", "status": "Charging\n"})
+	state, present, err := readBattery(dir)
+	if err != nil || !present {
+		t.Fatalf("Expected a battery, got present=%v err=%v", present, err)
+	}
+	if state.percent != 76 || !state.charging {
+		t.Errorf("Expected 76%% charging, got %+v", state)
+	}
+}
+
+// TestTextComponent tests that the text component draws its literal string
+func TestTextComponent(t *testing.T) {
+	dm := &DisplayManager{
+		img: blankFrame(),
+	}
+	comp := Component{Type: "text", X: 5, Y: 12, Label: "ignored", Text: "Living Room Pi"}
+	if err := dm.renderComponent(comp); err != nil {
+		t.Fatalf("Failed to render component: %v", err)
+	}
+	if want := labelImage(5, 12, "Living Room Pi"); !bytes.Equal(dm.img.Pix, want.Pix) {
+		t.Errorf("Rendered image does not match %q", "Living Room Pi")
+	}
+}
+
+// TestLineComponent tests that lines light exactly their pixels
+func TestLineComponent(t *testing.T) {
+	tests := []struct {
+		name string
+		comp Component
+		want image.Rectangle // lit area
+	}{
+		{"Horizontal", Component{Type: "line", X: 10, Y: 20, Length: 30}, image.Rect(10, 20, 40, 21)},
+		{"Horizontal thick", Component{Type: "line", X: 0, Y: 32, Length: 128, Thickness: 2}, image.Rect(0, 32, 128, 34)},
+		{"Vertical", Component{Type: "line", X: 64, Y: 5, Length: 40, Orientation: "vertical"}, image.Rect(64, 5, 65, 45)},
+		{"Vertical thick", Component{Type: "line", X: 64, Y: 5, Length: 40, Thickness: 3, Orientation: "vertical"}, image.Rect(64, 5, 67, 45)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dm := &DisplayManager{
+				img: blankFrame(),
+			}
+			if err := dm.renderComponent(tt.comp); err != nil {
+				t.Fatalf("Failed to render component: %v", err)
+			}
+			// Check one pixel beyond the line on every side as well
+			check := tt.want.Inset(-1).Intersect(dm.img.Bounds())
+			for y := check.Min.Y; y < check.Max.Y; y++ {
+				for x := check.Min.X; x < check.Max.X; x++ {
+					lit := dm.img.RGBAAt(x, y).R != 0
+					if want := (image.Point{X: x, Y: y}).In(tt.want); lit != want {
+						t.Fatalf("Pixel %d,%d: expected lit=%v, got %v", x, y, want, lit)
+					}
+				}
+			}
+		})
+	}
+}
+
+// TestVerticalComponentBar tests that orientation: vertical draws a vertical gauge
+func TestVerticalComponentBar(t *testing.T) {
+	dm := &DisplayManager{
+		swapReader: &MockSwapReader{swap: &mem.SwapMemoryStat{Total: 1000, Used: 500, UsedPercent: 50}},
+		img:        blankFrame(),
+	}
+	comp := Component{Type: "swap", X: 5, Y: 12, Label: "Swap", ShowBar: true, Orientation: "vertical", Height: 30}
+	if err := dm.renderComponent(comp); err != nil {
+		t.Fatalf("Failed to render component: %v", err)
+	}
+
+	want := labelImage(5, 12, "Swap: 50.0%")
+	render.DrawVBar(want, 5, 17, render.BarHeight, 30, 0.5, 1)
+	if !bytes.Equal(dm.img.Pix, want.Pix) {
+		t.Errorf("Rendered image does not match a vertical bar")
+	}
+}
+
+// TestBarText tests that show_bar_text draws the percentage inside the bar
+func TestBarText(t *testing.T) {
+	dm := &DisplayManager{
+		swapReader: &MockSwapReader{swap: &mem.SwapMemoryStat{Total: 1000, Used: 500, UsedPercent: 50}},
+		img:        blankFrame(),
+	}
+	comp := Component{Type: "swap", X: 5, Y: 12, Label: "Swap", ShowBar: true, BarWidth: 100, ShowBarText: true}
+	if err := dm.renderComponent(comp); err != nil {
+		t.Fatalf("Failed to render component: %v", err)
+	}
+
+	barH := render.BarTextHeight(basicfont.Face7x13)
+	plain := labelImage(5, 12, "Swap: 50.0%")
+	render.DrawBar(plain, 5, 17, 100, barH, 0.5, 1)
+
+	// Every changed pixel must be inside the bar, and there must be some
+	inside := image.Rect(6, 18, 104, 16+barH)
+	changed := 0
+	for y := 0; y < height; y++ {
+		for x := 0; x < width; x++ {
+			if dm.img.RGBAAt(x, y) == plain.RGBAAt(x, y) {
+				continue
+			}
+			if !(image.Point{X: x, Y: y}).In(inside) {
+				t.Fatalf("Pixel %d,%d outside the bar was changed", x, y)
+			}
+			changed++
+		}
+	}
+	if changed == 0 {
+		t.Error("Expected the percentage to be drawn inside the bar")
+	}
+}
+
+// TestWhiteBackground tests that a white-background screen draws dark on light
+func TestWhiteBackground(t *testing.T) {
+	dm := &DisplayManager{
+		img: blankFrame(),
+		config: Config{Screens: []Screen{{
+			Name:       "Inverted",
+			Background: "white",
+			Components: []Component{{Type: "text", X: 5, Y: 20, Text: "Hello"}},
+		}}},
+	}
+	dm.renderFrame()
+
+	want := labelImage(5, 20, "Hello")
+	render.Invert(want)
+	if !bytes.Equal(dm.img.Pix, want.Pix) {
+		t.Error("Expected dark text on a white background")
+	}
+	if dm.img.RGBAAt(0, 0).R == 0 {
+		t.Error("Expected the background to be lit")
+	}
+
+	// Rendering again starts from a cleared frame rather than re-inverting
+	dm.renderFrame()
+	if !bytes.Equal(dm.img.Pix, want.Pix) {
+		t.Error("Expected the same frame on the second render")
+	}
+}
+
+// contrastResettingDisplay is a MockDisplay whose Invert resets the contrast,
+// as some controllers do
+type contrastResettingDisplay struct {
+	*MockDisplay
+}
+
+func (d *contrastResettingDisplay) Invert(inverted bool) error {
+	d.contrast = brightContrast
+	return d.MockDisplay.Invert(inverted)
+}
+
+// TestToggleInvertReassertsContrast tests that contrast is applied again after
+// an invert toggle, and that invert_daytime_only keeps nights uninverted
+func TestToggleInvertReassertsContrast(t *testing.T) {
+	tests := []struct {
+		name         string
+		daytimeOnly  bool
+		hour         int
+		wantInverted bool
+		wantContrast uint8
+	}{
+		{"Night", false, 22, true, dimContrast},
+		{"Day", false, 12, true, brightContrast},
+		{"Night, daytime only", true, 22, false, dimContrast},
+		{"Day, daytime only", true, 12, true, brightContrast},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockDisplay := &contrastResettingDisplay{NewMockDisplay(t)}
+			now := time.Date(2024, 3, 9, tt.hour, 0, 0, 0, time.Local)
+			dm := &DisplayManager{
+				config:  Config{DayStartHour: 7, NightStartHour: 18, InvertDaytimeOnly: tt.daytimeOnly},
+				dev:     mockDisplay,
+				timeNow: func() time.Time { return now },
+			}
+			if err := dm.toggleInvert(); err != nil {
+				t.Fatalf("toggleInvert failed: %v", err)
+			}
+			if mockDisplay.inverted != tt.wantInverted || dm.isInverted != tt.wantInverted {
+				t.Errorf("Expected inverted=%v, got display=%v manager=%v", tt.wantInverted, mockDisplay.inverted, dm.isInverted)
+			}
+			if mockDisplay.contrast != tt.wantContrast {
+				t.Errorf("Expected contrast %d after toggling, got %d", tt.wantContrast, mockDisplay.contrast)
+			}
+		})
+	}
+}
+
+// TestSyncInvertTicker tests that a reload can turn invert toggling on and off
+func TestSyncInvertTicker(t *testing.T) {
+	dm := &DisplayManager{}
+	ticker, c := dm.syncInvertTicker(nil)
+	if ticker != nil || c != nil {
+		t.Fatalf("Expected no ticker with invert_duration unset")
+	}
+
+	dm.config.InvertDuration = 60
+	ticker, c = dm.syncInvertTicker(ticker)
+	if ticker == nil || c == nil {
+		t.Fatalf("Expected a ticker once invert_duration is set")
+	}
+	dm.config.InvertDuration = 30
+	if again, _ := dm.syncInvertTicker(ticker); again != ticker {
+		t.Errorf("Expected the running ticker to be reset, not replaced")
+	}
+
+	dm.config.InvertDuration = 0
+	if ticker, c = dm.syncInvertTicker(ticker); ticker != nil || c != nil {
+		t.Errorf("Expected the ticker to stop once invert_duration is cleared")
+	}
+}
+
+// countingSwapReader is a MockSwapReader that counts reads
+type countingSwapReader struct {
+	MockSwapReader
+	calls int
+}
+
+func (c *countingSwapReader) SwapMemory() (*mem.SwapMemoryStat, error) {
+	c.calls++
+	return c.MockSwapReader.SwapMemory()
+}
+
+// TestComponentRefreshSeconds tests that a component with refresh_seconds
+// reuses its last rendering instead of re-reading its data source every tick
+func TestComponentRefreshSeconds(t *testing.T) {
+	reader := &countingSwapReader{MockSwapReader: MockSwapReader{swap: &mem.SwapMemoryStat{Total: 1000, Used: 250, UsedPercent: 25}}}
+	start := time.Date(2024, 3, 9, 14, 0, 0, 0, time.Local)
+	now := start
+	dm := &DisplayManager{
+		swapReader: reader,
+		img:        blankFrame(),
+		timeNow:    func() time.Time { return now },
+		config: Config{Screens: []Screen{{
+			Name:       "Slow",
+			Components: []Component{{Type: "swap", X: 5, Y: 12, Label: "Swap", RefreshSeconds: 60}},
+		}}},
+	}
+	want := labelImage(5, 12, "Swap: 25.0%")
+
+	for second := 0; second < 60; second++ {
+		now = start.Add(time.Duration(second) * time.Second)
+		dm.renderFrame()
+		if !bytes.Equal(dm.img.Pix, want.Pix) {
+			t.Fatalf("Second %d: expected the cached value to be drawn", second)
+		}
+	}
+	if reader.calls != 1 {
+		t.Errorf("Expected 1 read in the first minute, got %d", reader.calls)
+	}
+
+	reader.swap = &mem.SwapMemoryStat{Total: 1000, Used: 500, UsedPercent: 50}
+	now = start.Add(60 * time.Second)
+	dm.renderFrame()
+	if reader.calls != 2 {
+		t.Errorf("Expected a second read after 60s, got %d reads", reader.calls)
+	}
+	if want := labelImage(5, 12, "Swap: 50.0%"); !bytes.Equal(dm.img.Pix, want.Pix) {
+		t.Error("Expected the refreshed value to be drawn")
+	}
+}
+
+// TestCachedComponentAnimates tests that a component with refresh_seconds
+// keeps scrolling and blinking between samples
+func TestCachedComponentAnimates(t *testing.T) {
+	alertAbove := 20.0
+	tests := []struct {
+		name    string
+		comp    Component
+		advance func(dm *DisplayManager)
+	}{
+		{
+			name:    "Marquee",
+			comp:    Component{Type: "swap", X: 100, Y: 12, Label: "Swap", Scroll: true, RefreshSeconds: 60},
+			advance: func(dm *DisplayManager) { dm.advanceScrolls() },
+		},
+		{
+			name:    "Alert blink",
+			comp:    Component{Type: "swap", X: 5, Y: 12, Label: "Swap", AlertAbove: &alertAbove, RefreshSeconds: 60},
+			advance: func(dm *DisplayManager) { dm.blinkOff = !dm.blinkOff },
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			reader := &countingSwapReader{MockSwapReader: MockSwapReader{swap: &mem.SwapMemoryStat{Total: 1000, Used: 250, UsedPercent: 25}}}
+			now := time.Date(2024, 3, 9, 14, 0, 0, 0, time.Local)
+			dm := &DisplayManager{
+				swapReader: reader,
+				img:        blankFrame(),
+				timeNow:    func() time.Time { return now },
+				config:     Config{Screens: []Screen{{Name: "Slow", Components: []Component{tt.comp}}}},
+			}
+			dm.renderFrame()
+			first := append([]byte(nil), dm.img.Pix...)
+			if bytes.Equal(first, blankFrame().Pix) {
+				t.Fatal("Expected the first frame to show the component")
+			}
+
+			tt.advance(dm)
+			now = now.Add(time.Second)
+			dm.renderFrame()
+			if bytes.Equal(dm.img.Pix, first) {
+				t.Error("Expected the cached component to change between samples")
+			}
+			if reader.calls != 1 {
+				t.Errorf("Expected 1 read, got %d", reader.calls)
+			}
+		})
+	}
+}
+
+// TestComponentStaleAfter tests that a component whose source keeps failing
+// shows its last value, dimmed once it is older than stale_after intervals
+func TestComponentStaleAfter(t *testing.T) {
+	provider := &MockMetricsProvider{cpu: 25}
+	start := time.Date(2024, 3, 9, 14, 0, 0, 0, time.Local)
+	now := start
+	dm := &DisplayManager{
+		metricsSource: provider,
+		img:           blankFrame(),
+		timeNow:       func() time.Time { return now },
+		config: Config{Screens: []Screen{{
+			Name:       "Stale",
+			Components: []Component{{Type: "cpu", X: 5, Y: 12, Label: "CPU", RefreshSeconds: 10, StaleAfter: 2}},
+		}}},
+	}
+	dm.renderFrame()
+	fresh := image.NewRGBA(dm.img.Bounds())
+	copy(fresh.Pix, dm.img.Pix)
+
+	provider.err = fmt.Errorf("cpu stats unavailable")
+	tests := []struct {
+		second    int
+		wantStale bool
+	}{
+		{10, false},
+		{20, false},
+		{21, true},
+		{40, true},
+	}
+	for _, tt := range tests {
+		now = start.Add(time.Duration(tt.second) * time.Second)
+		dm.renderFrame()
+		want := fresh
+		if tt.wantStale {
+			want = blankFrame()
+			draw.Draw(want, want.Bounds(), render.Dim(fresh), image.Point{}, draw.Over)
+		}
+		if !bytes.Equal(dm.img.Pix, want.Pix) {
+			t.Errorf("Second %d: expected the last value with stale = %v", tt.second, tt.wantStale)
+		}
+	}
+
+	provider.err = nil
+	provider.cpu = 50
+	now = start.Add(50 * time.Second)
+	dm.renderFrame()
+	if bytes.Equal(dm.img.Pix, fresh.Pix) {
+		t.Error("Expected a recovered source to draw its new value")
+	}
+}
+
+// TestWrappedText tests that wrapped text is drawn one line per line height
+// within max_width
+func TestWrappedText(t *testing.T) {
+	dm := &DisplayManager{img: blankFrame()}
+	comp := Component{Type: "text", X: 5, Y: 12, Text: "disk almost full on backup", Wrap: true, MaxWidth: 84}
+	if err := dm.renderComponent(comp); err != nil {
+		t.Fatalf("Failed to render component: %v", err)
+	}
+
+	want := labelImage(5, 12, "disk almost")
+	render.AddLabel(want, basicfont.Face7x13, 5, 25, "full on")
+	render.AddLabel(want, basicfont.Face7x13, 5, 38, "backup")
+	if !bytes.Equal(dm.img.Pix, want.Pix) {
+		t.Error("Expected the text on three lines 13 pixels apart")
+	}
+}
+
+// TestZeroScreensFallback tests that a manager without screens shows a
+// message instead of panicking, both when rendering and when rotating
+func TestZeroScreensFallback(t *testing.T) {
+	face := basicfont.Face7x13
+	want := blankFrame()
+	render.AddLabel(want, face, render.AlignX(face, width/2, "No screens", "center"), 32, "No screens")
+	render.AddLabel(want, face, render.AlignX(face, width/2, "configured", "center"), 45, "configured")
+
+	mockDisplay := NewMockDisplay(t)
+	dm := &DisplayManager{
+		dev:     mockDisplay,
+		img:     blankFrame(),
+		timeNow: time.Now,
+	}
+	if err := dm.renderCurrentScreen(); err != nil {
+		t.Fatalf("Failed to render screen: %v", err)
+	}
+	if !bytes.Equal(mockDisplay.lastImage.Pix, want.Pix) {
+		t.Error("Expected the no screens message to be drawn")
+	}
+
+	// Stepping through screens wraps around the single fallback screen
+	dm = &DisplayManager{
+		dev:      NewMockDisplay(t),
+		img:      blankFrame(),
+		timeNow:  time.Now,
+		commands: make(chan displayCommand, commandQueueSize),
+	}
+	dm.commands <- displayCommand{kind: cmdNextScreen}
+	dm.commands <- displayCommand{kind: cmdPrevScreen}
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+	if err := dm.Run(ctx); err != nil {
+		t.Fatalf("Run failed: %v", err)
+	}
+	if dm.currentScreen != 0 {
+		t.Errorf("Expected to stay on the fallback screen, got %d", dm.currentScreen)
+	}
+}
+
+// TestDimText tests that dim_text dithers a component's text, set on the
+// component itself or on its screen
+func TestDimText(t *testing.T) {
+	tests := []struct {
+		name   string
+		screen Screen
+	}{
+		{name: "Component", screen: Screen{Components: []Component{{Type: "text", X: 5, Y: 20, Text: "Quiet", DimText: true}}}},
+		{name: "Screen", screen: Screen{DimText: true, Components: []Component{{Type: "text", X: 5, Y: 20, Text: "Quiet"}}}},
+	}
+
+	want := blankFrame()
+	render.AddDimLabel(want, basicfont.Face7x13, 5, 20, "Quiet")
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dm := &DisplayManager{
+				config: Config{Screens: []Screen{tt.screen}},
+				img:    blankFrame(),
+			}
+			dm.renderFrame()
+			if !bytes.Equal(dm.img.Pix, want.Pix) {
+				t.Error("Expected the text to be drawn dithered")
+			}
+			if solid := labelImage(5, 20, "Quiet"); bytes.Equal(dm.img.Pix, solid.Pix) {
+				t.Error("Expected dim text to differ from solid text")
+			}
+		})
+	}
+}
+
+// TestFailingComponentDoesNotBlankScreen tests that one component's error is
+// logged while the other components still render
+func TestFailingComponentDoesNotBlankScreen(t *testing.T) {
+	dm := &DisplayManager{
+		metricsSource: &MockMetricsProvider{temps: &MockTemperatureReader{}},
+		img:           blankFrame(),
+		config: Config{Screens: []Screen{{
+			Name: "Mixed",
+			Components: []Component{
+				{Type: "temperature", X: 5, Y: 12, Label: "Temp"},
+				{Type: "text", X: 5, Y: 40, Text: "Still here"},
+			},
+		}}},
+	}
+	dm.renderFrame()
+	want := labelImage(5, 40, "Still here")
+	render.AddLabel(want, basicfont.Face7x13, 5, 12, "Temp: N/A")
+	if !bytes.Equal(dm.img.Pix, want.Pix) {
+		t.Error("Expected the placeholder and the other component to be drawn")
+	}
+}
+
+// TestFailingComponentLoggedOnce tests that a component that keeps failing is
+// logged when it starts failing and when it recovers, not on every frame
+func TestFailingComponentLoggedOnce(t *testing.T) {
+	var logs bytes.Buffer
+	defer slog.SetDefault(slog.Default())
+	slog.SetDefault(slog.New(slog.NewTextHandler(&logs, nil)))
+
+	provider := &MockMetricsProvider{err: fmt.Errorf("sensor gone")}
+	dm := &DisplayManager{
+		metricsSource: provider,
+		img:           blankFrame(),
+		config:        Config{Screens: []Screen{{Name: "CPU", Components: []Component{{Type: "cpu", X: 5, Y: 12}}}}},
+	}
+	for i := 0; i < 3; i++ {
+		dm.renderFrame()
+	}
+	if n := strings.Count(logs.String(), "failed to render component"); n != 1 {
+		t.Errorf("Expected the failure to be logged once, got %d times", n)
+	}
+
+	provider.err = nil
+	dm.renderFrame()
+	dm.renderFrame()
+	if n := strings.Count(logs.String(), "component recovered"); n != 1 {
+		t.Errorf("Expected the recovery to be logged once, got %d times", n)
+	}
+}
+
+// TestPlaceholder tests that a configured placeholder replaces N/A on both
+// a component's own fallback and a failed read
+func TestPlaceholder(t *testing.T) {
+	dm := &DisplayManager{
+		config: Config{
+			Placeholder: "??",
+			Screens: []Screen{{
+				Name: "Sources",
+				Components: []Component{
+					{Type: "temperature", X: 5, Y: 12, Label: "Temp"},
+					{Type: "disk", X: 5, Y: 26, Mountpoint: "/data"},
+					{Type: "ip", X: 5, Y: 40, Label: "IP"},
+					{Type: "cpu", X: 5, Y: 54},
+				},
+			}},
+		},
+		networkChecker: &MockNetworkChecker{},
+		metricsSource:  &MockMetricsProvider{temps: &MockTemperatureReader{}, err: fmt.Errorf("not supported")},
+		img:            blankFrame(),
+	}
+	dm.renderFrame()
+
+	want := labelImage(5, 12, "Temp: ??")
+	render.AddLabel(want, basicfont.Face7x13, 5, 26, "/data: ??")
+	render.AddLabel(want, basicfont.Face7x13, 5, 40, "IP: ??")
+	render.AddLabel(want, basicfont.Face7x13, 5, 54, "??")
+	if !bytes.Equal(dm.img.Pix, want.Pix) {
+		t.Error("Expected every unreadable value to show the placeholder")
+	}
+}
+
+// TestOpenDisplayWithRetry tests that transient open failures are retried
+// with a doubling delay and that the last error is returned once retries run out
+func TestOpenDisplayWithRetry(t *testing.T) {
+	tests := []struct {
+		name       string
+		config     Config
+		failures   int
+		wantErr    bool
+		wantSleeps []time.Duration
+	}{
+		{
+			name:     "Opens first time",
+			config:   Config{InitRetries: 3},
+			failures: 0,
+		},
+		{
+			name:       "Succeeds after transient failures",
+			config:     Config{InitRetries: 3, InitRetryDelay: 2},
+			failures:   2,
+			wantSleeps: []time.Duration{2 * time.Second, 4 * time.Second},
+		},
+		{
+			name:       "Default delay",
+			config:     Config{InitRetries: 1},
+			failures:   1,
+			wantSleeps: []time.Duration{time.Second},
+		},
+		{
+			name:       "Gives up after retries",
+			config:     Config{InitRetries: 2, InitRetryDelay: 1},
+			failures:   5,
+			wantErr:    true,
+			wantSleeps: []time.Duration{time.Second, 2 * time.Second},
+		},
+		{
+			name:     "No retries configured",
+			config:   Config{},
+			failures: 1,
+			wantErr:  true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			attempts := 0
+			open := func(Config) (DisplayDevice, error) {
+				attempts++
+				if attempts <= tt.failures {
+					return nil, fmt.Errorf("failed to open I2C: bus not ready")
+				}
+				return &MockDisplay{}, nil
+			}
+			var sleeps []time.Duration
+			sleep := func(d time.Duration) { sleeps = append(sleeps, d) }
+
+			dev, err := openDisplayWithRetry(tt.config, open, sleep)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatal("Expected an error")
+				}
+				if !strings.Contains(err.Error(), "bus not ready") {
+					t.Errorf("Expected the last open error, got %v", err)
+				}
+				if attempts != tt.config.InitRetries+1 {
+					t.Errorf("Expected %d attempts, got %d", tt.config.InitRetries+1, attempts)
+				}
+			} else if err != nil || dev == nil {
+				t.Fatalf("Expected a device, got %v", err)
+			}
+			if !reflect.DeepEqual(sleeps, tt.wantSleeps) {
+				t.Errorf("Expected sleeps %v, got %v", tt.wantSleeps, sleeps)
+			}
+		})
+	}
+}
+
+// TestLoadJSONConfig tests that a .json config parses to the same Config as
+// the equivalent YAML
+func TestLoadJSONConfig(t *testing.T) {
+	dir := t.TempDir()
+	yamlPath := filepath.Join(dir, "config.yaml")
+	jsonPath := filepath.Join(dir, "config.json")
+	yamlConfig := `
+screen_duration: 5
+network_interface: wlan0
+day_contrast: 200
+temperature_unit: F
+mqtt:
+  broker: tcp://broker:1883
+  interval: 10
+screens:
+  - name: System
+    duration: 8
+    components:
+      - type: cpu
+        x: 5
+        y: 12
+        label: CPU
+        show_bar: true
+        bar_width: 100
+      - type: ip
+        x: 5
+        y: 40
+        label: IP
+        interfaces: [eth0, wlan0]
+        start_hour: 7
+`
+	jsonConfig := `{
+  "screen_duration": 5,
+  "network_interface": "wlan0",
+  "day_contrast": 200,
+  "temperature_unit": "F",
+  "mqtt": {"broker": "tcp://broker:1883", "interval": 10},
+  "screens": [
+    {
+      "name": "System",
+      "duration": 8,
+      "components": [
+        {"type": "cpu", "x": 5, "y": 12, "label": "CPU", "show_bar": true, "bar_width": 100},
+        {"type": "ip", "x": 5, "y": 40, "label": "IP", "interfaces": ["eth0", "wlan0"], "start_hour": 7}
+      ]
+    }
+  ]
+}`
+	if err := os.WriteFile(yamlPath, []byte(yamlConfig), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(jsonPath, []byte(jsonConfig), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	fromYAML, err := loadConfig(yamlPath)
+	if err != nil {
+		t.Fatalf("Failed to load YAML config: %v", err)
+	}
+	fromJSON, err := loadConfig(jsonPath)
+	if err != nil {
+		t.Fatalf("Failed to load JSON config: %v", err)
+	}
+	if !reflect.DeepEqual(fromYAML, fromJSON) {
+		t.Errorf("Expected JSON config to match YAML config\nyaml: %+v\njson: %+v", fromYAML, fromJSON)
+	}
+
+	// JSON syntax errors are reported like YAML ones
+	if err := os.WriteFile(jsonPath, []byte(`{"screen_duration": 5,`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := loadConfig(jsonPath); err == nil || !strings.Contains(err.Error(), "error parsing config file") {
+		t.Errorf("Expected a parse error, got %v", err)
+	}
+}
+
+// MockCommandRunner implements CommandRunner for testing
+type MockCommandRunner struct {
+	output   []byte
+	err      error
+	calls    []string
+	deadline time.Time
+}
+
+func (m *MockCommandRunner) Output(ctx context.Context, name string, args ...string) ([]byte, error) {
+	m.calls = append(m.calls, strings.Join(append([]string{name}, args...), " "))
+	m.deadline, _ = ctx.Deadline()
+	return m.output, m.err
+}
+
+// TestParseVcgencmdTemp tests parsing real vcgencmd measure_temp output
+func TestParseVcgencmdTemp(t *testing.T) {
+	tests := []struct {
+		output  string
+		want    float64
+		wantErr bool
+	}{
+		{"temp=48.3'C\n", 48.3, false},
+		{"temp=61.0'C", 61.0, false},
+		{"temp=38'C\n", 38, false},
+		{"temp=-2.5'C\n", -2.5, false},
+		{"VCHI initialization failed\n", 0, true},
+		{"temp=hot'C\n", 0, true},
+		{"", 0, true},
+	}
+
+	for _, tt := range tests {
+		got, err := parseVcgencmdTemp(tt.output)
+		if tt.wantErr {
+			if err == nil {
+				t.Errorf("%q: expected an error, got %v", tt.output, got)
+			}
+			continue
+		}
+		if err != nil || got != tt.want {
+			t.Errorf("%q: expected %v, got %v (err %v)", tt.output, tt.want, got, err)
+		}
+	}
+}
+
+// TestGPUTempComponent tests rendering the vcgencmd temperature
+func TestGPUTempComponent(t *testing.T) {
+	tests := []struct {
+		name      string
+		runner    *MockCommandRunner
+		unit      string
+		wantLabel string
+		wantBar   float64
+	}{
+		{"Celsius", &MockCommandRunner{output: []byte("temp=48.3'C\n")}, "", "GPU: 48.3 C", 0.483},
+		{"Fahrenheit", &MockCommandRunner{output: []byte("temp=50.0'C\n")}, "F", "GPU: 122.0 F", 0.5},
+		{"Not a Pi", &MockCommandRunner{err: exec.ErrNotFound}, "", "GPU: N/A", -1},
+		{"Command fails", &MockCommandRunner{err: fmt.Errorf("exit status 255")}, "", "GPU: N/A", -1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dm := &DisplayManager{
+				commandRunner: tt.runner,
+				config:        Config{TemperatureUnit: tt.unit},
+				img:           blankFrame(),
+				timeNow:       time.Now,
+				asyncFunc:     func(f func()) { f() },
+			}
+			comp := Component{Type: "gputemp", X: 5, Y: 12, ShowBar: true, BarWidth: 100}
+			if err := dm.renderComponent(comp); err != nil {
+				t.Fatalf("Failed to render component: %v", err)
+			}
+			if len(tt.runner.calls) != 1 || tt.runner.calls[0] != "vcgencmd measure_temp" {
+				t.Errorf("Expected one vcgencmd measure_temp call, got %v", tt.runner.calls)
+			}
+			if tt.runner.deadline.IsZero() {
+				t.Error("Expected vcgencmd to run with a timeout")
+			}
+
+			// The reading is reused until the refresh interval passes
+			dm.clearImage()
+			if err := dm.renderComponent(comp); err != nil {
+				t.Fatalf("Failed to render component: %v", err)
+			}
+			if len(tt.runner.calls) != 1 {
+				t.Errorf("Expected vcgencmd not to run again within %v, got %d calls", defaultGPUTempRefresh, len(tt.runner.calls))
+			}
+
+			want := labelImage(5, 12, tt.wantLabel)
+			if tt.wantBar >= 0 {
+				render.DrawBar(want, 5, 17, 100, render.BarHeight, tt.wantBar, 1)
+			}
+			if !bytes.Equal(dm.img.Pix, want.Pix) {
+				t.Errorf("Rendered image does not match %q", tt.wantLabel)
+			}
+		})
+	}
+}
+
+// TestGPUTempDoesNotBlock tests that vcgencmd runs in the background, with the
+// placeholder shown until the first reading finishes
+func TestGPUTempDoesNotBlock(t *testing.T) {
+	var pending []func()
+	runner := &MockCommandRunner{output: []byte("temp=48.3'C\n")}
+	dm := &DisplayManager{
+		commandRunner: runner,
+		asyncFunc:     func(f func()) { pending = append(pending, f) },
+		img:           blankFrame(),
+		timeNow:       time.Now,
+	}
+	comp := Component{Type: "gputemp", X: 5, Y: 12}
+	if err := dm.renderComponent(comp); err != nil {
+		t.Fatalf("Failed to render component: %v", err)
+	}
+	if len(runner.calls) != 0 || len(pending) != 1 {
+		t.Fatalf("Expected vcgencmd to be queued, not run, got %d calls", len(runner.calls))
+	}
+	if want := labelImage(5, 12, "GPU: N/A"); !bytes.Equal(dm.img.Pix, want.Pix) {
+		t.Error("Expected the placeholder while the first reading runs")
+	}
+
+	pending[0]()
+	dm.clearImage()
+	if err := dm.renderComponent(comp); err != nil {
+		t.Fatalf("Failed to render component: %v", err)
+	}
+	if want := labelImage(5, 12, "GPU: 48.3 C"); !bytes.Equal(dm.img.Pix, want.Pix) {
+		t.Error("Expected the temperature once the reading finished")
+	}
+}
+
+// TestAlertBlink tests that a component past an alert threshold is drawn on
+// one update and skipped on the next, while one inside its thresholds always is
+func TestAlertBlink(t *testing.T) {
+	above, below := 90.0, 20.0
+	tests := []struct {
+		name      string
+		percent   float64
+		wantLabel string
+		blinks    bool
+	}{
+		{"Above alert_above", 95, "Bat: 95%", true},
+		{"Below alert_below", 10, "Bat: 10%", true},
+		{"Between thresholds", 50, "Bat: 50%", false},
+		{"At threshold", 90, "Bat: 90%", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dm := &DisplayManager{
+				batteryReader: &MockBatteryReader{state: batteryState{percent: tt.percent}, present: true},
+				img:           blankFrame(),
+			}
+			comp := Component{Type: "battery", X: 5, Y: 12, Label: "Bat", AlertAbove: &above, AlertBelow: &below}
+			want := labelImage(5, 12, tt.wantLabel)
+			blank := blankFrame()
+
+			for frame := 0; frame < 4; frame++ {
+				dm.clearImage()
+				if err := dm.renderComponent(comp); err != nil {
+					t.Fatalf("Failed to render component: %v", err)
+				}
+				expected := want
+				if tt.blinks && frame%2 == 1 {
+					expected = blank
+				}
+				if !bytes.Equal(dm.img.Pix, expected.Pix) {
+					t.Errorf("Frame %d: expected drawn=%v", frame, expected == want)
+				}
+				dm.blinkOff = !dm.blinkOff
+			}
+		})
+	}
+}
+
+// TestDangerBar tests that bar fill past danger_threshold is hatched while the
+// fill below it stays solid
+func TestDangerBar(t *testing.T) {
+	threshold := 50.0
+	dm := &DisplayManager{img: blankFrame()}
+	comp := Component{Type: "cpu", X: 5, Y: 12, BarWidth: 102, DangerThreshold: &threshold}
+	dm.drawComponentBar(comp, 0.9)
+
+	// The bar's inside spans x 6..105 and y 18..22
+	lit := func(x0, x1 int) (lit, total int) {
+		for y := 18; y <= 22; y++ {
+			for x := x0; x <= x1; x++ {
+				total++
+				if dm.img.RGBAAt(x, y).R != 0 {
+					lit++
+				}
+			}
+		}
+		return lit, total
+	}
+	if got, total := lit(6, 55); got != total {
+		t.Errorf("Expected the fill below the threshold to be solid, got %d of %d pixels lit", got, total)
+	}
+	got, total := lit(56, 95)
+	if got == 0 || got == total {
+		t.Errorf("Expected the fill past the threshold to be hatched, got %d of %d pixels lit", got, total)
+	}
+	if got, _ := lit(96, 105); got != 0 {
+		t.Errorf("Expected no fill past 90%%, got %d pixels lit", got)
+	}
+
+	// Without a threshold the same fill is solid throughout
+	solid := blankFrame()
+	render.DrawBar(solid, 5, 17, 102, render.BarHeight, 0.9, 1)
+	if bytes.Equal(solid.Pix, dm.img.Pix) {
+		t.Error("Expected the danger bar to differ from a solid bar")
+	}
+
+	// Vertical bars hatch the top of their fill
+	vertical := blankFrame()
+	render.DrawVBar(vertical, 10, 10, render.BarHeight, 22, 1, 0.5)
+	if vertical.RGBAAt(12, 30).R == 0 {
+		t.Error("Expected the bottom of a vertical bar to be solid")
+	}
+	gaps := 0
+	for y := 11; y < 20; y++ {
+		for x := 11; x < 16; x++ {
+			if vertical.RGBAAt(x, y).R == 0 {
+				gaps++
+			}
+		}
+	}
+	if gaps == 0 {
+		t.Error("Expected the top of a vertical bar to be hatched")
+	}
+}
+
+// MockHostInfoReader implements HostInfoReader for testing
+type MockHostInfoReader struct {
+	info  hostInfo
+	calls int
+}
+
+func (m *MockHostInfoReader) HostInfo() (hostInfo, error) {
+	m.calls++
+	return m.info, nil
+}
+
+// TestHostnameComponent tests rendering the selected host details
+func TestHostnameComponent(t *testing.T) {
+	info := hostInfo{hostname: "rpi4", os: "linux", kernel: "6.1.21-v8+", platform: "debian 12.1"}
+	tests := []struct {
+		name      string
+		comp      Component
+		wantLabel string
+	}{
+		{"Default", Component{Type: "hostname", X: 5, Y: 12}, "rpi4"},
+		{"With label", Component{Type: "hostname", X: 5, Y: 12, Label: "Host"}, "Host: rpi4"},
+		{"OS and kernel", Component{Type: "hostname", X: 5, Y: 12, Fields: []string{"hostname", "os", "kernel"}}, "rpi4 Linux 6.1"},
+		{"Platform only", Component{Type: "hostname", X: 5, Y: 12, Fields: []string{"platform"}}, "debian 12.1"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			reader := &MockHostInfoReader{info: info}
+			dm := &DisplayManager{
+				hostReader: reader,
+				img:        blankFrame(),
+			}
+			for i := 0; i < 3; i++ {
+				dm.clearImage()
+				if err := dm.renderComponent(tt.comp); err != nil {
+					t.Fatalf("Failed to render component: %v", err)
+				}
+			}
+			if want := labelImage(5, 12, tt.wantLabel); !bytes.Equal(dm.img.Pix, want.Pix) {
+				t.Errorf("Rendered image does not match %q", tt.wantLabel)
+			}
+			if reader.calls != 1 {
+				t.Errorf("Expected host info to be read once, got %d reads", reader.calls)
+			}
+		})
+	}
+}
+
+// TestFormatHostInfoKernel tests shortening kernel versions to major.minor
+func TestFormatHostInfoKernel(t *testing.T) {
+	for kernel, want := range map[string]string{
+		"6.1.21-v8+":        "6.1",
+		"6.6-rc2":           "6.6",
+		"5.15.0-1034-raspi": "5.15",
+		"unknown":           "unknown",
+	} {
+		if got := formatHostInfo(hostInfo{kernel: kernel}, []string{"kernel"}); got != want {
+			t.Errorf("%s: expected %q, got %q", kernel, want, got)
+		}
+	}
+}
+
+// TestRotate180 tests turning frames upside down for panels mounted that way
+func TestRotate180(t *testing.T) {
+	img := labelImage(5, 12, "Upside")
+	img.Set(0, 0, color.White)
+
+	rotated := render.Rotate(img, 180)
+	if rotated.RGBAAt(width-1, height-1).R == 0 {
+		t.Error("Expected the top-left pixel to move to the bottom-right")
+	}
+	if rotated.RGBAAt(0, 0).R != 0 {
+		t.Error("Expected the bottom-right pixel to move to the top-left")
+	}
+	if again := render.Rotate(rotated, 180); !bytes.Equal(again.Pix, img.Pix) {
+		t.Error("Expected rotating twice to return the original image")
+	}
+
+	// Frames sent to the panel are rotated, the frame buffer isn't
+	mockDisplay := NewMockDisplay(t)
+	dm := &DisplayManager{
+		dev:     mockDisplay,
+		img:     blankFrame(),
+		timeNow: time.Now,
+		config: Config{
+			Rotate180: true,
+			Screens:   []Screen{{Name: "A", Components: []Component{{Type: "text", X: 5, Y: 12, Text: "Upside"}}}},
+		},
+	}
+	if err := dm.renderCurrentScreen(); err != nil {
+		t.Fatalf("Failed to render screen: %v", err)
+	}
+	want := labelImage(5, 12, "Upside")
+	if !bytes.Equal(dm.img.Pix, want.Pix) {
+		t.Error("Expected the frame buffer to stay upright")
+	}
+	if !bytes.Equal(mockDisplay.lastImage.Pix, render.Rotate(want, 180).Pix) {
+		t.Error("Expected the panel to receive the rotated frame")
+	}
+}
+
+// TestPortraitRotation tests that a portrait layout is drawn upright and sent
+// to the panel as a landscape frame
+func TestPortraitRotation(t *testing.T) {
+	config := Config{
+		ScreenDuration:  5,
+		DisplayRotation: 90,
+		Screens:         []Screen{{Name: "A", Components: []Component{{Type: "text", X: 2, Y: 100, Text: "Tall"}}}},
+	}
+	if w, h := config.logicalSize(); w != height || h != width {
+		t.Fatalf("Expected a %dx%d layout, got %dx%d", height, width, w, h)
+	}
+
+	mockDisplay := NewMockDisplay(t)
+	dm := &DisplayManager{
+		dev:     mockDisplay,
+		img:     image.NewRGBA(image.Rect(0, 0, height, width)),
+		timeNow: time.Now,
+		config:  config,
+	}
+	if err := dm.renderCurrentScreen(); err != nil {
+		t.Fatalf("Failed to render screen: %v", err)
+	}
+	if got := mockDisplay.lastImage.Bounds().Size(); got != image.Pt(width, height) {
+		t.Fatalf("Expected the panel to receive a %dx%d frame, got %v", width, height, got)
+	}
+	if !bytes.Equal(mockDisplay.lastImage.Pix, render.Rotate(dm.img, 90).Pix) {
+		t.Error("Expected the panel to receive the rotated frame")
+	}
+}
+
+// TestDisabledScreens tests that a disabled middle screen is skipped in the
+// rotation and the order is renumbered around it
+func TestDisabledScreens(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	yamlConfig := `
+screen_duration: 5
+order: [2, 1, 0, 2]
+screens:
+  - name: A
+    components: [{type: text, x: 5, y: 20, text: A}]
+  - name: B
+    enabled: false
+    components: [{type: text, x: 5, y: 20, text: B}]
+  - name: C
+    enabled: true
+    components: [{type: text, x: 5, y: 20, text: C}]
+`
+	if err := os.WriteFile(path, []byte(yamlConfig), 0644); err != nil {
+		t.Fatal(err)
+	}
+	config, err := loadConfig(path)
+	if err != nil {
+		t.Fatalf("Failed to load config: %v", err)
+	}
+
+	var names []string
+	for _, screen := range config.Screens {
+		names = append(names, screen.Name)
+	}
+	if want := []string{"A", "C"}; !reflect.DeepEqual(names, want) {
+		t.Fatalf("Expected screens %v, got %v", want, names)
+	}
+	if want := []int{1, 0, 1}; !reflect.DeepEqual(config.Order, want) {
+		t.Errorf("Expected order %v, got %v", want, config.Order)
+	}
+
+	config.Order = nil
+	dm := &DisplayManager{config: config}
+	var shown []string
+	for i := 0; i < 4; i++ {
+		shown = append(shown, config.Screens[dm.currentScreen].Name)
+		dm.currentScreen = dm.nextScreen()
+	}
+	if want := []string{"A", "C", "A", "C"}; !reflect.DeepEqual(shown, want) {
+		t.Errorf("Expected rotation %v, got %v", want, shown)
+	}
+
+	// Every screen disabled fails validation
+	disabled := false
+	config = Config{ScreenDuration: 5, Screens: []Screen{{Name: "A", Enabled: &disabled}}}
+	if err := validateConfig(config); err == nil || !strings.Contains(err.Error(), "at least one screen must be enabled") {
+		t.Errorf("Expected an error about disabled screens, got %v", err)
+	}
+}
+
+// TestDisabledComponent tests that a disabled component isn't drawn
+func TestDisabledComponent(t *testing.T) {
+	disabled := false
+	config := Config{
+		ScreenDuration: 5,
+		Screens: []Screen{{Name: "A", Components: []Component{
+			{Type: "text", X: 5, Y: 20, Text: "Shown"},
+			{Type: "text", X: 5, Y: 40, Text: "Hidden", Enabled: &disabled},
+		}}},
+	}
+	if err := validateConfig(config); err != nil {
+		t.Fatalf("Expected a valid config, got %v", err)
+	}
+
+	dm := &DisplayManager{
+		img:     blankFrame(),
+		timeNow: time.Now,
+		config:  config.withoutDisabled(),
+	}
+	dm.renderFrame()
+	if want := labelImage(5, 20, "Shown"); !bytes.Equal(dm.img.Pix, want.Pix) {
+		t.Error("Expected only the enabled component to be drawn")
+	}
+}
+
+// MockFanReader implements FanReader for testing
+type MockFanReader struct {
+	rpm     int
+	present bool
+	path    string
+}
+
+func (m *MockFanReader) FanRPM(path string) (int, bool, error) {
+	m.path = path
+	return m.rpm, m.present, nil
+}
+
+// TestParseFanRPM tests parsing hwmon fan input contents
+func TestParseFanRPM(t *testing.T) {
+	tests := []struct {
+		data    string
+		want    int
+		wantErr bool
+	}{
+		{"2400\n", 2400, false},
+		{"0\n", 0, false},
+		{"  1375 \n", 1375, false},
+		{"12000", 12000, false},
+		{"", 0, true},
+		{"-1\n", 0, true},
+		{"2400.5\n", 0, true},
+		{"N/A\n", 0, true},
+	}
+
+	for _, tt := range tests {
+		got, err := parseFanRPM(tt.data)
+		if tt.wantErr {
+			if err == nil {
+				t.Errorf("%q: expected an error, got %v", tt.data, got)
+			}
+			continue
+		}
+		if err != nil || got != tt.want {
+			t.Errorf("%q: expected %v, got %v (err %v)", tt.data, tt.want, got, err)
+		}
+	}
+}
+
+// TestRealFanReader tests reading a hwmon fan input file
+func TestRealFanReader(t *testing.T) {
+	dir := t.TempDir()
+	input := filepath.Join(dir, "fan1_input")
+	if err := os.WriteFile(input, []byte("2400\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	reader := &RealFanReader{}
+	if rpm, present, err := reader.FanRPM(input); err != nil || !present || rpm != 2400 {
+		t.Errorf("Expected 2400 rpm, got %d present=%v err=%v", rpm, present, err)
+	}
+	if _, present, err := reader.FanRPM(filepath.Join(dir, "fan2_input")); err != nil || present {
+		t.Errorf("Missing file: expected no fan and no error, got present=%v err=%v", present, err)
+	}
+}
+
+// TestFanComponent tests rendering the fan speed and its bar
+func TestFanComponent(t *testing.T) {
+	tests := []struct {
+		name      string
+		reader    *MockFanReader
+		maxRPM    int
+		wantLabel string
+		wantBar   float64
+	}{
+		{"No fan", &MockFanReader{}, 0, "Fan: N/A", -1},
+		{"Spinning", &MockFanReader{rpm: 2400, present: true}, 0, "Fan: 2400 rpm", -1},
+		{"Stopped", &MockFanReader{rpm: 0, present: true}, 0, "Fan: 0 rpm", -1},
+		{"Bar", &MockFanReader{rpm: 2400, present: true}, 4800, "Fan: 2400 rpm", 0.5},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dm := &DisplayManager{
+				fanReader: tt.reader,
+				img:       blankFrame(),
+			}
+			comp := Component{Type: "fan", X: 5, Y: 12, Source: "/sys/class/hwmon/hwmon2/fan1_input", MaxRPM: tt.maxRPM, ShowBar: tt.maxRPM > 0, BarWidth: 100}
+			if err := dm.renderComponent(comp); err != nil {
+				t.Fatalf("Failed to render component: %v", err)
+			}
+			if tt.reader.path != comp.Source {
+				t.Errorf("Expected the reader to get %q, got %q", comp.Source, tt.reader.path)
+			}
+
+			want := labelImage(5, 12, tt.wantLabel)
+			if tt.wantBar >= 0 {
+				render.DrawBar(want, 5, 17, 100, render.BarHeight, tt.wantBar, 1)
+			}
+			if !bytes.Equal(dm.img.Pix, want.Pix) {
+				t.Errorf("Rendered image does not match %q", tt.wantLabel)
+			}
+		})
+	}
+}
+
+// TestExecComponent tests rendering a shell command's output
+func TestExecComponent(t *testing.T) {
+	tests := []struct {
+		name      string
+		runner    *MockCommandRunner
+		label     string
+		wantLabel string
+	}{
+		{"Success", &MockCommandRunner{output: []byte("  412 W\n")}, "Solar", "Solar: 412 W"},
+		{"No label", &MockCommandRunner{output: []byte("ok\nmore lines\n")}, "", "ok"},
+		{"Non-zero exit", &MockCommandRunner{output: []byte("partial"), err: fmt.Errorf("exit status 1")}, "Solar", "Solar: ERR"},
+		{"Timeout", &MockCommandRunner{err: context.DeadlineExceeded}, "Solar", "Solar: ERR"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			now := time.Now()
+			dm := &DisplayManager{
+				commandRunner: tt.runner,
+				asyncFunc:     func(f func()) { f() },
+				img:           blankFrame(),
+				timeNow:       func() time.Time { return now },
+			}
+			comp := Component{Type: "exec", X: 5, Y: 12, Label: tt.label, Command: "solar-watts --now", Timeout: 1}
+			start := time.Now()
+			if err := dm.renderComponent(comp); err != nil {
+				t.Fatalf("Failed to render component: %v", err)
+			}
+			if want := labelImage(5, 12, tt.wantLabel); !bytes.Equal(dm.img.Pix, want.Pix) {
+				t.Errorf("Rendered image does not match %q", tt.wantLabel)
+			}
+			if want := []string{"sh -c solar-watts --now"}; !reflect.DeepEqual(tt.runner.calls, want) {
+				t.Errorf("Expected calls %v, got %v", want, tt.runner.calls)
+			}
+			if limit := tt.runner.deadline.Sub(start); limit < time.Second || limit > 2*time.Second {
+				t.Errorf("Expected the command to be limited to 1s, got %v", limit)
+			}
+
+			// The result is reused until the default refresh interval passes
+			dm.clearImage()
+			now = now.Add(defaultExecRefresh - time.Second)
+			if err := dm.renderComponent(comp); err != nil {
+				t.Fatalf("Failed to render component: %v", err)
+			}
+			if len(tt.runner.calls) != 1 {
+				t.Errorf("Expected the cached output to be reused, got %d runs", len(tt.runner.calls))
+			}
+			now = now.Add(time.Second)
+			if err := dm.renderComponent(comp); err != nil {
+				t.Fatalf("Failed to render component: %v", err)
+			}
+			if len(tt.runner.calls) != 2 {
+				t.Errorf("Expected the command to run again after the refresh interval, got %d runs", len(tt.runner.calls))
+			}
+		})
+	}
+}
+
+// TestExecDoesNotBlock tests that the command runs in the background, with
+// the placeholder shown until its first run finishes, and that stale_after
+// keeps dimmed output after a failed run
+func TestExecDoesNotBlock(t *testing.T) {
+	var pending []func()
+	runner := &MockCommandRunner{output: []byte("412 W\n")}
+	now := time.Date(2024, 3, 9, 14, 0, 0, 0, time.Local)
+	dm := &DisplayManager{
+		commandRunner: runner,
+		asyncFunc:     func(f func()) { pending = append(pending, f) },
+		img:           blankFrame(),
+		timeNow:       func() time.Time { return now },
+	}
+	comp := Component{Type: "exec", X: 5, Y: 12, Label: "Solar", Command: "solar-watts", StaleAfter: 1}
+	draw := func() {
+		t.Helper()
+		dm.clearImage()
+		if err := dm.renderComponent(comp); err != nil {
+			t.Fatalf("Failed to render component: %v", err)
+		}
+	}
+
+	draw()
+	if len(runner.calls) != 0 || len(pending) != 1 {
+		t.Fatalf("Expected the command to be queued, not run, got %d runs", len(runner.calls))
+	}
+	if want := labelImage(5, 12, "Solar: N/A"); !bytes.Equal(dm.img.Pix, want.Pix) {
+		t.Error("Expected the placeholder while the first run is going")
+	}
+	pending[0]()
+	pending = nil
+	draw()
+	if want := labelImage(5, 12, "Solar: 412 W"); !bytes.Equal(dm.img.Pix, want.Pix) {
+		t.Error("Expected the output once the run finished")
+	}
+
+	// A failed run keeps the last output, dimmed once it is a refresh interval old
+	runner.err = fmt.Errorf("exit status 1")
+	now = now.Add(defaultExecRefresh)
+	draw()
+	pending[0]()
+	now = now.Add(time.Second)
+	draw()
+	want := blankFrame()
+	render.AddDimLabel(want, basicfont.Face7x13, 5, 12, "Solar: 412 W")
+	if !bytes.Equal(dm.img.Pix, want.Pix) {
+		t.Error("Expected the last output dimmed after a failed run")
+	}
+}
+
+// TestRealCommandRunnerTimeout tests that a command still running at its
+// deadline is killed
+func TestRealCommandRunnerTimeout(t *testing.T) {
+	if _, err := exec.LookPath("sleep"); err != nil {
+		t.Skip("sleep not available")
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+
+	start := time.Now()
+	if _, err := (&RealCommandRunner{}).Output(ctx, "sleep", "5"); err == nil {
+		t.Error("Expected an error from a killed command")
+	}
+	if elapsed := time.Since(start); elapsed > 3*time.Second {
+		t.Errorf("Expected the command to be killed at its deadline, took %v", elapsed)
+	}
+}
+
+// TestTemperatureTrend tests the arrow drawn after a temperature for rising,
+// falling and steady readings
+func TestTemperatureTrend(t *testing.T) {
+	reader := &MockTemperatureReader{files: map[string]float64{tempFile: 45.2}}
+	dm := &DisplayManager{
+		metricsSource: &MockMetricsProvider{temps: reader},
+		img:           blankFrame(),
+	}
+	comp := Component{Type: "temperature", X: 5, Y: 12, Label: "CPU", Trend: true}
+
+	steps := []struct {
+		temp      float64
+		wantLabel string
+	}{
+		{45.2, "CPU: 45.2 C"}, // nothing to compare the first reading with
+		{46.0, "CPU: 46.0 C ^"},
+		{47.5, "CPU: 47.5 C ^"},
+		{47.6, "CPU: 47.6 C -"}, // inside the dead band
+		{47.5, "CPU: 47.5 C -"},
+		{46.1, "CPU: 46.1 C v"},
+		{46.1, "CPU: 46.1 C -"},
+	}
+	for i, step := range steps {
+		reader.files[tempFile] = step.temp
+		dm.clearImage()
+		if err := dm.renderComponent(comp); err != nil {
+			t.Fatalf("Step %d: failed to render component: %v", i, err)
+		}
+		if want := labelImage(5, 12, step.wantLabel); !bytes.Equal(dm.img.Pix, want.Pix) {
+			t.Errorf("Step %d: rendered image does not match %q", i, step.wantLabel)
+		}
+	}
+
+	// Each component keeps its own previous reading
+	other := Component{Type: "temperature", X: 5, Y: 40, Label: "CPU", Trend: true}
+	dm.clearImage()
+	if err := dm.renderComponent(other); err != nil {
+		t.Fatalf("Failed to render component: %v", err)
+	}
+	if want := labelImage(5, 40, "CPU: 46.1 C"); !bytes.Equal(dm.img.Pix, want.Pix) {
+		t.Error("Expected no trend for a component's first reading")
+	}
+}
+
+// MockTicker implements updateTicker with a channel the test fires
+type MockTicker struct {
+	c chan time.Time
+}
+
+func (m *MockTicker) Chan() <-chan time.Time {
+	return m.c
+}
+
+func (m *MockTicker) Reset(d time.Duration) {}
+
+func (m *MockTicker) Stop() {}
+
+// TestUpdateInterval tests that the update ticker runs at update_interval and
+// each tick redraws the current screen
+func TestUpdateInterval(t *testing.T) {
+	tests := []struct {
+		name     string
+		interval int
+		want     time.Duration
+	}{
+		{"Default", 0, time.Second},
+		{"Slower", 5, 5 * time.Second},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ticker := &MockTicker{c: make(chan time.Time)}
+			var tickerInterval time.Duration
+			// The clock moves on every time it is read, so each render shows a new time
+			start := time.Date(2024, 1, 1, 12, 0, 0, 0, time.Local)
+			var reads atomic.Int64
+			mockDisplay := NewMockDisplay(t)
+			dm := &DisplayManager{
+				dev:     mockDisplay,
+				img:     blankFrame(),
+				timeNow: func() time.Time { return start.Add(time.Duration(reads.Add(1)) * time.Second) },
+				newTimer: func(d time.Duration) screenTimer {
+					return &MockTimer{c: make(chan time.Time), resets: make(chan time.Duration, 1)}
+				},
+				newTicker: func(d time.Duration) updateTicker {
+					tickerInterval = d
+					return ticker
+				},
+				config: Config{
+					ScreenDuration: 60,
+					UpdateInterval: tt.interval,
+					Screens:        []Screen{{Name: "Clock", Components: []Component{{Type: "time", X: 5, Y: 20}}}},
+				},
+			}
+
+			ctx, cancel := context.WithCancel(context.Background())
+			done := make(chan error)
+			go func() {
+				done <- dm.Run(ctx)
+			}()
+
+			// A received tick is handled before Run checks for cancellation
+			for i := 0; i < 3; i++ {
+				ticker.c <- time.Now()
+			}
+			cancel()
+			if err := <-done; err != nil {
+				t.Fatalf("Run failed: %v", err)
+			}
+
+			if tickerInterval != tt.want {
+				t.Errorf("Expected a %v ticker, got %v", tt.want, tickerInterval)
+			}
+			// The initial frame, one per tick and the blank frame on shutdown
+			if mockDisplay.drawCount != 5 {
+				t.Errorf("Expected 5 draws, got %d", mockDisplay.drawCount)
+			}
+		})
+	}
+}
+
+// TestComponentLabels tests that data components prefix their value with
+// "label: " and show the bare value without a label
+func TestComponentLabels(t *testing.T) {
+	tests := []struct {
+		comp Component
+		want string
+	}{
+		{Component{Type: "ip"}, "192.168.1.100"},
+		{Component{Type: "cpu"}, "12.5%"},
+		{Component{Type: "memory"}, "40.0%"},
+		{Component{Type: "swap"}, "25.0%"},
+		{Component{Type: "temperature"}, "48.0 C"},
+		{Component{Type: "battery"}, "80%"},
+		{Component{Type: "loadavg"}, "0.42 0.31 0.28"},
+		{Component{Type: "uptime"}, "up 1h"},
+		{Component{Type: "processes"}, "3"},
+		{Component{Type: "time", TimeFormat: "15:04"}, "12:30"},
+		{Component{Type: "date", TimeFormat: "Jan 2"}, "Mar 4"},
+	}
+
+	for _, tt := range tests {
+		for _, label := range []string{"", "Val"} {
+			name := tt.comp.Type + " without label"
+			want := tt.want
+			if label != "" {
+				name = tt.comp.Type + " with label"
+				want = label + ": " + tt.want
+			}
+			t.Run(name, func(t *testing.T) {
+				dm := &DisplayManager{
+					networkChecker: &MockNetworkChecker{ipAddress: "192.168.1.100"},
+					metricsSource: &MockMetricsProvider{
+						cpu:    12.5,
+						memory: 40,
+						temps:  &MockTemperatureReader{files: map[string]float64{tempFile: 48}},
+					},
+					swapReader:    &MockSwapReader{swap: &mem.SwapMemoryStat{Total: 1000, Used: 250, UsedPercent: 25}},
+					batteryReader: &MockBatteryReader{state: batteryState{percent: 80}, present: true},
+					loadReader:    &MockLoadReader{avg: &load.AvgStat{Load1: 0.42, Load5: 0.31, Load15: 0.28}, cores: 4},
+					uptimeReader:  &MockUptimeReader{seconds: 3600},
+					processLister: &MockProcessLister{pids: []int32{1, 2, 3}},
+					timeNow:       func() time.Time { return time.Date(2024, 3, 4, 12, 30, 0, 0, time.Local) },
+					img:           blankFrame(),
+				}
+				comp := tt.comp
+				comp.X, comp.Y, comp.Label = 5, 20, label
+				if err := dm.renderComponent(comp); err != nil {
+					t.Fatalf("Failed to render component: %v", err)
+				}
+				if !bytes.Equal(dm.img.Pix, labelImage(5, 20, want).Pix) {
+					t.Errorf("Rendered image does not match %q", want)
+				}
+			})
+		}
+	}
+}
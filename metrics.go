@@ -0,0 +1,100 @@
+package main
+
+import (
+	"net/http"
+	"sync"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+// displayMetrics exports the values the render loop draws as Prometheus
+// gauges, so scrapes never sample gopsutil a second time. Gauges only update
+// while a screen showing them is rendered. A nil *displayMetrics records nothing.
+type displayMetrics struct {
+	registry    *prometheus.Registry
+	cpu         prometheus.Gauge
+	memory      prometheus.Gauge
+	disk        *prometheus.GaugeVec
+	temperature *prometheus.GaugeVec
+
+	// The last CPU reading is also kept for MQTT, which runs on its own
+	// goroutine and can't sample CPU itself without moving the render loop's
+	// baseline
+	mu      sync.Mutex
+	lastCPU float64
+	hasCPU  bool
+}
+
+// newDisplayMetrics creates the gauges in their own registry
+func newDisplayMetrics() *displayMetrics {
+	m := &displayMetrics{
+		registry: prometheus.NewRegistry(),
+		cpu: prometheus.NewGauge(prometheus.GaugeOpts{
+			Name: "monitor_cpu_usage_percent",
+			Help: "CPU usage last shown on the display.",
+		}),
+		memory: prometheus.NewGauge(prometheus.GaugeOpts{
+			Name: "monitor_memory_usage_percent",
+			Help: "Memory usage last shown on the display.",
+		}),
+		disk: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Name: "monitor_disk_usage_percent",
+			Help: "Disk usage last shown on the display, by mountpoint.",
+		}, []string{"mountpoint"}),
+		temperature: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Name: "monitor_temperature_celsius",
+			Help: "Temperature last shown on the display, by sensor key or file.",
+		}, []string{"sensor"}),
+	}
+	m.registry.MustRegister(m.cpu, m.memory, m.disk, m.temperature)
+	return m
+}
+
+// handler serves the registry on /metrics in the Prometheus text format
+func (m *displayMetrics) handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.Handle("/metrics", promhttp.HandlerFor(m.registry, promhttp.HandlerOpts{}))
+	return mux
+}
+
+// SetCPU records the CPU usage shown, for both the gauge and MQTT
+func (m *displayMetrics) SetCPU(percent float64) {
+	if m != nil {
+		m.cpu.Set(percent)
+		m.mu.Lock()
+		m.lastCPU, m.hasCPU = percent, true
+		m.mu.Unlock()
+	}
+}
+
+// cpuPercent returns the CPU usage last shown, with ok false before any has been
+func (m *displayMetrics) cpuPercent() (percent float64, ok bool) {
+	if m == nil {
+		return 0, false
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.lastCPU, m.hasCPU
+}
+
+// SetMemory records the memory usage shown
+func (m *displayMetrics) SetMemory(percent float64) {
+	if m != nil {
+		m.memory.Set(percent)
+	}
+}
+
+// SetDisk records the usage shown for a mountpoint
+func (m *displayMetrics) SetDisk(mountpoint string, percent float64) {
+	if m != nil {
+		m.disk.WithLabelValues(mountpoint).Set(percent)
+	}
+}
+
+// SetTemperature records the temperature shown for a sensor key or file
+func (m *displayMetrics) SetTemperature(sensor string, celsius float64) {
+	if m != nil {
+		m.temperature.WithLabelValues(sensor).Set(celsius)
+	}
+}
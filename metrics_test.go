@@ -0,0 +1,41 @@
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+)
+
+// TestMetricsFromRender tests that rendered values are exported without resampling
+func TestMetricsFromRender(t *testing.T) {
+	dm := &DisplayManager{
+		metricsSource: &MockMetricsProvider{temps: &MockTemperatureReader{files: map[string]float64{tempFile: 48.5}}},
+		img:           blankFrame(),
+		metrics:       newDisplayMetrics(),
+	}
+	if err := dm.renderComponent(Component{Type: "temperature", X: 5, Y: 12, Label: "Temp"}); err != nil {
+		t.Fatalf("Failed to render component: %v", err)
+	}
+	dm.metrics.SetDisk("/", 61.5)
+
+	if got := testutil.ToFloat64(dm.metrics.temperature.WithLabelValues(tempFile)); got != 48.5 {
+		t.Errorf("Expected temperature 48.5, got %v", got)
+	}
+
+	rec := httptest.NewRecorder()
+	dm.metrics.handler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	body := rec.Body.String()
+	for _, want := range []string{
+		`monitor_temperature_celsius{sensor="` + tempFile + `"} 48.5`,
+		`monitor_disk_usage_percent{mountpoint="/"} 61.5`,
+		"monitor_cpu_usage_percent 0",
+		"monitor_memory_usage_percent 0",
+	} {
+		if !strings.Contains(body, want) {
+			t.Errorf("Expected scrape to contain %q, got:\n%s", want, body)
+		}
+	}
+}
@@ -0,0 +1,49 @@
+package main
+
+import "log/slog"
+
+// currentConfigVersion is the config schema this build reads. Configs without
+// a version are version 1.
+const currentConfigVersion = 2
+
+// migrateConfig upgrades an older config to the current version in place.
+// Version 1 predates per-screen durations and display_rotation, so every
+// screen gets an explicit duration and rotate_180 becomes display_rotation.
+// A newer version is loaded as if it were current, after a warning.
+func migrateConfig(config *Config) {
+	version := config.Version
+	if version == 0 {
+		version = 1
+	}
+	if version > currentConfigVersion {
+		slog.Warn("config version is newer than this program supports, some settings may be ignored",
+			"version", version, "supported", currentConfigVersion)
+		config.Version = currentConfigVersion
+		return
+	}
+
+	if version < 2 {
+		migrateScreenDurations(config.Screens, config.ScreenDuration)
+		for i := range config.Displays {
+			migrateScreenDurations(config.Displays[i].Screens, config.ScreenDuration)
+		}
+		if config.Rotate180 && config.DisplayRotation == 0 {
+			config.Rotate180 = false
+			config.DisplayRotation = 180
+		}
+	}
+	config.Version = currentConfigVersion
+}
+
+// migrateScreenDurations sets the duration of every screen that relies on
+// screen_duration. An invalid screen_duration is left for validation to report.
+func migrateScreenDurations(screens []Screen, duration int) {
+	if duration <= 0 {
+		return
+	}
+	for i := range screens {
+		if screens[i].Duration == 0 {
+			screens[i].Duration = duration
+		}
+	}
+}
@@ -0,0 +1,73 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// TestMigrateV1Config tests that an unversioned config loads with explicit
+// screen durations and rotate_180 turned into display_rotation
+func TestMigrateV1Config(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	v1 := `
+screen_duration: 5
+rotate_180: true
+screens:
+  - name: A
+    components: [{type: text, x: 5, y: 20, text: A}]
+  - name: B
+    duration: 12
+    components: [{type: text, x: 5, y: 20, text: B}]
+`
+	if err := os.WriteFile(path, []byte(v1), 0644); err != nil {
+		t.Fatal(err)
+	}
+	config, err := loadConfig(path)
+	if err != nil {
+		t.Fatalf("Failed to load v1 config: %v", err)
+	}
+
+	if config.Version != currentConfigVersion {
+		t.Errorf("Expected version %d, got %d", currentConfigVersion, config.Version)
+	}
+	if got := config.Screens[0].Duration; got != 5 {
+		t.Errorf("Expected screen A to take screen_duration 5, got %d", got)
+	}
+	if got := config.Screens[1].Duration; got != 12 {
+		t.Errorf("Expected screen B to keep its duration 12, got %d", got)
+	}
+	if config.Rotate180 || config.DisplayRotation != 180 {
+		t.Errorf("Expected rotate_180 to become display_rotation 180, got rotate_180=%v display_rotation=%d", config.Rotate180, config.DisplayRotation)
+	}
+}
+
+// TestMigrateCurrentConfig tests that a current config is left alone
+func TestMigrateCurrentConfig(t *testing.T) {
+	config := Config{Version: currentConfigVersion, ScreenDuration: 5, Rotate180: true, Screens: []Screen{{Name: "A"}}}
+	migrateConfig(&config)
+	if config.Screens[0].Duration != 0 || !config.Rotate180 || config.DisplayRotation != 0 {
+		t.Errorf("Expected a current config to be unchanged, got %+v", config)
+	}
+}
+
+// TestMigrateFutureConfig tests that a newer version is loaded with a warning
+func TestMigrateFutureConfig(t *testing.T) {
+	var logs bytes.Buffer
+	defer slog.SetDefault(slog.Default())
+	slog.SetDefault(slog.New(slog.NewTextHandler(&logs, nil)))
+
+	config := Config{Version: currentConfigVersion + 1, ScreenDuration: 5, Screens: []Screen{{Name: "A"}}}
+	migrateConfig(&config)
+
+	if !strings.Contains(logs.String(), "config version is newer") || !strings.Contains(logs.String(), fmt.Sprintf("version=%d", currentConfigVersion+1)) {
+		t.Errorf("Expected a warning about the newer version, got %q", logs.String())
+	}
+	if config.Screens[0].Duration != 0 {
+		t.Error("Expected a newer config not to be migrated")
+	}
+}
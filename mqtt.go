@@ -0,0 +1,130 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"time"
+
+	mqtt "github.com/eclipse/paho.mqtt.golang"
+)
+
+const (
+	defaultMQTTInterval    = 30 // seconds
+	defaultMQTTTopicPrefix = "oled-monitor"
+	mqttPublishTimeout     = 5 * time.Second
+)
+
+// MQTTConfig configures publishing sensor values to an MQTT broker
+type MQTTConfig struct {
+	Broker      string `yaml:"broker" json:"broker"`             // broker URL such as tcp://homeassistant:1883, disabled when empty
+	TopicPrefix string `yaml:"topic_prefix" json:"topic_prefix"` // values go to <prefix>/cpu etc., defaults to oled-monitor
+	Interval    int    `yaml:"interval" json:"interval"`         // seconds between publishes, defaults to 30
+	ClientID    string `yaml:"client_id" json:"client_id"`       // defaults to the topic prefix
+}
+
+// MetricPublisher interface for sending sensor values to a message broker
+type MetricPublisher interface {
+	Publish(topic, payload string) error
+	Close()
+}
+
+// mqttPublisher implements MetricPublisher using paho
+type mqttPublisher struct {
+	client mqtt.Client
+}
+
+// newMQTTPublisher starts connecting to the broker in the background. paho
+// keeps retrying with backoff, so a broker that is down never blocks the display.
+func newMQTTPublisher(config MQTTConfig) *mqttPublisher {
+	clientID := config.ClientID
+	if clientID == "" {
+		clientID = mqttTopicPrefix(config)
+	}
+	opts := mqtt.NewClientOptions().
+		AddBroker(config.Broker).
+		SetClientID(clientID).
+		SetAutoReconnect(true).
+		SetConnectRetry(true).
+		SetConnectRetryInterval(10 * time.Second).
+		SetMaxReconnectInterval(2 * time.Minute).
+		SetConnectionLostHandler(func(c mqtt.Client, err error) {
+			slog.Warn("lost connection to MQTT broker, reconnecting", "err", err)
+		})
+	client := mqtt.NewClient(opts)
+	client.Connect()
+	return &mqttPublisher{client: client}
+}
+
+// Publish sends a retained value, failing fast while the broker is unreachable
+func (p *mqttPublisher) Publish(topic, payload string) error {
+	if !p.client.IsConnectionOpen() {
+		return fmt.Errorf("not connected to MQTT broker")
+	}
+	token := p.client.Publish(topic, 0, true, payload)
+	if !token.WaitTimeout(mqttPublishTimeout) {
+		return fmt.Errorf("timed out publishing to %s", topic)
+	}
+	return token.Error()
+}
+
+// Close disconnects from the broker
+func (p *mqttPublisher) Close() {
+	p.client.Disconnect(250)
+}
+
+// mqttTopicPrefix returns the configured topic prefix or its default
+func mqttTopicPrefix(config MQTTConfig) string {
+	if config.TopicPrefix == "" {
+		return defaultMQTTTopicPrefix
+	}
+	return config.TopicPrefix
+}
+
+// runMQTT publishes sensor values on the configured interval until ctx is
+// done. It samples independently of the display refresh.
+func (dm *DisplayManager) runMQTT(ctx context.Context, publisher MetricPublisher, config MQTTConfig, networkInterface string) {
+	interval := config.Interval
+	if interval == 0 {
+		interval = defaultMQTTInterval
+	}
+	ticker := time.NewTicker(time.Duration(interval) * time.Second)
+	defer ticker.Stop()
+
+	for {
+		dm.publishMetrics(publisher, mqttTopicPrefix(config), networkInterface)
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// publishMetrics samples each value and publishes it under prefix. CPU usage
+// is the value the display last showed, since sampling it here would reset
+// the display's measurement interval. Values that can't be read or sent are
+// logged and skipped.
+func (dm *DisplayManager) publishMetrics(publisher MetricPublisher, prefix, networkInterface string) {
+	values := make(map[string]string)
+	if cpuPercent, ok := dm.metrics.cpuPercent(); ok {
+		values["cpu"] = fmt.Sprintf("%.1f", cpuPercent)
+	}
+	if memInfo, err := dm.metricsSource.VirtualMemory(); err == nil {
+		values["memory"] = fmt.Sprintf("%.1f", memInfo.UsedPercent)
+	}
+	if usage, err := dm.metricsSource.DiskUsage("/"); err == nil {
+		values["disk"] = fmt.Sprintf("%.1f", usage.UsedPercent)
+	}
+	if temp, err := dm.metricsSource.Temperature("", ""); err == nil {
+		values["temperature"] = fmt.Sprintf("%.1f", temp)
+	}
+	values["ip"] = dm.networkChecker.GetIPv4Address(networkInterface)
+
+	for name, value := range values {
+		topic := prefix + "/" + name
+		if err := publisher.Publish(topic, value); err != nil {
+			slog.Debug("failed to publish", "topic", topic, "err", err)
+		}
+	}
+}
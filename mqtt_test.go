@@ -0,0 +1,101 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+)
+
+// MockPublisher implements MetricPublisher for testing
+type MockPublisher struct {
+	mu        sync.Mutex
+	published map[string]string
+	fail      bool
+	calls     int
+}
+
+func (m *MockPublisher) Publish(topic, payload string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.calls++
+	if m.fail {
+		return fmt.Errorf("broker down")
+	}
+	if m.published == nil {
+		m.published = make(map[string]string)
+	}
+	m.published[topic] = payload
+	return nil
+}
+
+func (m *MockPublisher) Close() {}
+
+// TestPublishMetrics tests that each value is published under the topic prefix
+func TestPublishMetrics(t *testing.T) {
+	provider := &countingMetricsProvider{MockMetricsProvider: MockMetricsProvider{
+		memory: 40.2,
+		disks:  map[string]float64{"/": 61.0},
+		temps:  &MockTemperatureReader{files: map[string]float64{tempFile: 48.5}},
+	}}
+	dm := &DisplayManager{
+		networkChecker: &MockNetworkChecker{ipAddress: "192.168.1.100"},
+		metricsSource:  provider,
+		metrics:        newDisplayMetrics(),
+	}
+	publisher := &MockPublisher{}
+	dm.publishMetrics(publisher, "pi", "eth0")
+	if _, ok := publisher.published["pi/cpu"]; ok {
+		t.Error("Expected no CPU usage before the display has shown one")
+	}
+
+	dm.metrics.SetCPU(12.5)
+	dm.publishMetrics(publisher, "pi", "eth0")
+	if provider.cpuCalls != 0 {
+		t.Errorf("Expected CPU usage not to be sampled for MQTT, got %d reads", provider.cpuCalls)
+	}
+
+	if got := publisher.published["pi/ip"]; got != "192.168.1.100" {
+		t.Errorf("Expected pi/ip to be 192.168.1.100, got %q", got)
+	}
+	if got := publisher.published["pi/temperature"]; got != "48.5" {
+		t.Errorf("Expected pi/temperature to be 48.5, got %q", got)
+	}
+	for topic, want := range map[string]string{"pi/cpu": "12.5", "pi/memory": "40.2", "pi/disk": "61.0"} {
+		if got := publisher.published[topic]; got != want {
+			t.Errorf("Expected %s to be %s, got %q", topic, want, got)
+		}
+	}
+}
+
+// TestRunMQTTBrokerDown tests that publish failures don't stop the loop
+func TestRunMQTTBrokerDown(t *testing.T) {
+	dm := &DisplayManager{
+		networkChecker: &MockNetworkChecker{ipAddress: "192.168.1.100"},
+		metricsSource:  &MockMetricsProvider{temps: &MockTemperatureReader{}},
+	}
+	publisher := &MockPublisher{fail: true}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		dm.runMQTT(ctx, publisher, MQTTConfig{Interval: 1}, "eth0")
+		close(done)
+	}()
+
+	time.Sleep(1500 * time.Millisecond)
+	cancel()
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("runMQTT did not stop after cancel")
+	}
+
+	publisher.mu.Lock()
+	defer publisher.mu.Unlock()
+	// The first round publishes immediately and the second after one interval
+	if publisher.calls < 2 {
+		t.Errorf("Expected publishing to continue after failures, got %d calls", publisher.calls)
+	}
+}
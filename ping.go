@@ -0,0 +1,120 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net"
+	"syscall"
+	"time"
+
+	"github.com/swilcox/go-monitor-ssd1306/render"
+)
+
+const (
+	defaultPingPort     = "80"
+	defaultPingInterval = 10 * time.Second
+	defaultPingTimeout  = 2 * time.Second
+)
+
+// Pinger interface for checking whether a host answers, returning the round
+// trip time
+type Pinger interface {
+	Ping(ctx context.Context, address string) (time.Duration, error)
+}
+
+// RealPinger implements Pinger with a TCP connect, which unlike ICMP needs no
+// privileges. A refused connection still came from the host, so it counts as
+// up.
+type RealPinger struct{}
+
+// Ping connects to address (host:port) and closes the connection again
+func (p *RealPinger) Ping(ctx context.Context, address string) (time.Duration, error) {
+	start := time.Now()
+	var dialer net.Dialer
+	conn, err := dialer.DialContext(ctx, "tcp", address)
+	if errors.Is(err, syscall.ECONNREFUSED) {
+		return time.Since(start), nil
+	}
+	if err != nil {
+		return 0, err
+	}
+	rtt := time.Since(start)
+	conn.Close()
+	return rtt, nil
+}
+
+// pingAddress returns the host:port a ping component connects to
+func pingAddress(host string) string {
+	if _, _, err := net.SplitHostPort(host); err == nil {
+		return host
+	}
+	return net.JoinHostPort(host, defaultPingPort)
+}
+
+// pingInterval returns how often a ping component checks its host
+func pingInterval(c Component) time.Duration {
+	if c.Interval == 0 {
+		return defaultPingInterval
+	}
+	return time.Duration(c.Interval) * time.Second
+}
+
+// pingTimeout returns how long a ping component waits for its host
+func pingTimeout(c Component) time.Duration {
+	if c.Timeout == 0 {
+		return defaultPingTimeout
+	}
+	return time.Duration(c.Timeout) * time.Second
+}
+
+// pingResult is the outcome of one check. A host that doesn't answer is a
+// result to show, not a failed fetch.
+type pingResult struct {
+	up  bool
+	rtt time.Duration
+}
+
+// latestPing returns the latest result for the component's host, checking it
+// in the background once per interval. checked is false until the first
+// check finishes.
+func (dm *DisplayManager) latestPing(comp Component) (result pingResult, checked bool) {
+	address := pingAddress(comp.Host)
+	if dm.pings == nil {
+		dm.pings = make(map[string]*fetchCache[pingResult])
+	}
+	cache, found := dm.pings[address]
+	if !found {
+		cache = &fetchCache[pingResult]{}
+		dm.pings[address] = cache
+	}
+	pinger, timeout := dm.pinger, pingTimeout(comp)
+	return cache.get("ping "+address, dm.timeNow(), pingInterval(comp), pingInterval(comp), dm.goAsync, func() (pingResult, error) {
+		ctx, cancel := context.WithTimeout(context.Background(), timeout)
+		defer cancel()
+		rtt, err := pinger.Ping(ctx, address)
+		if err != nil {
+			slog.Debug("ping failed", "address", address, "err", err)
+		}
+		return pingResult{up: err == nil, rtt: rtt}, nil
+	})
+}
+
+// goAsync runs f on its own goroutine, or through asyncFunc when a test has
+// set it
+func (dm *DisplayManager) goAsync(f func()) {
+	if dm.asyncFunc != nil {
+		dm.asyncFunc(f)
+		return
+	}
+	go f()
+}
+
+// pingText describes a ping result, e.g. "gw: up 12ms" or "gw: down"
+func pingText(label string, result pingResult) string {
+	if !result.up {
+		return render.Labeled(label, "down")
+	}
+	return render.Labeled(label, fmt.Sprintf("up %dms", result.rtt.Milliseconds()))
+}
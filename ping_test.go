@@ -0,0 +1,166 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"net"
+	"sync"
+	"testing"
+	"time"
+)
+
+// MockPinger implements Pinger for testing
+type MockPinger struct {
+	mu      sync.Mutex
+	rtts    map[string]time.Duration
+	err     error
+	calls   []string
+	release chan struct{} // when set, pings wait for it
+}
+
+func (m *MockPinger) Ping(ctx context.Context, address string) (time.Duration, error) {
+	if m.release != nil {
+		<-m.release
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.calls = append(m.calls, address)
+	return m.rtts[address], m.err
+}
+
+// TestPingAddress tests that the default port is added only when missing
+func TestPingAddress(t *testing.T) {
+	tests := map[string]string{
+		"192.168.1.1":      "192.168.1.1:80",
+		"nas.local:445":    "nas.local:445",
+		"fe80::1":          "[fe80::1]:80",
+		"[2001:db8::1]:22": "[2001:db8::1]:22",
+	}
+	for host, want := range tests {
+		if got := pingAddress(host); got != want {
+			t.Errorf("pingAddress(%q) = %q, want %q", host, got, want)
+		}
+	}
+}
+
+// TestPingComponent tests the text for reachable and unreachable hosts
+func TestPingComponent(t *testing.T) {
+	tests := []struct {
+		name      string
+		pinger    *MockPinger
+		comp      Component
+		wantLabel string
+	}{
+		{
+			name:      "Reachable",
+			pinger:    &MockPinger{rtts: map[string]time.Duration{"192.168.1.1:80": 12400 * time.Microsecond}},
+			comp:      Component{Type: "ping", X: 5, Y: 20, Host: "192.168.1.1", Label: "gw"},
+			wantLabel: "gw: up 12ms",
+		},
+		{
+			name:      "Unreachable",
+			pinger:    &MockPinger{err: errors.New("i/o timeout")},
+			comp:      Component{Type: "ping", X: 5, Y: 20, Host: "192.168.1.1", Label: "gw"},
+			wantLabel: "gw: down",
+		},
+		{
+			name:      "Host as label",
+			pinger:    &MockPinger{rtts: map[string]time.Duration{"nas:445": 3 * time.Millisecond}},
+			comp:      Component{Type: "ping", X: 5, Y: 20, Host: "nas:445"},
+			wantLabel: "nas:445: up 3ms",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dm := &DisplayManager{
+				pinger:    tt.pinger,
+				asyncFunc: func(f func()) { f() },
+				img:       blankFrame(),
+				timeNow:   time.Now,
+			}
+			if err := dm.renderComponent(tt.comp); err != nil {
+				t.Fatalf("Failed to render component: %v", err)
+			}
+			if want := labelImage(5, 20, tt.wantLabel); !bytes.Equal(dm.img.Pix, want.Pix) {
+				t.Errorf("Rendered image does not match %q", tt.wantLabel)
+			}
+		})
+	}
+}
+
+// TestPingInterval tests that the host is only checked once per interval
+func TestPingInterval(t *testing.T) {
+	pinger := &MockPinger{}
+	start := time.Date(2024, 3, 9, 14, 0, 0, 0, time.Local)
+	now := start
+	dm := &DisplayManager{
+		pinger:    pinger,
+		asyncFunc: func(f func()) { f() },
+		img:       blankFrame(),
+		timeNow:   func() time.Time { return now },
+	}
+	comp := Component{Type: "ping", X: 5, Y: 20, Host: "gw", Interval: 30}
+	for _, second := range []int{0, 1, 29, 30, 31} {
+		now = start.Add(time.Duration(second) * time.Second)
+		if err := dm.renderComponent(comp); err != nil {
+			t.Fatalf("Failed to render component: %v", err)
+		}
+	}
+	if len(pinger.calls) != 2 {
+		t.Errorf("Expected checks at 0s and 30s, got %d", len(pinger.calls))
+	}
+}
+
+// TestPingDoesNotBlock tests that a slow check runs in the background, with
+// the placeholder shown until it finishes
+func TestPingDoesNotBlock(t *testing.T) {
+	pinger := &MockPinger{rtts: map[string]time.Duration{"gw:80": 5 * time.Millisecond}, release: make(chan struct{})}
+	dm := &DisplayManager{pinger: pinger, img: blankFrame(), timeNow: time.Now}
+	comp := Component{Type: "ping", X: 5, Y: 20, Host: "gw"}
+
+	if err := dm.renderComponent(comp); err != nil {
+		t.Fatalf("Failed to render component: %v", err)
+	}
+	if want := labelImage(5, 20, "gw: N/A"); !bytes.Equal(dm.img.Pix, want.Pix) {
+		t.Error("Expected the placeholder while the first check runs")
+	}
+
+	close(pinger.release)
+	deadline := time.Now().Add(2 * time.Second)
+	for {
+		if _, checked := dm.latestPing(comp); checked {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("Background check never finished")
+		}
+		time.Sleep(time.Millisecond)
+	}
+	dm.img = blankFrame()
+	if err := dm.renderComponent(comp); err != nil {
+		t.Fatalf("Failed to render component: %v", err)
+	}
+	if want := labelImage(5, 20, "gw: up 5ms"); !bytes.Equal(dm.img.Pix, want.Pix) {
+		t.Error("Expected the finished check to be drawn")
+	}
+}
+
+// TestRealPinger tests that listening and refusing ports both count as up
+func TestRealPinger(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Skipf("Cannot listen: %v", err)
+	}
+	address := ln.Addr().String()
+
+	pinger := &RealPinger{}
+	if _, err := pinger.Ping(context.Background(), address); err != nil {
+		t.Errorf("Expected a listening port to be up, got %v", err)
+	}
+	ln.Close()
+	if _, err := pinger.Ping(context.Background(), address); err != nil {
+		t.Errorf("Expected a refused connection to count as up, got %v", err)
+	}
+}
@@ -0,0 +1,96 @@
+package main
+
+import (
+	"fmt"
+	"image"
+	"image/draw"
+	"image/png"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// previewNetworkChecker implements NetworkChecker with a fixed address so
+// previews render the same on any machine
+type previewNetworkChecker struct{}
+
+// GetIPv4Address returns a documentation address for every interface
+func (p *previewNetworkChecker) GetIPv4Address(interfaceName string) string {
+	return "192.0.2.10"
+}
+
+// GetFirstIPv4Address returns the same documentation address
+func (p *previewNetworkChecker) GetFirstIPv4Address(interfaceNames []string) string {
+	return "192.0.2.10"
+}
+
+// GetIPv6Address returns a documentation IPv6 address for every interface
+func (p *previewNetworkChecker) GetIPv6Address(interfaceName string) string {
+	return "2001:db8::10"
+}
+
+// previewPath returns the numbered file name for a screen, so out.png
+// becomes out0.png, out1.png and so on
+func previewPath(outPath string, index int) string {
+	ext := filepath.Ext(outPath)
+	if ext == "" {
+		ext = ".png"
+	}
+	return fmt.Sprintf("%s%d%s", strings.TrimSuffix(outPath, filepath.Ext(outPath)), index, ext)
+}
+
+// writePreview renders every configured screen once and writes each frame
+// as a PNG. It never initializes periph, so it runs without display hardware.
+// With several displays each one's screens get their own numbered files, so
+// out.png becomes out0-0.png, out0-1.png, out1-0.png and so on.
+func writePreview(configPath, outPath string) error {
+	managers, err := newDisplayManagers(configPath, &previewNetworkChecker{})
+	if err != nil {
+		return err
+	}
+	if len(managers) == 1 {
+		return writeDisplayPreview(managers[0], outPath)
+	}
+	for i, dm := range managers {
+		ext := filepath.Ext(outPath)
+		if err := writeDisplayPreview(dm, fmt.Sprintf("%s%d-%s", strings.TrimSuffix(outPath, ext), i, ext)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// writeDisplayPreview writes one PNG per screen of a single display
+func writeDisplayPreview(dm *DisplayManager, outPath string) error {
+	// Each screen is rendered once, so wait for fetches like weather instead
+	// of previewing their placeholders
+	dm.asyncFunc = func(f func()) { f() }
+	for i := range dm.config.Screens {
+		dm.currentScreen = i
+		// Sensors missing on a laptop render as placeholders, so the rest of the layout still previews
+		dm.renderFrame()
+
+		// Composite onto black so the PNG looks like the panel
+		frame := image.NewRGBA(dm.img.Bounds())
+		draw.Draw(frame, frame.Bounds(), image.Black, image.Point{}, draw.Src)
+		draw.Draw(frame, frame.Bounds(), dm.img, image.Point{}, draw.Over)
+
+		if err := writePNG(previewPath(outPath, i), frame); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// writePNG encodes an image to a PNG file
+func writePNG(path string, img image.Image) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return fmt.Errorf("error creating preview file: %v", err)
+	}
+	if err := png.Encode(f, img); err != nil {
+		f.Close()
+		return fmt.Errorf("error encoding preview: %v", err)
+	}
+	return f.Close()
+}
@@ -0,0 +1,75 @@
+package main
+
+import (
+	"image/png"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// TestPreviewPath tests the numbered file names generated for each screen
+func TestPreviewPath(t *testing.T) {
+	tests := []struct {
+		outPath string
+		index   int
+		want    string
+	}{
+		{"out.png", 0, "out0.png"},
+		{"previews/screen.png", 2, "previews/screen2.png"},
+		{"screen", 1, "screen1.png"},
+	}
+
+	for _, tt := range tests {
+		if got := previewPath(tt.outPath, tt.index); got != tt.want {
+			t.Errorf("previewPath(%q, %d) = %q, want %q", tt.outPath, tt.index, got, tt.want)
+		}
+	}
+}
+
+// TestWritePreview tests that every screen is rendered to its own PNG without hardware
+func TestWritePreview(t *testing.T) {
+	dir := t.TempDir()
+	configPath := filepath.Join(dir, "config.yaml")
+	configYAML := []byte(`
+screen_duration: 5
+network_interface: eth0
+screens:
+  - name: Network
+    components:
+      - type: ip
+        x: 5
+        y: 12
+        label: IP
+  - name: Clock
+    components:
+      - type: time
+        x: 5
+        y: 12
+`)
+	if err := os.WriteFile(configPath, configYAML, 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := writePreview(configPath, filepath.Join(dir, "screen.png")); err != nil {
+		t.Fatalf("Failed to write preview: %v", err)
+	}
+
+	for _, name := range []string{"screen0.png", "screen1.png"} {
+		f, err := os.Open(filepath.Join(dir, name))
+		if err != nil {
+			t.Fatalf("Expected %s to be written: %v", name, err)
+		}
+		img, err := png.Decode(f)
+		f.Close()
+		if err != nil {
+			t.Fatalf("Failed to decode %s: %v", name, err)
+		}
+		if b := img.Bounds(); b.Dx() != width || b.Dy() != height {
+			t.Errorf("%s: expected %dx%d image, got %v", name, width, height, b)
+		}
+	}
+
+	if _, err := os.Stat(filepath.Join(dir, "screen2.png")); !os.IsNotExist(err) {
+		t.Error("Expected only one PNG per configured screen")
+	}
+}
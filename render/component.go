@@ -0,0 +1,73 @@
+package render
+
+// Component represents a display component configuration
+type Component struct {
+	Type            string   `yaml:"type" json:"type"`
+	Enabled         *bool    `yaml:"enabled,omitempty" json:"enabled,omitempty"` // false skips drawing the component, defaults to true
+	X               int      `yaml:"x" json:"x"`
+	Y               int      `yaml:"y" json:"y"`
+	Label           string   `yaml:"label,omitempty" json:"label,omitempty"`
+	ShowBar         bool     `yaml:"show_bar,omitempty" json:"show_bar,omitempty"`
+	BarWidth        int      `yaml:"bar_width,omitempty" json:"bar_width,omitempty"`
+	TimeFormat      string   `yaml:"time_format,omitempty" json:"time_format,omitempty"`           // Go layout for time, date and calendar; "compact" or "verbose" for uptime
+	MaxMbps         float64  `yaml:"max_mbps,omitempty" json:"max_mbps,omitempty"`                 // netspeed bar scale, defaults to 100
+	Mountpoint      string   `yaml:"mountpoint,omitempty" json:"mountpoint,omitempty"`             // disk mountpoint, defaults to "/"
+	Mountpoints     []string `yaml:"mountpoints,omitempty" json:"mountpoints,omitempty"`           // diskall: mountpoints whose usage is added up
+	ShowAbsolute    bool     `yaml:"show_absolute,omitempty" json:"show_absolute,omitempty"`       // memory and disk: show used/total bytes instead of the percentage
+	Device          string   `yaml:"device,omitempty" json:"device,omitempty"`                     // diskio: block device such as "sda"
+	Align           string   `yaml:"align,omitempty" json:"align,omitempty"`                       // "left" (default), "center" or "right" of X
+	Height          int      `yaml:"height,omitempty" json:"height,omitempty"`                     // graph or vertical bar height in pixels, defaults to 16
+	Source          string   `yaml:"source,omitempty" json:"source,omitempty"`                     // temperature file, defaults to thermal_zone0; fan: hwmon fan input, defaults to the first fan1_input; docker: API socket
+	SensorKey       string   `yaml:"sensor_key,omitempty" json:"sensor_key,omitempty"`             // gopsutil sensor key, used instead of source when set
+	StartHour       *int     `yaml:"start_hour,omitempty" json:"start_hour,omitempty"`             // first hour (0-23) the component is shown
+	EndHour         *int     `yaml:"end_hour,omitempty" json:"end_hour,omitempty"`                 // hour (0-23) the component is hidden again
+	Scroll          bool     `yaml:"scroll,omitempty" json:"scroll,omitempty"`                     // scroll text that doesn't fit horizontally
+	Interfaces      []string `yaml:"interfaces,omitempty" json:"interfaces,omitempty"`             // ip: interfaces to try in order
+	Family          string   `yaml:"family,omitempty" json:"family,omitempty"`                     // ip: "ipv4" (default) or "ipv6"
+	Top             bool     `yaml:"top,omitempty" json:"top,omitempty"`                           // processes: show the busiest process instead of the count
+	Text            string   `yaml:"text,omitempty" json:"text,omitempty"`                         // text: caption drawn verbatim
+	Orientation     string   `yaml:"orientation,omitempty" json:"orientation,omitempty"`           // line and bars: "horizontal" (default) or "vertical"
+	Length          int      `yaml:"length,omitempty" json:"length,omitempty"`                     // line: length in pixels
+	Thickness       int      `yaml:"thickness,omitempty" json:"thickness,omitempty"`               // line: thickness in pixels, defaults to 1
+	ShowBarText     bool     `yaml:"show_bar_text,omitempty" json:"show_bar_text,omitempty"`       // draw the percentage inside a horizontal bar
+	Icon            string   `yaml:"icon,omitempty" json:"icon,omitempty"`                         // embedded icon drawn before the text
+	RefreshSeconds  int      `yaml:"refresh_seconds,omitempty" json:"refresh_seconds,omitempty"`   // re-sample at most this often, 0 for every update (10 for exec, 5 for docker)
+	AlertAbove      *float64 `yaml:"alert_above,omitempty" json:"alert_above,omitempty"`           // blink while the percentage is above this
+	AlertBelow      *float64 `yaml:"alert_below,omitempty" json:"alert_below,omitempty"`           // blink while the percentage is below this
+	DangerThreshold *float64 `yaml:"danger_threshold,omitempty" json:"danger_threshold,omitempty"` // hatch the bar fill beyond this percentage
+	Fields          []string `yaml:"fields,omitempty" json:"fields,omitempty"`                     // hostname: pieces to show, defaults to [hostname]
+	MaxRPM          int      `yaml:"max_rpm,omitempty" json:"max_rpm,omitempty"`                   // fan: speed of a full bar
+	Command         string   `yaml:"command,omitempty" json:"command,omitempty"`                   // exec: shell command whose output is shown
+	Timeout         int      `yaml:"timeout,omitempty" json:"timeout,omitempty"`                   // exec: seconds before the command is killed, defaults to 5; ping: seconds to wait, defaults to 2
+	Trend           bool     `yaml:"trend,omitempty" json:"trend,omitempty"`                       // temperature: mark whether it rose or fell since the last reading
+	SignalBars      bool     `yaml:"signal_bars,omitempty" json:"signal_bars,omitempty"`           // wifi: draw signal strength bars before the text
+	BlinkColon      bool     `yaml:"blink_colon,omitempty" json:"blink_colon,omitempty"`           // time: hide the colons on odd seconds when time_format shows seconds
+	DimText         bool     `yaml:"dim_text,omitempty" json:"dim_text,omitempty"`                 // dither the text so it reads as gray
+	Spacing         int      `yaml:"spacing,omitempty" json:"spacing,omitempty"`                   // cpucores: pixels between bars, defaults to 2
+	Ticks           int      `yaml:"ticks,omitempty" json:"ticks,omitempty"`                       // horizontal bars: scale divisions marked by pips above the bar
+	Zones           []string `yaml:"zones,omitempty" json:"zones,omitempty"`                       // temperature: files or glob patterns, the hottest is shown
+	ShowZone        bool     `yaml:"show_zone,omitempty" json:"show_zone,omitempty"`               // temperature: name the zone the reading came from
+	StaleAfter      int      `yaml:"stale_after,omitempty" json:"stale_after,omitempty"`           // refresh intervals a failing component keeps its last value before it is dimmed
+	Wrap            bool     `yaml:"wrap,omitempty" json:"wrap,omitempty"`                         // break long text onto further lines below Y
+	MaxWidth        int      `yaml:"max_width,omitempty" json:"max_width,omitempty"`               // wrap: line width in pixels, defaults to the rest of the display
+	BarBorder       *bool    `yaml:"bar_border,omitempty" json:"bar_border,omitempty"`             // outline horizontal bars, defaults to true
+	BarPadding      int      `yaml:"bar_padding,omitempty" json:"bar_padding,omitempty"`           // unlit pixels around a horizontal bar's fill
+	Reverse         bool     `yaml:"reverse,omitempty" json:"reverse,omitempty"`                   // horizontal bars: fill from the right
+	Timezone        string   `yaml:"timezone,omitempty" json:"timezone,omitempty"`                 // time and date: IANA zone, defaults to the global timezone
+	Host            string   `yaml:"host,omitempty" json:"host,omitempty"`                         // ping: host or host:port to connect to, port 80 when omitted
+	Interval        int      `yaml:"interval,omitempty" json:"interval,omitempty"`                 // ping: seconds between checks, defaults to 10
+}
+
+// IsEnabled reports whether the component is drawn, which it is unless
+// enabled is set to false
+func (c Component) IsEnabled() bool {
+	return c.Enabled == nil || *c.Enabled
+}
+
+// LineThickness returns a line component's thickness in pixels, defaulting to 1
+func (c Component) LineThickness() int {
+	if c.Thickness == 0 {
+		return 1
+	}
+	return c.Thickness
+}
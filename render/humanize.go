@@ -0,0 +1,55 @@
+package render
+
+import (
+	"fmt"
+	"math"
+)
+
+// byteUnits are the binary units bytes are shown in, smallest first
+var byteUnits = []struct {
+	size float64
+	name string
+}{
+	{1 << 10, "KB"},
+	{1 << 20, "MB"},
+	{1 << 30, "GB"},
+	{1 << 40, "TB"},
+}
+
+// byteUnit returns the largest unit n still fills once it is rounded the way
+// it is shown (whole bytes, one decimal above that), so 1048575 bytes is
+// 1.0 MB rather than 1024.0 KB
+func byteUnit(n float64) (size float64, name string) {
+	size, name = 1, "B"
+	shown := math.Round(n)
+	for _, unit := range byteUnits {
+		if shown < 1024 {
+			break
+		}
+		size, name = unit.size, unit.name
+		shown = math.Round(n/size*10) / 10
+	}
+	return size, name
+}
+
+// HumanizeBytes formats n in binary units with one decimal, e.g. "3.2GB".
+// Counts under 1 KB are shown whole, e.g. "512B".
+func HumanizeBytes(n float64) string {
+	size, name := byteUnit(n)
+	if size == 1 {
+		return fmt.Sprintf("%.0f%s", n, name)
+	}
+	return fmt.Sprintf("%.1f%s", n/size, name)
+}
+
+// FormatUsedTotal formats used and total bytes as "3.2/7.8 GB", both in the
+// unit that suits total. The decimal is dropped from three-digit totals, e.g.
+// "210/512 GB", to keep the text short.
+func FormatUsedTotal(used, total uint64) string {
+	size, name := byteUnit(float64(total))
+	u, t := float64(used)/size, float64(total)/size
+	if size == 1 || math.Round(t*10) >= 1000 {
+		return fmt.Sprintf("%.0f/%.0f %s", u, t, name)
+	}
+	return fmt.Sprintf("%.1f/%.1f %s", u, t, name)
+}
@@ -0,0 +1,48 @@
+package render
+
+import "testing"
+
+// TestHumanizeBytes tests the switch to each binary unit at its boundary,
+// including values that only reach it once rounded
+func TestHumanizeBytes(t *testing.T) {
+	tests := []struct {
+		n    float64
+		want string
+	}{
+		{0, "0B"},
+		{1023, "1023B"},
+		{1023.6, "1.0KB"},
+		{1024, "1.0KB"},
+		{1<<20 - 1, "1.0MB"},
+		{1 << 20, "1.0MB"},
+		{1<<30 - 1, "1.0GB"},
+		{3435973837, "3.2GB"},
+		{1 << 40, "1.0TB"},
+	}
+	for _, tt := range tests {
+		if got := HumanizeBytes(tt.n); got != tt.want {
+			t.Errorf("HumanizeBytes(%v) = %q, want %q", tt.n, got, tt.want)
+		}
+	}
+}
+
+// TestFormatUsedTotal tests that both figures use the unit that suits the total
+func TestFormatUsedTotal(t *testing.T) {
+	tests := []struct {
+		used, total uint64
+		want        string
+	}{
+		{3435973837, 8375186227, "3.2/7.8 GB"},
+		{210 << 30, 512 << 30, "210/512 GB"},
+		{1 << 40, 2 << 40, "1.0/2.0 TB"},
+		{100 << 20, 900 << 20, "100/900 MB"},
+		{512, 1000, "512/1000 B"},
+		{1 << 19, 1<<20 - 1, "0.5/1.0 MB"},
+		{50 << 30, 100<<30 - 1, "50/100 GB"},
+	}
+	for _, tt := range tests {
+		if got := FormatUsedTotal(tt.used, tt.total); got != tt.want {
+			t.Errorf("FormatUsedTotal(%d, %d) = %q, want %q", tt.used, tt.total, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,80 @@
+package render
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+	"strconv"
+	"strings"
+)
+
+// IconGap is the space in pixels between an icon and its text
+const IconGap = 2
+
+// Icon is a monochrome bitmap. Each row is padded to whole bytes and the
+// least significant bit of each byte is the leftmost pixel, as in XBM.
+type Icon struct {
+	Width, Height int
+	Bits          []byte
+}
+
+// Lit reports whether the pixel at column x, row y is set
+func (ic *Icon) Lit(x, y int) bool {
+	rowBytes := (ic.Width + 7) / 8
+	return ic.Bits[y*rowBytes+x/8]&(1<<uint(x%8)) != 0
+}
+
+// ParseXBM reads the width, height and bit data of an XBM bitmap
+func ParseXBM(data string) (*Icon, error) {
+	ic := &Icon{}
+	for _, line := range strings.Split(data, "\n") {
+		fields := strings.Fields(line)
+		if len(fields) != 3 || fields[0] != "#define" {
+			continue
+		}
+		value, err := strconv.Atoi(fields[2])
+		if err != nil {
+			return nil, fmt.Errorf("bad %s: %v", fields[1], err)
+		}
+		switch {
+		case strings.HasSuffix(fields[1], "_width"):
+			ic.Width = value
+		case strings.HasSuffix(fields[1], "_height"):
+			ic.Height = value
+		}
+	}
+	if ic.Width <= 0 || ic.Height <= 0 {
+		return nil, fmt.Errorf("missing width or height")
+	}
+
+	start, end := strings.Index(data, "{"), strings.LastIndex(data, "}")
+	if start < 0 || end < start {
+		return nil, fmt.Errorf("missing bit data")
+	}
+	for _, field := range strings.Split(data[start+1:end], ",") {
+		field = strings.TrimSpace(field)
+		if field == "" {
+			continue
+		}
+		value, err := strconv.ParseUint(field, 0, 8)
+		if err != nil {
+			return nil, fmt.Errorf("bad bit data %q: %v", field, err)
+		}
+		ic.Bits = append(ic.Bits, byte(value))
+	}
+	if want := (ic.Width + 7) / 8 * ic.Height; len(ic.Bits) != want {
+		return nil, fmt.Errorf("expected %d bytes of bit data, got %d", want, len(ic.Bits))
+	}
+	return ic, nil
+}
+
+// DrawIcon copies an icon's set bits into img with its top-left corner at x, y
+func DrawIcon(img *image.RGBA, ic *Icon, x, y int) {
+	for row := 0; row < ic.Height; row++ {
+		for col := 0; col < ic.Width; col++ {
+			if ic.Lit(col, row) {
+				img.Set(x+col, y+row, color.White)
+			}
+		}
+	}
+}
@@ -0,0 +1,23 @@
+package render
+
+import "testing"
+
+// TestParseXBM tests reading bits from an XBM file
+func TestParseXBM(t *testing.T) {
+	ic, err := ParseXBM("#define dot_width 3\n#define dot_height 2\nstatic unsigned char dot_bits[] = {\n   0x05, 0x02 };\n")
+	if err != nil {
+		t.Fatalf("Failed to parse XBM: %v", err)
+	}
+	want := [][]bool{{true, false, true}, {false, true, false}}
+	for y, row := range want {
+		for x, lit := range row {
+			if ic.Lit(x, y) != lit {
+				t.Errorf("Pixel %d,%d: expected lit=%v", x, y, lit)
+			}
+		}
+	}
+
+	if _, err := ParseXBM("#define dot_width 8\n#define dot_height 2\nstatic unsigned char dot_bits[] = { 0x05 };\n"); err == nil {
+		t.Error("Expected an error for truncated bit data")
+	}
+}
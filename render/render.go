@@ -0,0 +1,367 @@
+// Package render draws the text, bars, graphs and lines the monitor's
+// components are made of. Everything draws white on a transparent
+// *image.RGBA, so frames can be composited, inverted or rotated before they
+// reach a display, and the functions can be used without one.
+package render
+
+import (
+	"image"
+	"image/color"
+	"image/draw"
+	"strings"
+	"unicode/utf8"
+
+	"golang.org/x/image/font"
+	"golang.org/x/image/math/fixed"
+)
+
+// AddLabel adds a text label to the image
+func AddLabel(img *image.RGBA, face font.Face, x, y int, label string) {
+	drawLabel(img, image.NewUniform(color.White), face, x, y, label)
+}
+
+// AddDimLabel adds a text label with only every other pixel of each glyph
+// lit, in a checkerboard, so it reads as gray on a monochrome panel
+func AddDimLabel(img *image.RGBA, face font.Face, x, y int, label string) {
+	drawLabel(img, checkerboard{}, face, x, y, label)
+}
+
+// drawLabel draws label with its baseline starting at x, y, taking pixel
+// colors from src
+func drawLabel(img *image.RGBA, src image.Image, face font.Face, x, y int, label string) {
+	d := &font.Drawer{
+		Dst:  img,
+		Src:  src,
+		Face: face,
+		Dot:  fixed.Point26_6{X: fixed.I(x), Y: fixed.I(y)},
+	}
+	d.DrawString(label)
+}
+
+// checkerboard is an unbounded image alternating white and transparent
+// pixels. The font drawer reads it relative to each glyph's bounds, so the
+// phase can differ between glyphs but every glyph is half lit.
+type checkerboard struct{}
+
+func (checkerboard) ColorModel() color.Model { return color.RGBAModel }
+
+func (checkerboard) Bounds() image.Rectangle {
+	return image.Rect(-1<<30, -1<<30, 1<<30, 1<<30)
+}
+
+func (checkerboard) At(x, y int) color.Color {
+	if (x+y)&1 == 0 {
+		return color.White
+	}
+	return color.Transparent
+}
+
+// AlignX returns the starting X for text so that it is left-aligned at x,
+// centered on x, or ends at x
+func AlignX(face font.Face, x int, text, align string) int {
+	textWidth := font.MeasureString(face, text).Ceil()
+	switch align {
+	case "center":
+		return x - textWidth/2
+	case "right":
+		return x - textWidth
+	default:
+		return x
+	}
+}
+
+// LineHeight returns the distance between the baselines of wrapped lines
+func LineHeight(face font.Face) int {
+	m := face.Metrics()
+	return (m.Ascent + m.Descent).Ceil()
+}
+
+// WrapText breaks text into lines no wider than maxWidth, breaking on spaces
+// and at newlines. A word wider than a whole line is split between
+// characters.
+func WrapText(face font.Face, text string, maxWidth int) []string {
+	var lines []string
+	for _, paragraph := range strings.Split(text, "\n") {
+		line := ""
+		for _, word := range strings.Fields(paragraph) {
+			candidate := word
+			if line != "" {
+				candidate = line + " " + word
+			}
+			if font.MeasureString(face, candidate).Ceil() <= maxWidth {
+				line = candidate
+				continue
+			}
+			if line != "" {
+				lines = append(lines, line)
+			}
+			for font.MeasureString(face, word).Ceil() > maxWidth {
+				n := fittingPrefix(face, word, maxWidth)
+				lines = append(lines, word[:n])
+				word = word[n:]
+			}
+			line = word
+		}
+		lines = append(lines, line)
+	}
+	return lines
+}
+
+// fittingPrefix returns the length in bytes of the longest proper prefix of
+// word no wider than maxWidth, but always at least one character so wrapping
+// advances
+func fittingPrefix(face font.Face, word string, maxWidth int) int {
+	n := 0
+	for i := range word {
+		if i == 0 {
+			continue
+		}
+		if font.MeasureString(face, word[:i]).Ceil() > maxWidth {
+			break
+		}
+		n = i
+	}
+	if n == 0 {
+		_, n = utf8.DecodeRuneInString(word)
+	}
+	return n
+}
+
+// clampFraction limits a bar percentage to 0..1, since sensors can briefly
+// report values outside that range
+func clampFraction(percentage float64) float64 {
+	if percentage < 0 {
+		return 0
+	}
+	if percentage > 1 {
+		return 1
+	}
+	return percentage
+}
+
+// drawBarBorder draws a one-pixel border around the width x height region at
+// x, y. Like image.Rectangle the region is half-open: the border covers
+// columns x to x+width-1 and rows y to y+height-1.
+func drawBarBorder(img *image.RGBA, x, y, width, height int) {
+	for i := x; i < x+width; i++ {
+		img.Set(i, y, color.White)
+		img.Set(i, y+height-1, color.White)
+	}
+	for j := y; j < y+height; j++ {
+		img.Set(x, j, color.White)
+		img.Set(x+width-1, j, color.White)
+	}
+}
+
+// DrawBar draws a horizontal progress bar occupying exactly the width x
+// height region at x, y, filling from the left inside the border. Fill past
+// the danger fraction is hatched; a danger of 1 gives a solid bar.
+func DrawBar(img *image.RGBA, x, y, width, height int, percentage, danger float64) {
+	DrawStyledBar(img, x, y, width, height, percentage, danger, BarStyle{})
+}
+
+// BarStyle adjusts how DrawStyledBar draws a bar. The zero value draws the
+// same bar as DrawBar.
+type BarStyle struct {
+	NoBorder bool // draw only the fill
+	Padding  int  // unlit pixels between the bar's edge (or border) and the fill
+	Reverse  bool // fill from the right edge instead of the left
+}
+
+// DrawStyledBar draws a horizontal progress bar like DrawBar, with the border
+// and the fill's inset set by style
+func DrawStyledBar(img *image.RGBA, x, y, width, height int, percentage, danger float64, style BarStyle) {
+	inset := style.Padding
+	if !style.NoBorder {
+		drawBarBorder(img, x, y, width, height)
+		inset++
+	}
+
+	// Fill bar based on percentage, with the danger fraction measured from
+	// the edge the fill starts at
+	innerWidth := width - 2*inset
+	fillWidth := int(float64(innerWidth) * clampFraction(percentage))
+	dangerWidth := int(float64(innerWidth) * clampFraction(danger))
+	for k := 0; k < fillWidth; k++ {
+		i := x + inset + k
+		if style.Reverse {
+			i = x + inset + innerWidth - 1 - k
+		}
+		for j := y + inset; j < y+height-inset; j++ {
+			if k >= dangerWidth && hatchGap(i, j) {
+				continue
+			}
+			img.Set(i, j, color.White)
+		}
+	}
+}
+
+// tickHeight is the height in pixels of the pips DrawBarTicks draws
+const tickHeight = 2
+
+// DrawBarTicks draws scale pips above a horizontal bar at x, y, dividing its
+// width into ticks equal parts with a pip at each mark, including both ends
+func DrawBarTicks(img *image.RGBA, x, y, width, ticks int) {
+	if ticks <= 0 {
+		return
+	}
+	for i := 0; i <= ticks; i++ {
+		px := x + i*(width-1)/ticks
+		for j := y - tickHeight; j < y; j++ {
+			img.Set(px, j, color.White)
+		}
+	}
+}
+
+// hatchGap reports whether a pixel is left unlit by the diagonal stripes
+// that fill the danger part of a bar
+func hatchGap(x, y int) bool {
+	return (x+y)%3 == 0
+}
+
+// DrawBarText draws text centered inside a bar's border, inverting the pixels
+// it covers so it stays readable over both the filled and empty parts
+func DrawBarText(img *image.RGBA, face font.Face, x, y, width, height int, text string) {
+	mask := image.NewRGBA(img.Bounds())
+	ascent := face.Metrics().Ascent.Ceil()
+	AddLabel(mask, face, AlignX(face, x+width/2, text, "center"), y+1+ascent, text)
+
+	inside := image.Rect(x+1, y+1, x+width-1, y+height-1).Intersect(img.Bounds())
+	for j := inside.Min.Y; j < inside.Max.Y; j++ {
+		for i := inside.Min.X; i < inside.Max.X; i++ {
+			if mask.RGBAAt(i, j).A == 0 {
+				continue
+			}
+			if img.RGBAAt(i, j).R != 0 {
+				img.Set(i, j, color.Transparent)
+			} else {
+				img.Set(i, j, color.White)
+			}
+		}
+	}
+}
+
+// BarTextHeight returns the height of a bar tall enough to hold a line of text
+func BarTextHeight(face font.Face) int {
+	metrics := face.Metrics()
+	return (metrics.Ascent + metrics.Descent).Ceil() + 2
+}
+
+// DrawVBar draws a vertical progress bar occupying exactly the width x
+// height region at x, y, filling from the bottom up inside the border. Fill
+// past the danger fraction is hatched as in DrawBar.
+func DrawVBar(img *image.RGBA, x, y, width, height int, percentage, danger float64) {
+	drawBarBorder(img, x, y, width, height)
+
+	// Fill bar upward based on percentage
+	fillHeight := int(float64(height-2) * clampFraction(percentage))
+	dangerY := y + height - 2 - int(float64(height-2)*clampFraction(danger))
+	for j := y + height - 2; j > y+height-2-fillHeight; j-- {
+		for i := x + 1; i < x+width-1; i++ {
+			if j <= dangerY && hatchGap(i, j) {
+				continue
+			}
+			img.Set(i, j, color.White)
+		}
+	}
+}
+
+// DrawGraph draws percentage samples (0-100, oldest first) as a column-height
+// sparkline in the width x height region at x, y, with the newest sample in
+// the rightmost column
+func DrawGraph(img *image.RGBA, x, y, width, height int, samples []float64) {
+	if len(samples) > width {
+		samples = samples[len(samples)-width:]
+	}
+	start := x + width - len(samples)
+	for i, sample := range samples {
+		colHeight := int(sample/100.0*float64(height) + 0.5)
+		if colHeight > height {
+			colHeight = height
+		}
+		for j := 0; j < colHeight; j++ {
+			img.Set(start+i, y+height-1-j, color.White)
+		}
+	}
+}
+
+// DrawLine draws a solid line starting at x,y. Horizontal lines run right
+// and grow downward with thickness; vertical lines run down and grow right.
+func DrawLine(img *image.RGBA, x, y, length, thickness int, vertical bool) {
+	w, h := length, thickness
+	if vertical {
+		w, h = thickness, length
+	}
+	for i := x; i < x+w; i++ {
+		for j := y; j < y+h; j++ {
+			img.Set(i, j, color.White)
+		}
+	}
+}
+
+// Clear sets every pixel to opaque black. Unlike the transparent black of a
+// new image, anything partly transparent drawn over it then blends to the
+// gray it stands for, so luminance thresholds see the intended value.
+func Clear(img *image.RGBA) {
+	for i := 0; i < len(img.Pix); i += 4 {
+		img.Pix[i], img.Pix[i+1], img.Pix[i+2], img.Pix[i+3] = 0, 0, 0, 0xff
+	}
+}
+
+// Invert swaps lit and unlit pixels, leaving every pixel opaque
+func Invert(img *image.RGBA) {
+	for i := 0; i < len(img.Pix); i += 4 {
+		v := uint8(0xff)
+		if img.Pix[i] != 0 {
+			v = 0
+		}
+		img.Pix[i], img.Pix[i+1], img.Pix[i+2], img.Pix[i+3] = v, v, v, 0xff
+	}
+}
+
+// Dim returns a copy of img with only every other lit pixel kept, in the same
+// checkerboard as AddDimLabel, so whatever it holds reads as gray
+func Dim(img *image.RGBA) *image.RGBA {
+	b := img.Bounds()
+	out := image.NewRGBA(b)
+	draw.DrawMask(out, b, img, b.Min, checkerboard{}, b.Min, draw.Src)
+	return out
+}
+
+// Shift returns a copy of img moved dx pixels right and dy pixels down.
+// Pixels moved past an edge are dropped and the uncovered edge is unlit.
+func Shift(img *image.RGBA, dx, dy int) *image.RGBA {
+	b := img.Bounds()
+	out := image.NewRGBA(b)
+	Clear(out)
+	draw.Draw(out, b.Add(image.Pt(dx, dy)), img, b.Min, draw.Src)
+	return out
+}
+
+// Rotate returns a copy of img turned clockwise by 90, 180 or 270
+// degrees. For a w x h image the pixel at x, y moves to h-1-y, x at 90,
+// w-1-x, h-1-y at 180 and y, w-1-x at 270; the result is h x w at 90 and 270.
+func Rotate(img *image.RGBA, degrees int) *image.RGBA {
+	b := img.Bounds()
+	w, h := b.Dx(), b.Dy()
+	size := image.Rect(0, 0, w, h)
+	if degrees == 90 || degrees == 270 {
+		size = image.Rect(0, 0, h, w)
+	}
+	rotated := image.NewRGBA(size)
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			px, py := x, y
+			switch degrees {
+			case 90:
+				px, py = h-1-y, x
+			case 180:
+				px, py = w-1-x, h-1-y
+			case 270:
+				px, py = y, w-1-x
+			}
+			rotated.SetRGBA(px, py, img.RGBAAt(b.Min.X+x, b.Min.Y+y))
+		}
+	}
+	return rotated
+}
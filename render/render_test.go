@@ -0,0 +1,472 @@
+package render
+
+import (
+	"bytes"
+	"fmt"
+	"image"
+	"image/color"
+	"image/draw"
+	"testing"
+
+	"golang.org/x/image/font"
+	"golang.org/x/image/font/basicfont"
+)
+
+// Tests draw into a frame the size of the default 128x64 panel
+const (
+	width  = 128
+	height = 64
+)
+
+// TestDrawBar tests the DrawBar function
+func TestDrawBar(t *testing.T) {
+	tests := []struct {
+		name       string
+		percentage float64
+		wantEmpty  bool
+		wantFull   bool
+	}{
+		{
+			name:       "Empty bar",
+			percentage: 0.0,
+			wantEmpty:  true,
+			wantFull:   false,
+		},
+		{
+			name:       "Full bar",
+			percentage: 1.0,
+			wantEmpty:  false,
+			wantFull:   true,
+		},
+		{
+			name:       "Half bar",
+			percentage: 0.5,
+			wantEmpty:  false,
+			wantFull:   false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			img := image.NewRGBA(image.Rect(0, 0, width, height))
+			DrawBar(img, 10, 10, 50, BarHeight, tt.percentage, 1)
+
+			// Check if bar is drawn correctly
+			middle := img.RGBAAt(35, 13) // Point in middle of bar
+			if tt.wantEmpty && middle.R != 0 {
+				t.Errorf("Expected empty bar, but got color at middle point")
+			}
+			if tt.wantFull && middle.R == 0 {
+				t.Errorf("Expected full bar, but got no color at middle point")
+			}
+
+			// Check border
+			border := img.RGBAAt(10, 10) // Top-left corner
+			if border.R == 0 {
+				t.Errorf("Expected border to be drawn")
+			}
+		})
+	}
+}
+
+// TestDrawVBar tests that vertical bars fill from the bottom up
+func TestDrawVBar(t *testing.T) {
+	tests := []struct {
+		name       string
+		percentage float64
+		wantBottom bool // pixel just above the bottom border
+		wantMiddle bool
+		wantTop    bool // pixel just below the top border
+	}{
+		{"Empty bar", 0.0, false, false, false},
+		{"Half bar", 0.5, true, false, false},
+		{"Full bar", 1.0, true, true, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			img := image.NewRGBA(image.Rect(0, 0, width, height))
+			// Interior spans rows 11-28
+			DrawVBar(img, 10, 10, BarHeight, 20, tt.percentage, 1)
+
+			if lit := img.RGBAAt(13, 28).R != 0; lit != tt.wantBottom {
+				t.Errorf("Bottom: expected lit=%v, got %v", tt.wantBottom, lit)
+			}
+			if lit := img.RGBAAt(13, 15).R != 0; lit != tt.wantMiddle {
+				t.Errorf("Upper half: expected lit=%v, got %v", tt.wantMiddle, lit)
+			}
+			if lit := img.RGBAAt(13, 12).R != 0; lit != tt.wantTop {
+				t.Errorf("Top: expected lit=%v, got %v", tt.wantTop, lit)
+			}
+			if img.RGBAAt(10, 10).R == 0 {
+				t.Errorf("Expected border to be drawn")
+			}
+		})
+	}
+}
+
+// TestDrawBarClamp tests that out-of-range percentages stay inside the bar
+func TestDrawBarClamp(t *testing.T) {
+	tests := []struct {
+		name       string
+		percentage float64
+		want       float64
+	}{
+		{"Over 100%", 1.5, 1},
+		{"Negative", -0.2, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			img := image.NewRGBA(image.Rect(0, 0, width, height))
+			DrawBar(img, 10, 10, 50, BarHeight, tt.percentage, 1)
+
+			bar := image.Rect(10, 10, 60, 10+BarHeight)
+			for y := 0; y < height; y++ {
+				for x := 0; x < width; x++ {
+					if img.RGBAAt(x, y).R != 0 && !(image.Point{X: x, Y: y}).In(bar) {
+						t.Fatalf("Pixel %d,%d outside the bar was set", x, y)
+					}
+				}
+			}
+
+			want := image.NewRGBA(image.Rect(0, 0, width, height))
+			DrawBar(want, 10, 10, 50, BarHeight, tt.want, 1)
+			if !bytes.Equal(img.Pix, want.Pix) {
+				t.Errorf("Expected the bar to match a %.0f%% bar", tt.want*100)
+			}
+		})
+	}
+}
+
+// TestDrawBarBounds tests that a bar covers exactly its width x height region
+func TestDrawBarBounds(t *testing.T) {
+	for _, percentage := range []float64{0, 0.5, 1} {
+		img := image.NewRGBA(image.Rect(0, 0, width, height))
+		DrawBar(img, 10, 10, 50, BarHeight, percentage, 1)
+
+		lit := image.Rectangle{}
+		for y := 0; y < height; y++ {
+			for x := 0; x < width; x++ {
+				if img.RGBAAt(x, y).R != 0 {
+					lit = lit.Union(image.Rect(x, y, x+1, y+1))
+				}
+			}
+		}
+		if want := image.Rect(10, 10, 60, 10+BarHeight); lit != want {
+			t.Errorf("%.0f%%: expected lit region %v, got %v", percentage*100, want, lit)
+		}
+		// The rightmost border column is x+width-1
+		if img.RGBAAt(59, 12).R == 0 || img.RGBAAt(60, 12).R != 0 {
+			t.Errorf("%.0f%%: expected the right border at column 59 only", percentage*100)
+		}
+	}
+}
+
+// TestDrawStyledBar tests that the border is only drawn when enabled and that
+// padding insets the fill
+func TestDrawStyledBar(t *testing.T) {
+	const x, y, w, h = 10, 10, 20, BarHeight
+	tests := []struct {
+		name       string
+		style      BarStyle
+		wantBorder bool
+		fillStart  int // first column of a full bar's fill, or of its border
+	}{
+		{name: "Default", style: BarStyle{}, wantBorder: true, fillStart: x},
+		{name: "Borderless", style: BarStyle{NoBorder: true}, wantBorder: false, fillStart: x},
+		{name: "Padded", style: BarStyle{Padding: 1}, wantBorder: true, fillStart: x + 2},
+		{name: "Borderless padded", style: BarStyle{NoBorder: true, Padding: 2}, wantBorder: false, fillStart: x + 2},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			empty := image.NewRGBA(image.Rect(0, 0, width, height))
+			DrawStyledBar(empty, x, y, w, h, 0, 1, tt.style)
+			for _, p := range []image.Point{{x, y}, {x + w - 1, y}, {x, y + h - 1}, {x + w - 1, y + h - 1}, {x + w/2, y}} {
+				if lit := empty.RGBAAt(p.X, p.Y).R != 0; lit != tt.wantBorder {
+					t.Errorf("Border pixel %v lit = %v, want %v", p, lit, tt.wantBorder)
+				}
+			}
+
+			full := image.NewRGBA(image.Rect(0, 0, width, height))
+			DrawStyledBar(full, x, y, w, h, 1, 1, tt.style)
+			mid := y + h/2
+			for i := x; i < x+w; i++ {
+				edge := i == x || i == x+w-1
+				want := (tt.wantBorder && edge) || (i >= tt.fillStart && i < x+w-(tt.fillStart-x))
+				if lit := full.RGBAAt(i, mid).R != 0; lit != want {
+					t.Errorf("Column %d of a full bar lit = %v, want %v", i, lit, want)
+				}
+			}
+		})
+	}
+}
+
+// TestDrawStyledBarReverse tests that a reversed 30% bar fills the right 30%
+// of the inside of its border, and hatches past the danger fraction from there
+func TestDrawStyledBarReverse(t *testing.T) {
+	const x, y, w, h = 10, 10, 22, BarHeight
+	img := image.NewRGBA(image.Rect(0, 0, width, height))
+	DrawStyledBar(img, x, y, w, h, 0.3, 1, BarStyle{Reverse: true})
+
+	// 20 columns inside the border, so the fill is the last 6 of them
+	mid := y + h/2
+	for i := x + 1; i < x+w-1; i++ {
+		want := i >= x+w-1-6
+		if lit := img.RGBAAt(i, mid).R != 0; lit != want {
+			t.Errorf("Column %d lit = %v, want %v", i, lit, want)
+		}
+	}
+
+	hatched := image.NewRGBA(image.Rect(0, 0, width, height))
+	DrawStyledBar(hatched, x, y, w, h, 1, 0.5, BarStyle{Reverse: true})
+	for i := x + 1; i < x+w-1; i++ {
+		gaps := false
+		for j := y + 1; j < y+h-1; j++ {
+			if hatched.RGBAAt(i, j).R == 0 {
+				gaps = true
+			}
+		}
+		if want := i < x+1+10; gaps != want {
+			t.Errorf("Column %d hatched = %v, want %v", i, gaps, want)
+		}
+	}
+}
+
+// TestDrawBarTicks tests that a 4-tick bar has pips above it at each quarter
+func TestDrawBarTicks(t *testing.T) {
+	img := image.NewRGBA(image.Rect(0, 0, width, height))
+	DrawBarTicks(img, 10, 20, 41, 4)
+
+	want := map[int]bool{10: true, 20: true, 30: true, 40: true, 50: true}
+	for x := 0; x < width; x++ {
+		for _, y := range []int{18, 19} {
+			if lit := img.RGBAAt(x, y).R != 0; lit != want[x] {
+				t.Errorf("Pixel %d,%d lit = %v, want %v", x, y, lit, want[x])
+			}
+		}
+		if img.RGBAAt(x, 17).R != 0 || img.RGBAAt(x, 20).R != 0 {
+			t.Errorf("Expected pips only in the two rows above the bar, column %d", x)
+		}
+	}
+}
+
+// TestDrawGraph tests the column heights drawn for a known sample sequence
+func TestDrawGraph(t *testing.T) {
+	const x, y, graphWidth, graphHeight = 10, 20, 6, 10
+
+	h := NewHistory(graphWidth)
+	for _, v := range []float64{0, 50, 100, 20} {
+		h.Push(v)
+	}
+	img := image.NewRGBA(image.Rect(0, 0, width, height))
+	DrawGraph(img, x, y, graphWidth, graphHeight, h.Values())
+
+	// Samples are right-aligned with the newest in the last column
+	wantHeights := []int{0, 0, 0, 5, 10, 2}
+	for col, want := range wantHeights {
+		got := 0
+		for row := 0; row < graphHeight; row++ {
+			if img.RGBAAt(x+col, y+row).R != 0 {
+				got++
+			}
+		}
+		if got != want {
+			t.Errorf("Column %d: expected height %d, got %d", col, want, got)
+		}
+		// Columns fill from the bottom up
+		if want > 0 && img.RGBAAt(x+col, y+graphHeight-1).R == 0 {
+			t.Errorf("Column %d: expected bottom pixel to be lit", col)
+		}
+	}
+
+	// Pushing another sample shifts the graph left by one column
+	h.Push(100)
+	img = image.NewRGBA(image.Rect(0, 0, width, height))
+	DrawGraph(img, x, y, graphWidth, graphHeight, h.Values())
+	if img.RGBAAt(x+2, y+graphHeight-1).R == 0 || img.RGBAAt(x+5, y).R == 0 {
+		t.Error("Expected graph to shift left with the newest sample on the right")
+	}
+}
+
+// TestRotateImage tests where each rotation sends a logical pixel
+func TestRotateImage(t *testing.T) {
+	// A 64x128 portrait layout and the 128x64 landscape panel it is sent to
+	tests := []struct {
+		degrees  int
+		w, h     int
+		x, y     int
+		wantX    int
+		wantY    int
+		wantSize image.Point
+	}{
+		{degrees: 90, w: 64, h: 128, x: 0, y: 0, wantX: 127, wantY: 0, wantSize: image.Pt(128, 64)},
+		{degrees: 90, w: 64, h: 128, x: 10, y: 20, wantX: 107, wantY: 10, wantSize: image.Pt(128, 64)},
+		{degrees: 180, w: 128, h: 64, x: 10, y: 20, wantX: 117, wantY: 43, wantSize: image.Pt(128, 64)},
+		{degrees: 270, w: 64, h: 128, x: 0, y: 0, wantX: 0, wantY: 63, wantSize: image.Pt(128, 64)},
+		{degrees: 270, w: 64, h: 128, x: 10, y: 20, wantX: 20, wantY: 53, wantSize: image.Pt(128, 64)},
+	}
+
+	for _, tt := range tests {
+		t.Run(fmt.Sprintf("%d degrees from %d,%d", tt.degrees, tt.x, tt.y), func(t *testing.T) {
+			img := image.NewRGBA(image.Rect(0, 0, tt.w, tt.h))
+			img.Set(tt.x, tt.y, color.White)
+
+			rotated := Rotate(img, tt.degrees)
+			if got := rotated.Bounds().Size(); got != tt.wantSize {
+				t.Fatalf("Expected a %v frame, got %v", tt.wantSize, got)
+			}
+			for y := 0; y < tt.wantSize.Y; y++ {
+				for x := 0; x < tt.wantSize.X; x++ {
+					lit := rotated.RGBAAt(x, y).R != 0
+					if lit != (x == tt.wantX && y == tt.wantY) {
+						t.Fatalf("Pixel %d,%d lit=%v, expected only %d,%d to be lit", x, y, lit, tt.wantX, tt.wantY)
+					}
+				}
+			}
+
+			// Turning the rest of the way round restores the original
+			if again := Rotate(rotated, 360-tt.degrees); !bytes.Equal(again.Pix, img.Pix) {
+				t.Error("Expected a full turn to return the original image")
+			}
+		})
+	}
+}
+
+// TestAddDimLabel tests that dim text lights about half of the pixels of
+// the same text drawn solid, and nothing outside it
+func TestAddDimLabel(t *testing.T) {
+	solid := image.NewRGBA(image.Rect(0, 0, width, height))
+	dim := image.NewRGBA(image.Rect(0, 0, width, height))
+	AddLabel(solid, basicfont.Face7x13, 5, 20, "CPU: 42.0%")
+	AddDimLabel(dim, basicfont.Face7x13, 5, 20, "CPU: 42.0%")
+
+	solidLit, dimLit := 0, 0
+	for y := 0; y < height; y++ {
+		for x := 0; x < width; x++ {
+			s, d := solid.RGBAAt(x, y).R != 0, dim.RGBAAt(x, y).R != 0
+			if s {
+				solidLit++
+			}
+			if d {
+				dimLit++
+				if !s {
+					t.Fatalf("Dim pixel %d,%d is outside the glyphs", x, y)
+				}
+			}
+		}
+	}
+	if solidLit == 0 {
+		t.Fatal("Expected the solid label to light pixels")
+	}
+	if ratio := float64(dimLit) / float64(solidLit); ratio < 0.4 || ratio > 0.6 {
+		t.Errorf("Expected about half the glyph pixels lit, got %d of %d (%.2f)", dimLit, solidLit, ratio)
+	}
+}
+
+// TestDim tests that a dimmed image keeps only the checkerboard's lit pixels
+func TestDim(t *testing.T) {
+	img := image.NewRGBA(image.Rect(0, 0, 4, 2))
+	for i := range img.Pix {
+		img.Pix[i] = 0xff
+	}
+	dimmed := Dim(img)
+	for y := 0; y < 2; y++ {
+		for x := 0; x < 4; x++ {
+			if lit := dimmed.RGBAAt(x, y).R != 0; lit != ((x+y)%2 == 0) {
+				t.Errorf("Pixel %d,%d lit = %v", x, y, lit)
+			}
+		}
+	}
+}
+
+// TestWrapText tests that long text breaks into the expected lines, none
+// wider than the limit
+func TestWrapText(t *testing.T) {
+	face := basicfont.Face7x13 // 7 pixels per character
+	tests := []struct {
+		name     string
+		text     string
+		maxWidth int
+		want     []string
+	}{
+		{
+			name:     "Words",
+			text:     "the quick brown fox jumps over the lazy dog",
+			maxWidth: 70,
+			want:     []string{"the quick", "brown fox", "jumps over", "the lazy", "dog"},
+		},
+		{name: "Fits", text: "short", maxWidth: 70, want: []string{"short"}},
+		{name: "Long word", text: "see abcdefghijklm", maxWidth: 35, want: []string{"see", "abcde", "fghij", "klm"}},
+		{name: "Newlines", text: "one\ntwo three", maxWidth: 70, want: []string{"one", "two three"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := WrapText(face, tt.text, tt.maxWidth)
+			if fmt.Sprint(got) != fmt.Sprint(tt.want) {
+				t.Errorf("Expected %q, got %q", tt.want, got)
+			}
+			for _, line := range got {
+				if w := font.MeasureString(face, line).Ceil(); w > tt.maxWidth {
+					t.Errorf("Line %q is %d pixels wide, over %d", line, w, tt.maxWidth)
+				}
+			}
+		})
+	}
+}
+
+// TestClear tests that a cleared image is opaque black and that a
+// half-transparent white drawn over it blends to mid gray rather than staying
+// half transparent
+func TestClear(t *testing.T) {
+	img := image.NewRGBA(image.Rect(0, 0, 4, 4))
+	Clear(img)
+	for i := 0; i < len(img.Pix); i += 4 {
+		if got := img.Pix[i : i+4]; !bytes.Equal(got, []byte{0, 0, 0, 0xff}) {
+			t.Fatalf("Expected opaque black, got %v at byte %d", got, i)
+		}
+	}
+
+	gray := image.NewUniform(color.NRGBA{R: 0xff, G: 0xff, B: 0xff, A: 0x80})
+	draw.Draw(img, image.Rect(1, 1, 2, 2), gray, image.Point{}, draw.Over)
+	if got, want := img.RGBAAt(1, 1), (color.RGBA{R: 0x80, G: 0x80, B: 0x80, A: 0xff}); got != want {
+		t.Errorf("Expected gray %v, got %v", want, got)
+	}
+	if y := color.GrayModel.Convert(img.At(1, 1)).(color.Gray).Y; y != 0x80 {
+		t.Errorf("Expected luminance 0x80, got %#x", y)
+	}
+}
+
+// TestShift tests moving an image and dropping pixels past the edge
+func TestShift(t *testing.T) {
+	img := image.NewRGBA(image.Rect(0, 0, width, height))
+	img.Set(10, 20, color.White)
+	img.Set(width-1, 0, color.White)
+
+	out := Shift(img, -1, 1)
+	if out.RGBAAt(9, 21).R == 0 {
+		t.Error("Expected the pixel at 10,20 to move to 9,21")
+	}
+	if out.RGBAAt(10, 20).R != 0 {
+		t.Error("Expected the original position to be cleared")
+	}
+	if out.RGBAAt(width-2, 1).R == 0 {
+		t.Error("Expected the corner pixel to move inward")
+	}
+
+	out = Shift(img, 1, 0)
+	if out.RGBAAt(11, 20).R == 0 {
+		t.Error("Expected the pixel at 10,20 to move to 11,20")
+	}
+	lit := 0
+	for i := 0; i < len(out.Pix); i += 4 {
+		if out.Pix[i] != 0 {
+			lit++
+		}
+	}
+	if lit != 1 {
+		t.Errorf("Expected the pixel pushed past the right edge to be dropped, got %d lit", lit)
+	}
+}
@@ -0,0 +1,676 @@
+package render
+
+import (
+	"fmt"
+	"image"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/shirou/gopsutil/v3/disk"
+	"github.com/shirou/gopsutil/v3/mem"
+	"golang.org/x/image/font"
+	"golang.org/x/image/font/basicfont"
+)
+
+const (
+	BarHeight          = 7  // height of a horizontal bar, and width of a vertical one
+	DefaultGraphHeight = 16 // height of graphs and vertical bars without a height
+	DefaultPlaceholder = "N/A"
+	ScrollStep         = 4  // pixels a marquee moves per update
+	ScrollGap          = 16 // blank pixels between marquee repeats
+
+	// DefaultTempFile is the temperature read when a component names no source
+	DefaultTempFile = "/sys/class/thermal/thermal_zone0/temp"
+
+	defaultCoreSpacing = 2
+	trendDeadBand      = 0.2 // degrees Celsius a reading must move to count as rising or falling
+)
+
+// MetricsProvider interface for the system metrics shown by the cpu,
+// cpucores, memory, disk and temperature components
+type MetricsProvider interface {
+	CPUPercent() (float64, error)
+	PerCPUPercent() ([]float64, error)
+	VirtualMemory() (*mem.VirtualMemoryStat, error)
+	DiskUsage(path string) (*disk.UsageStat, error)
+	Temperature(source, sensorKey string) (float64, error)
+	HottestTemperature(zones []string) (float64, string, error)
+}
+
+// Recorder is told each metric a Renderer draws, e.g. to export it
+type Recorder interface {
+	SetCPU(percent float64)
+	SetMemory(percent float64)
+	SetDisk(mountpoint string, percent float64)
+	SetTemperature(sensor string, celsius float64)
+}
+
+// Renderer draws components into Img: the clock, text, lines and the metrics
+// read from Metrics itself, and every other type through Other. It keeps each
+// component's own state, such as its marquee offset or graph history, between
+// frames, keyed by Screen and the component's position.
+type Renderer struct {
+	Img      *image.RGBA
+	Metrics  MetricsProvider
+	Face     font.Face                  // defaults to basicfont.Face7x13
+	Icons    map[string]*Icon           // the icons components can name
+	Recorder Recorder                   // nil when metrics aren't exported
+	Now      func() time.Time           // defaults to time.Now
+	Other    func(comp Component) error // draws the types the renderer doesn't know, nil skips them
+
+	Placeholder     string     // shown for a value that can't be read, defaults to N/A
+	TemperatureUnit string     // "C" (default) or "F"
+	TimeLayout      string     // Go layout of the time component, defaults to 15:04:05
+	Timezone        string     // IANA zone of time and date, local time when empty
+	Screen          int        // index of the screen being drawn
+	BlinkOff        bool       // alerting components are hidden while set
+	Recording       *Recording // collects text in place of drawing it, nil otherwise
+
+	sample        *cpuSample // CPU usage read during the current frame, nil outside one
+	histories     map[string]*History
+	prevTemps     map[string]float64 // last reading of each temperature component with trend set
+	scrollOffsets map[string]int
+	locations     map[string]*time.Location // loaded timezones by name
+}
+
+// NewRenderer returns a renderer drawing into img with readings from metrics
+func NewRenderer(img *image.RGBA, metrics MetricsProvider) *Renderer {
+	return &Renderer{Img: img, Metrics: metrics}
+}
+
+// Recording is what a component drew while Renderer.Recording was set: its
+// text, kept apart from the pixels so marquees keep scrolling when it is
+// redrawn, and whether it was past an alert threshold, so it keeps blinking
+type Recording struct {
+	Texts    []RecordedText
+	Alerting bool
+}
+
+// RecordedText is one DrawIconText call kept in a Recording
+type RecordedText struct {
+	Comp Component
+	Icon *Icon
+	Text string
+}
+
+// Draw samples a component's data source and draws it
+func (r *Renderer) Draw(comp Component) error {
+	switch comp.Type {
+	case "time":
+		timeFormat := comp.TimeFormat
+		if timeFormat == "" {
+			timeFormat = r.TimeLayout
+		}
+		if timeFormat == "" {
+			timeFormat = "15:04:05"
+		}
+		now, err := r.clockNow(comp)
+		if err != nil {
+			return err
+		}
+		currentTime := now.Format(timeFormat)
+		if comp.BlinkColon && now.Second()%2 == 1 && layoutHasSeconds(timeFormat) {
+			currentTime = strings.ReplaceAll(currentTime, ":", " ")
+		}
+		r.DrawText(comp, Labeled(comp.Label, currentTime))
+
+	case "date":
+		dateFormat := comp.TimeFormat
+		if dateFormat == "" {
+			dateFormat = "Mon Jan 2"
+		}
+		now, err := r.clockNow(comp)
+		if err != nil {
+			return err
+		}
+		date := now.Format(dateFormat)
+		date = Labeled(comp.Label, date)
+		r.DrawText(comp, date)
+
+	case "cpu":
+		cpuPercent, err := r.cpuPercent()
+		if err != nil {
+			return err
+		}
+		if r.Recorder != nil {
+			r.Recorder.SetCPU(cpuPercent)
+		}
+		if r.AlertHidden(comp, cpuPercent) {
+			return nil
+		}
+		r.DrawText(comp, Labeled(comp.Label, fmt.Sprintf("%.1f%%", cpuPercent)))
+		if comp.ShowBar {
+			r.DrawComponentBar(comp, cpuPercent/100.0)
+		}
+
+	case "memory":
+		memInfo, err := r.Metrics.VirtualMemory()
+		if err != nil {
+			return err
+		}
+		if r.Recorder != nil {
+			r.Recorder.SetMemory(memInfo.UsedPercent)
+		}
+		if r.AlertHidden(comp, memInfo.UsedPercent) {
+			return nil
+		}
+		text := fmt.Sprintf("%.1f%%", memInfo.UsedPercent)
+		if comp.ShowAbsolute {
+			text = FormatUsedTotal(memInfo.Used, memInfo.Total)
+		}
+		r.DrawText(comp, Labeled(comp.Label, text))
+		if comp.ShowBar {
+			r.DrawComponentBar(comp, float64(memInfo.UsedPercent)/100.0)
+		}
+
+	case "cpugraph":
+		cpuPercent, err := r.cpuPercent()
+		if err != nil {
+			return err
+		}
+		if r.Recorder != nil {
+			r.Recorder.SetCPU(cpuPercent)
+		}
+		r.drawHistoryGraph(comp, cpuPercent)
+
+	case "memgraph":
+		memInfo, err := r.Metrics.VirtualMemory()
+		if err != nil {
+			return err
+		}
+		if r.Recorder != nil {
+			r.Recorder.SetMemory(memInfo.UsedPercent)
+		}
+		if graphY, graphHeight, ok := r.drawHistoryGraph(comp, memInfo.UsedPercent); ok {
+			DrawLine(r.Img, comp.X, graphY+graphHeight-1, comp.BarWidth, 1, false)
+		}
+
+	case "cpucores":
+		percents, err := r.perCPUPercent()
+		if err != nil {
+			return err
+		}
+		barsY := comp.Y
+		if comp.Label != "" {
+			r.DrawText(comp, comp.Label)
+			barsY += 5
+		}
+		barWidth := comp.BarWidth
+		if barWidth == 0 {
+			barWidth = BarHeight
+		}
+		spacing := comp.Spacing
+		if spacing == 0 {
+			spacing = defaultCoreSpacing
+		}
+		h := comp.Height
+		if h == 0 {
+			h = DefaultGraphHeight
+		}
+		danger := 1.0
+		if comp.DangerThreshold != nil {
+			danger = *comp.DangerThreshold / 100
+		}
+		// One bar per core the kernel reports, however many there are
+		for i, percent := range percents {
+			DrawVBar(r.Img, comp.X+i*(barWidth+spacing), barsY, barWidth, h, percent/100.0, danger)
+		}
+
+	case "disk":
+		mountpoint := comp.Mountpoint
+		if mountpoint == "" {
+			mountpoint = "/"
+		}
+		label := comp.Label
+		if label == "" {
+			label = mountpoint
+		}
+		usage, err := r.Metrics.DiskUsage(mountpoint)
+		if err != nil {
+			// A missing mountpoint shouldn't take down the whole screen
+			r.DrawPlaceholder(comp, label)
+			return nil
+		}
+		if r.Recorder != nil {
+			r.Recorder.SetDisk(mountpoint, usage.UsedPercent)
+		}
+		if r.AlertHidden(comp, usage.UsedPercent) {
+			return nil
+		}
+		text := fmt.Sprintf("%.1f%%", usage.UsedPercent)
+		if comp.ShowAbsolute {
+			text = FormatUsedTotal(usage.Used, usage.Total)
+		}
+		r.DrawText(comp, Labeled(label, text))
+		if comp.ShowBar {
+			r.DrawComponentBar(comp, float64(usage.UsedPercent)/100.0)
+		}
+
+	case "temperature":
+		var (
+			tempCelsius float64
+			sensor      string
+			err         error
+		)
+		if len(comp.Zones) > 0 {
+			tempCelsius, sensor, err = r.Metrics.HottestTemperature(comp.Zones)
+		} else {
+			tempCelsius, err = r.Metrics.Temperature(comp.Source, comp.SensorKey)
+			sensor = comp.SensorKey
+			if sensor == "" {
+				sensor = comp.Source
+				if sensor == "" {
+					sensor = DefaultTempFile
+				}
+			}
+		}
+		if err != nil {
+			return err
+		}
+		if r.Recorder != nil {
+			r.Recorder.SetTemperature(sensor, tempCelsius)
+		}
+		unit := r.TemperatureUnit
+		if unit == "" {
+			unit = "C"
+		}
+		text := Labeled(comp.Label, fmt.Sprintf("%.1f %s", ConvertTemperature(tempCelsius, unit), unit))
+		if comp.ShowZone && len(comp.Zones) > 0 {
+			text += " " + ZoneName(sensor)
+		}
+		if comp.Trend {
+			if arrow := r.temperatureTrend(comp, tempCelsius); arrow != "" {
+				text += " " + arrow
+			}
+		}
+		r.DrawText(comp, text)
+		if comp.ShowBar {
+			// The bar always spans 0-100 C (32-212 F), whatever unit is shown
+			r.DrawComponentBar(comp, tempCelsius/100.0)
+		}
+
+	case "text":
+		r.DrawText(comp, comp.Text)
+
+	case "line":
+		DrawLine(r.Img, comp.X, comp.Y, comp.Length, comp.LineThickness(), comp.Orientation == "vertical")
+
+	default:
+		if r.Other != nil {
+			return r.Other(comp)
+		}
+	}
+
+	return nil
+}
+
+// cpuSample holds the CPU usage read for one frame
+type cpuSample struct {
+	total, cores       bool // whether each reading has been taken
+	percent            float64
+	percents           []float64
+	totalErr, coresErr error
+}
+
+// BeginFrame starts a frame, in which CPU usage is read at most once
+func (r *Renderer) BeginFrame() {
+	r.sample = &cpuSample{}
+}
+
+// EndFrame ends the frame started by BeginFrame
+func (r *Renderer) EndFrame() {
+	r.sample = nil
+}
+
+// cpuPercent returns the total CPU usage, read at most once per frame. The
+// usage is measured since the previous read, so a second component reading it
+// in the same frame would see a near-zero interval.
+func (r *Renderer) cpuPercent() (float64, error) {
+	s := r.sample
+	if s == nil {
+		return r.Metrics.CPUPercent()
+	}
+	if !s.total {
+		s.percent, s.totalErr = r.Metrics.CPUPercent()
+		s.total = true
+	}
+	return s.percent, s.totalErr
+}
+
+// perCPUPercent returns the usage of each core, read at most once per frame
+func (r *Renderer) perCPUPercent() ([]float64, error) {
+	s := r.sample
+	if s == nil {
+		return r.Metrics.PerCPUPercent()
+	}
+	if !s.cores {
+		s.percents, s.coresErr = r.Metrics.PerCPUPercent()
+		s.cores = true
+	}
+	return s.percents, s.coresErr
+}
+
+// History is a fixed-size ring buffer of recent percentage samples
+type History struct {
+	samples []float64
+	next    int
+	full    bool
+}
+
+// NewHistory returns an empty history holding up to size samples
+func NewHistory(size int) *History {
+	return &History{samples: make([]float64, size)}
+}
+
+// Push adds a sample, overwriting the oldest one once the buffer is full
+func (h *History) Push(v float64) {
+	if len(h.samples) == 0 {
+		return
+	}
+	h.samples[h.next] = v
+	h.next = (h.next + 1) % len(h.samples)
+	if h.next == 0 {
+		h.full = true
+	}
+}
+
+// Values returns the stored samples, oldest first
+func (h *History) Values() []float64 {
+	if !h.full {
+		return append([]float64(nil), h.samples[:h.next]...)
+	}
+	return append(append([]float64(nil), h.samples[h.next:]...), h.samples[:h.next]...)
+}
+
+// history returns the sample history kept for a key, sized to hold size
+// samples. Histories live on the renderer so graphs survive screen switches.
+func (r *Renderer) history(key string, size int) *History {
+	if r.histories == nil {
+		r.histories = make(map[string]*History)
+	}
+	h, ok := r.histories[key]
+	if !ok || len(h.samples) != size {
+		h = NewHistory(size)
+		r.histories[key] = h
+	}
+	return h
+}
+
+// Labeled prefixes text with "label: ", or returns text alone when there is
+// no label
+func Labeled(label, text string) string {
+	if label == "" {
+		return text
+	}
+	return label + ": " + text
+}
+
+// DrawPlaceholder draws the placeholder after label, or alone when there is
+// no label, for a value that couldn't be read
+func (r *Renderer) DrawPlaceholder(comp Component, label string) {
+	placeholder := r.Placeholder
+	if placeholder == "" {
+		placeholder = DefaultPlaceholder
+	}
+	r.DrawText(comp, Labeled(label, placeholder))
+}
+
+// DrawText draws a component's text at its position, honoring its alignment
+// and icon
+func (r *Renderer) DrawText(comp Component, text string) {
+	r.DrawIconText(comp, r.Icons[comp.Icon], text)
+}
+
+// DrawIconText draws text like DrawText with ic, if not nil, in front of it.
+// While Recording is set the text is recorded instead, so it can be redrawn
+// every frame.
+func (r *Renderer) DrawIconText(comp Component, ic *Icon, text string) {
+	if r.Recording != nil {
+		r.Recording.Texts = append(r.Recording.Texts, RecordedText{Comp: comp, Icon: ic, Text: text})
+		return
+	}
+	face := r.FontFace()
+	if ic != nil {
+		// Align the icon and text as one unit, with the icon resting on the baseline
+		lead := ic.Width + IconGap
+		start := AlignX(face, comp.X, text, comp.Align)
+		switch comp.Align {
+		case "center":
+			start -= lead / 2
+		case "right":
+			start -= lead
+		}
+		DrawIcon(r.Img, ic, start, comp.Y-ic.Height)
+		comp.X, comp.Align = start+lead, "left"
+	}
+	if comp.Wrap {
+		r.drawWrapped(comp, face, text)
+		return
+	}
+	if comp.Scroll {
+		textWidth := font.MeasureString(face, text).Ceil()
+		if textWidth > r.Img.Bounds().Max.X-comp.X {
+			r.drawMarquee(comp, face, text, textWidth)
+			return
+		}
+	}
+	addText(r.Img, face, AlignX(face, comp.X, text, comp.Align), comp.Y, text, comp.DimText)
+}
+
+// drawWrapped draws text broken into lines of at most max_width pixels, the
+// first on the component's baseline and each further line one line height
+// below. Without max_width, lines use the room the alignment leaves on screen.
+func (r *Renderer) drawWrapped(comp Component, face font.Face, text string) {
+	maxWidth := comp.MaxWidth
+	if maxWidth == 0 {
+		right := r.Img.Bounds().Max.X
+		switch comp.Align {
+		case "center":
+			maxWidth = 2 * min(comp.X, right-comp.X)
+		case "right":
+			maxWidth = comp.X
+		default:
+			maxWidth = right - comp.X
+		}
+	}
+	lineHeight := LineHeight(face)
+	for i, line := range WrapText(face, text, maxWidth) {
+		addText(r.Img, face, AlignX(face, comp.X, line, comp.Align), comp.Y+i*lineHeight, line, comp.DimText)
+	}
+}
+
+// addText draws a component's text, dithered when dim is set
+func addText(img *image.RGBA, face font.Face, x, y int, text string, dim bool) {
+	if dim {
+		AddDimLabel(img, face, x, y, text)
+		return
+	}
+	AddLabel(img, face, x, y, text)
+}
+
+// FontFace returns the face text is drawn with, defaulting to basicfont
+func (r *Renderer) FontFace() font.Face {
+	if r.Face == nil {
+		return basicfont.Face7x13
+	}
+	return r.Face
+}
+
+// now returns the current time from Now, or the system clock without one
+func (r *Renderer) now() time.Time {
+	if r.Now == nil {
+		return time.Now()
+	}
+	return r.Now()
+}
+
+// clockNow returns the current time in the component's timezone, or the
+// renderer's, or local time when neither is set
+func (r *Renderer) clockNow(comp Component) (time.Time, error) {
+	name := comp.Timezone
+	if name == "" {
+		name = r.Timezone
+	}
+	now := r.now()
+	if name == "" {
+		return now, nil
+	}
+	loc, ok := r.locations[name]
+	if !ok {
+		var err error
+		if loc, err = time.LoadLocation(name); err != nil {
+			return time.Time{}, fmt.Errorf("failed to load timezone: %v", err)
+		}
+		if r.locations == nil {
+			r.locations = make(map[string]*time.Location)
+		}
+		r.locations[name] = loc
+	}
+	return now.In(loc), nil
+}
+
+// layoutHasSeconds reports whether a Go time layout shows seconds, by checking
+// whether two times a second apart format differently
+func layoutHasSeconds(layout string) bool {
+	t := time.Date(2000, 1, 1, 0, 0, 1, 0, time.UTC)
+	return t.Format(layout) != t.Add(time.Second).Format(layout)
+}
+
+// ConvertTemperature converts a Celsius reading to unit, "C" or "F"
+func ConvertTemperature(celsius float64, unit string) float64 {
+	if unit == "F" {
+		return celsius*9/5 + 32
+	}
+	return celsius
+}
+
+// ZoneName shortens a zone file to the name shown by show_zone, e.g.
+// "thermal_zone2" for /sys/class/thermal/thermal_zone2/temp
+func ZoneName(path string) string {
+	return filepath.Base(filepath.Dir(path))
+}
+
+// temperatureTrend compares a reading with the component's previous one and
+// returns ^ when it rose, v when it fell and - when it moved less than the
+// dead band, since basicfont has no arrow glyphs. The first reading has no
+// trend.
+func (r *Renderer) temperatureTrend(comp Component, tempCelsius float64) string {
+	if r.prevTemps == nil {
+		r.prevTemps = make(map[string]float64)
+	}
+	key := r.Key(comp)
+	prev, seen := r.prevTemps[key]
+	r.prevTemps[key] = tempCelsius
+	switch {
+	case !seen:
+		return ""
+	case tempCelsius-prev > trendDeadBand:
+		return "^"
+	case prev-tempCelsius > trendDeadBand:
+		return "v"
+	default:
+		return "-"
+	}
+}
+
+// Key identifies a component's own state, such as its marquee offset or
+// graph history, by screen and position
+func (r *Renderer) Key(comp Component) string {
+	return fmt.Sprintf("%d:%d,%d", r.Screen, comp.X, comp.Y)
+}
+
+// drawMarquee draws text that is too wide for the space right of X, shifted
+// left by the component's scroll offset and repeated after a gap so it wraps
+func (r *Renderer) drawMarquee(comp Component, face font.Face, text string, textWidth int) {
+	if r.scrollOffsets == nil {
+		r.scrollOffsets = make(map[string]int)
+	}
+	key := r.Key(comp)
+	cycle := textWidth + ScrollGap
+	offset := r.scrollOffsets[key] % cycle
+	r.scrollOffsets[key] = offset
+
+	// Clip to the area right of X so the text doesn't spill over the left edge
+	b := r.Img.Bounds()
+	clip := r.Img.SubImage(image.Rect(comp.X, b.Min.Y, b.Max.X, b.Max.Y)).(*image.RGBA)
+	addText(clip, face, comp.X-offset, comp.Y, text, comp.DimText)
+	addText(clip, face, comp.X-offset+cycle, comp.Y, text, comp.DimText)
+}
+
+// AdvanceScrolls moves every marquee along by one step
+func (r *Renderer) AdvanceScrolls() {
+	for key := range r.scrollOffsets {
+		r.scrollOffsets[key] += ScrollStep
+	}
+}
+
+// drawHistoryGraph pushes a percentage onto the component's own history and
+// draws the history as a graph bar_width samples wide, below the current value
+// when the component has a label. It returns where the graph was drawn, with
+// ok false when an alert blinked it off.
+func (r *Renderer) drawHistoryGraph(comp Component, percent float64) (graphY, graphHeight int, ok bool) {
+	history := r.history(comp.Type+":"+r.Key(comp), comp.BarWidth)
+	history.Push(percent)
+	if r.AlertHidden(comp, percent) {
+		return 0, 0, false
+	}
+
+	graphY = comp.Y
+	if comp.Label != "" {
+		r.DrawText(comp, Labeled(comp.Label, fmt.Sprintf("%.1f%%", percent)))
+		graphY += 5
+	}
+	graphHeight = comp.Height
+	if graphHeight <= 0 {
+		graphHeight = DefaultGraphHeight
+	}
+	DrawGraph(r.Img, comp.X, graphY, comp.BarWidth, graphHeight, history.Values())
+	return graphY, graphHeight, true
+}
+
+// DrawComponentBar draws a component's bar below its text. Vertical bars are
+// BarHeight pixels wide and the component's height tall; horizontal bars get
+// the component's scale ticks along their top edge and its border and padding.
+func (r *Renderer) DrawComponentBar(comp Component, percentage float64) {
+	danger := 1.0
+	if comp.DangerThreshold != nil {
+		danger = *comp.DangerThreshold / 100
+	}
+	if comp.Orientation == "vertical" {
+		h := comp.Height
+		if h == 0 {
+			h = DefaultGraphHeight
+		}
+		DrawVBar(r.Img, comp.X, comp.Y+5, BarHeight, h, percentage, danger)
+		return
+	}
+	DrawBarTicks(r.Img, comp.X, comp.Y+5, comp.BarWidth, comp.Ticks)
+	style := BarStyle{
+		NoBorder: comp.BarBorder != nil && !*comp.BarBorder,
+		Padding:  comp.BarPadding,
+		Reverse:  comp.Reverse,
+	}
+	if comp.ShowBarText {
+		// The bar grows to fit the text
+		face := r.FontFace()
+		h := BarTextHeight(face)
+		DrawStyledBar(r.Img, comp.X, comp.Y+5, comp.BarWidth, h, percentage, danger, style)
+		DrawBarText(r.Img, face, comp.X, comp.Y+5, comp.BarWidth, h, fmt.Sprintf("%.0f%%", percentage*100))
+		return
+	}
+	DrawStyledBar(r.Img, comp.X, comp.Y+5, comp.BarWidth, BarHeight, percentage, danger, style)
+}
+
+// AlertHidden reports whether a component is past one of its alert
+// thresholds and in the off half of its blink. While Recording is set nothing
+// is hidden; the recording is marked to blink instead.
+func (r *Renderer) AlertHidden(comp Component, percent float64) bool {
+	alerting := (comp.AlertAbove != nil && percent > *comp.AlertAbove) ||
+		(comp.AlertBelow != nil && percent < *comp.AlertBelow)
+	if r.Recording != nil {
+		r.Recording.Alerting = r.Recording.Alerting || alerting
+		return false
+	}
+	return alerting && r.BlinkOff
+}
@@ -0,0 +1,356 @@
+package render
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"image"
+	"testing"
+
+	"github.com/shirou/gopsutil/v3/disk"
+	"github.com/shirou/gopsutil/v3/mem"
+	"golang.org/x/image/font"
+	"golang.org/x/image/font/basicfont"
+)
+
+// mockMetrics implements MetricsProvider with fixed readings, counting the
+// CPU reads
+type mockMetrics struct {
+	cpu                 float64
+	cores               []float64
+	vm                  *mem.VirtualMemoryStat
+	usages              map[string]*disk.UsageStat
+	temp                float64
+	cpuCalls, coreCalls int
+}
+
+func (m *mockMetrics) CPUPercent() (float64, error) {
+	m.cpuCalls++
+	return m.cpu, nil
+}
+
+func (m *mockMetrics) PerCPUPercent() ([]float64, error) {
+	m.coreCalls++
+	return m.cores, nil
+}
+
+func (m *mockMetrics) VirtualMemory() (*mem.VirtualMemoryStat, error) {
+	if m.vm == nil {
+		return nil, errors.New("no memory reading")
+	}
+	return m.vm, nil
+}
+
+func (m *mockMetrics) DiskUsage(path string) (*disk.UsageStat, error) {
+	usage, ok := m.usages[path]
+	if !ok {
+		return nil, fmt.Errorf("no mountpoint %s", path)
+	}
+	return usage, nil
+}
+
+func (m *mockMetrics) Temperature(source, sensorKey string) (float64, error) {
+	return m.temp, nil
+}
+
+func (m *mockMetrics) HottestTemperature(zones []string) (float64, string, error) {
+	return m.temp, zones[0], nil
+}
+
+// mockRecorder implements Recorder, keeping the last of each reading
+type mockRecorder struct {
+	cpu, memory float64
+	disks       map[string]float64
+	temps       map[string]float64
+}
+
+func (m *mockRecorder) SetCPU(percent float64)    { m.cpu = percent }
+func (m *mockRecorder) SetMemory(percent float64) { m.memory = percent }
+
+func (m *mockRecorder) SetDisk(mountpoint string, percent float64) {
+	m.disks[mountpoint] = percent
+}
+
+func (m *mockRecorder) SetTemperature(sensor string, celsius float64) {
+	m.temps[sensor] = celsius
+}
+
+// blankFrame returns an empty frame the size of the default panel
+func blankFrame() *image.RGBA {
+	img := image.NewRGBA(image.Rect(0, 0, width, height))
+	Clear(img)
+	return img
+}
+
+// labelImage draws a label onto a blank frame for comparison
+func labelImage(x, y int, label string) *image.RGBA {
+	img := blankFrame()
+	AddLabel(img, basicfont.Face7x13, x, y, label)
+	return img
+}
+
+// TestRendererDraw tests the components the renderer draws from its metrics
+// provider, without a display
+func TestRendererDraw(t *testing.T) {
+	metrics := &mockMetrics{
+		cpu:  37.5,
+		vm:   &mem.VirtualMemoryStat{Used: 3435973837, Total: 8375186227, UsedPercent: 41},
+		temp: 22.3,
+		usages: map[string]*disk.UsageStat{
+			"/": {Path: "/", Used: 210 << 30, Total: 512 << 30, UsedPercent: 41},
+		},
+	}
+
+	tests := []struct {
+		name      string
+		comp      Component
+		unit      string
+		wantLabel string
+		wantBar   float64 // -1 for no bar
+	}{
+		{name: "CPU", comp: Component{Type: "cpu", X: 5, Y: 12, Label: "CPU", ShowBar: true, BarWidth: 100}, wantLabel: "CPU: 37.5%", wantBar: 0.375},
+		{name: "Memory", comp: Component{Type: "memory", X: 5, Y: 12, Label: "MEM", ShowAbsolute: true}, wantLabel: "MEM: 3.2/7.8 GB", wantBar: -1},
+		{name: "Disk", comp: Component{Type: "disk", X: 5, Y: 12, ShowAbsolute: true}, wantLabel: "/: 210/512 GB", wantBar: -1},
+		{name: "Missing disk", comp: Component{Type: "disk", X: 5, Y: 12, Mountpoint: "/data"}, wantLabel: "/data: N/A", wantBar: -1},
+		{name: "Fahrenheit", comp: Component{Type: "temperature", X: 5, Y: 12, Label: "Temp", ShowBar: true, BarWidth: 100}, unit: "F", wantLabel: "Temp: 72.1 F", wantBar: 0.223},
+		{name: "Text", comp: Component{Type: "text", X: 5, Y: 12, Text: "hello"}, wantLabel: "hello", wantBar: -1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := NewRenderer(blankFrame(), metrics)
+			r.TemperatureUnit = tt.unit
+			if err := r.Draw(tt.comp); err != nil {
+				t.Fatalf("Failed to draw component: %v", err)
+			}
+			want := labelImage(5, 12, tt.wantLabel)
+			if tt.wantBar >= 0 {
+				DrawBar(want, 5, 17, 100, BarHeight, tt.wantBar, 1)
+			}
+			if !bytes.Equal(r.Img.Pix, want.Pix) {
+				t.Errorf("Drawn image does not match %q", tt.wantLabel)
+			}
+		})
+	}
+}
+
+// TestRendererOther tests that types the renderer doesn't know are passed to
+// Other, and skipped without it
+func TestRendererOther(t *testing.T) {
+	r := NewRenderer(blankFrame(), &mockMetrics{})
+	if err := r.Draw(Component{Type: "weather", X: 5, Y: 12}); err != nil {
+		t.Fatalf("Expected an unknown type to be skipped, got %v", err)
+	}
+
+	var got []string
+	r.Other = func(comp Component) error {
+		got = append(got, comp.Type)
+		r.DrawPlaceholder(comp, "Out")
+		return nil
+	}
+	if err := r.Draw(Component{Type: "weather", X: 5, Y: 12}); err != nil {
+		t.Fatalf("Failed to draw component: %v", err)
+	}
+	if err := r.Draw(Component{Type: "line", X: 0, Y: 0, Length: 10}); err != nil {
+		t.Fatalf("Failed to draw component: %v", err)
+	}
+	if len(got) != 1 || got[0] != "weather" {
+		t.Errorf("Expected only weather to reach Other, got %v", got)
+	}
+	want := labelImage(5, 12, "Out: N/A")
+	DrawLine(want, 0, 0, 10, 1, false)
+	if !bytes.Equal(r.Img.Pix, want.Pix) {
+		t.Error("Expected Other's placeholder and the line to be drawn")
+	}
+}
+
+// TestRendererRecorder tests that each metric drawn is passed to the recorder
+func TestRendererRecorder(t *testing.T) {
+	metrics := &mockMetrics{
+		cpu:    12.5,
+		vm:     &mem.VirtualMemoryStat{UsedPercent: 41},
+		temp:   48.5,
+		usages: map[string]*disk.UsageStat{"/": {UsedPercent: 61.5}},
+	}
+	rec := &mockRecorder{disks: make(map[string]float64), temps: make(map[string]float64)}
+	r := NewRenderer(blankFrame(), metrics)
+	r.Recorder = rec
+	for _, comp := range []Component{{Type: "cpu"}, {Type: "memory"}, {Type: "disk"}, {Type: "temperature"}} {
+		if err := r.Draw(comp); err != nil {
+			t.Fatalf("Failed to draw %s: %v", comp.Type, err)
+		}
+	}
+	if rec.cpu != 12.5 || rec.memory != 41 || rec.disks["/"] != 61.5 || rec.temps[DefaultTempFile] != 48.5 {
+		t.Errorf("Expected every reading recorded, got %+v", rec)
+	}
+}
+
+// TestRendererFrame tests that components sharing a frame share one CPU
+// reading, and that each graph keeps its own history
+func TestRendererFrame(t *testing.T) {
+	metrics := &mockMetrics{cpu: 50, cores: []float64{10, 20}}
+	r := NewRenderer(blankFrame(), metrics)
+	comps := []Component{
+		{Type: "cpu", X: 5, Y: 10},
+		{Type: "cpugraph", X: 5, Y: 20, BarWidth: 4},
+		{Type: "cpugraph", X: 60, Y: 20, BarWidth: 4},
+		{Type: "cpucores", X: 5, Y: 40},
+		{Type: "cpucores", X: 60, Y: 40},
+	}
+	for frame := 1; frame <= 2; frame++ {
+		r.BeginFrame()
+		for _, comp := range comps {
+			if err := r.Draw(comp); err != nil {
+				t.Fatalf("Failed to draw %s: %v", comp.Type, err)
+			}
+		}
+		r.EndFrame()
+		if metrics.cpuCalls != frame || metrics.coreCalls != frame {
+			t.Errorf("Frame %d: expected one read of each, got %d total and %d per-core reads", frame, metrics.cpuCalls, metrics.coreCalls)
+		}
+	}
+
+	if len(r.histories) != 2 {
+		t.Fatalf("Expected a history per graph, got %d", len(r.histories))
+	}
+	for key, h := range r.histories {
+		if got := h.Values(); len(got) != 2 {
+			t.Errorf("History %s: expected one sample per frame, got %v", key, got)
+		}
+	}
+
+	// Outside a frame every draw reads the provider
+	r.Draw(comps[0])
+	r.Draw(comps[0])
+	if metrics.cpuCalls != 4 {
+		t.Errorf("Expected a read per draw outside a frame, got %d reads", metrics.cpuCalls)
+	}
+}
+
+// TestRendererRecording tests that text is recorded rather than drawn while
+// a recording is set, and that alerts mark it instead of hiding anything
+func TestRendererRecording(t *testing.T) {
+	above := 30.0
+	r := NewRenderer(blankFrame(), &mockMetrics{cpu: 37.5})
+	r.BlinkOff = true
+	r.Recording = &Recording{}
+	comp := Component{Type: "cpu", X: 5, Y: 12, Label: "CPU", AlertAbove: &above}
+	if err := r.Draw(comp); err != nil {
+		t.Fatalf("Failed to draw component: %v", err)
+	}
+	if !r.Recording.Alerting {
+		t.Error("Expected the recording to be marked alerting")
+	}
+	if len(r.Recording.Texts) != 1 || r.Recording.Texts[0].Text != "CPU: 37.5%" {
+		t.Errorf("Expected the text to be recorded, got %+v", r.Recording.Texts)
+	}
+	if !bytes.Equal(r.Img.Pix, blankFrame().Pix) {
+		t.Error("Expected nothing to be drawn while recording")
+	}
+
+	r.Recording = nil
+	if err := r.Draw(comp); err != nil {
+		t.Fatalf("Failed to draw component: %v", err)
+	}
+	if !bytes.Equal(r.Img.Pix, blankFrame().Pix) {
+		t.Error("Expected an alerting component to be hidden while the blink is off")
+	}
+}
+
+// TestScrollMarquee tests that long text scrolls and wraps while short text doesn't
+func TestScrollMarquee(t *testing.T) {
+	r := NewRenderer(blankFrame(), &mockMetrics{})
+	text := "IP: fd00:1234:5678:9abc::1"
+	comp := Component{Type: "text", X: 10, Y: 20, Text: text, Scroll: true}
+	cycle := font.MeasureString(basicfont.Face7x13, text).Ceil() + ScrollGap
+
+	draw := func() {
+		t.Helper()
+		Clear(r.Img)
+		if err := r.Draw(comp); err != nil {
+			t.Fatalf("Failed to draw component: %v", err)
+		}
+	}
+
+	draw()
+	key := r.Key(comp)
+	if got := r.scrollOffsets[key]; got != 0 {
+		t.Fatalf("Expected initial offset 0, got %d", got)
+	}
+	first := append([]byte(nil), r.Img.Pix...)
+
+	r.AdvanceScrolls()
+	draw()
+	if got := r.scrollOffsets[key]; got != ScrollStep {
+		t.Errorf("Expected offset %d after one update, got %d", ScrollStep, got)
+	}
+	if bytes.Equal(first, r.Img.Pix) {
+		t.Error("Expected the marquee to move after an update")
+	}
+	for y := 0; y < height; y++ {
+		for x := 0; x < comp.X; x++ {
+			if r.Img.RGBAAt(x, y).R != 0 {
+				t.Fatalf("Expected scrolled text to be clipped at X, pixel %d,%d is set", x, y)
+			}
+		}
+	}
+
+	// Scrolling a full text width plus gap wraps back to the starting frame
+	r.scrollOffsets[key] = cycle
+	draw()
+	if got := r.scrollOffsets[key]; got != 0 {
+		t.Errorf("Expected offset to wrap to 0, got %d", got)
+	}
+	if !bytes.Equal(first, r.Img.Pix) {
+		t.Error("Expected a wrapped marquee to match the first frame")
+	}
+
+	// Text that fits isn't scrolled
+	short := NewRenderer(blankFrame(), &mockMetrics{})
+	if err := short.Draw(Component{Type: "text", X: 10, Y: 20, Text: "10.0.0.1", Scroll: true}); err != nil {
+		t.Fatalf("Failed to draw component: %v", err)
+	}
+	if len(short.scrollOffsets) != 0 {
+		t.Error("Expected no marquee state for text that fits")
+	}
+}
+
+// TestSampleHistory tests that the ring buffer keeps the newest samples in order
+func TestSampleHistory(t *testing.T) {
+	h := NewHistory(3)
+	if got := h.Values(); len(got) != 0 {
+		t.Errorf("Expected empty history, got %v", got)
+	}
+	for _, v := range []float64{10, 20} {
+		h.Push(v)
+	}
+	if got := h.Values(); fmt.Sprint(got) != "[10 20]" {
+		t.Errorf("Expected [10 20], got %v", got)
+	}
+	for _, v := range []float64{30, 40, 50} {
+		h.Push(v)
+	}
+	if got := h.Values(); fmt.Sprint(got) != "[30 40 50]" {
+		t.Errorf("Expected [30 40 50], got %v", got)
+	}
+}
+
+// TestConvertTemperature tests Celsius to Fahrenheit conversion
+func TestConvertTemperature(t *testing.T) {
+	tests := []struct {
+		celsius float64
+		unit    string
+		want    float64
+	}{
+		{0, "F", 32},
+		{100, "F", 212},
+		{45, "F", 113},
+		{45, "C", 45},
+	}
+
+	for _, tt := range tests {
+		if got := ConvertTemperature(tt.celsius, tt.unit); got != tt.want {
+			t.Errorf("ConvertTemperature(%v, %q) = %v, want %v", tt.celsius, tt.unit, got, tt.want)
+		}
+	}
+}
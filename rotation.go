@@ -0,0 +1,102 @@
+package main
+
+import (
+	"fmt"
+	"math/rand"
+)
+
+// screenOrder picks the screens the timer rotates through. Buttons and the
+// HTTP endpoints still step through screens by index.
+type screenOrder interface {
+	// first returns the screen shown at startup
+	first(screens int) int
+	// next returns the screen to show after current
+	next(current, screens int) int
+}
+
+// newScreenOrder returns the order configured by rotation and order
+func newScreenOrder(config Config, rng *rand.Rand) screenOrder {
+	switch {
+	case config.Rotation == "random":
+		return &randomOrder{rng: rng}
+	case len(config.Order) > 0:
+		return &explicitOrder{order: config.Order}
+	}
+	return sequentialOrder{}
+}
+
+// sequentialOrder cycles 0, 1, 2, ...
+type sequentialOrder struct{}
+
+func (sequentialOrder) first(screens int) int {
+	return 0
+}
+
+func (sequentialOrder) next(current, screens int) int {
+	return (current + 1) % screens
+}
+
+// explicitOrder walks a configured list of screen indexes, which may repeat
+// screens to show them more often
+type explicitOrder struct {
+	order []int
+	pos   int
+}
+
+func (o *explicitOrder) first(screens int) int {
+	o.pos = 0
+	return o.next(0, screens)
+}
+
+func (o *explicitOrder) next(current, screens int) int {
+	screen := o.order[o.pos%len(o.order)]
+	o.pos = (o.pos + 1) % len(o.order)
+	return screen
+}
+
+// randomOrder picks any screen except the one already showing
+type randomOrder struct {
+	rng *rand.Rand
+}
+
+func (o *randomOrder) first(screens int) int {
+	return o.rng.Intn(screens)
+}
+
+func (o *randomOrder) next(current, screens int) int {
+	if screens < 2 {
+		return 0
+	}
+	screen := o.rng.Intn(screens - 1)
+	if screen >= current {
+		screen++
+	}
+	return screen
+}
+
+// validateRotation checks the rotation settings shared by every display
+func validateRotation(config Config) []string {
+	var problems []string
+	switch config.Rotation {
+	case "", "sequential":
+	case "random":
+		if len(config.Order) > 0 {
+			problems = append(problems, "order cannot be combined with rotation: random")
+		}
+	default:
+		problems = append(problems, fmt.Sprintf("rotation must be sequential or random, got %q", config.Rotation))
+	}
+	return problems
+}
+
+// validateOrder checks order against the number of screens a display rotates
+// through
+func validateOrder(order []int, screens int, prefix string) []string {
+	var problems []string
+	for _, screen := range order {
+		if screen < 0 || screen >= screens {
+			problems = append(problems, fmt.Sprintf("%sorder refers to screen %d, but there are only %d screens", prefix, screen, screens))
+		}
+	}
+	return problems
+}
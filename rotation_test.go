@@ -0,0 +1,98 @@
+package main
+
+import (
+	"math/rand"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// TestExplicitOrder tests rotating through a configured order, repeats included
+func TestExplicitOrder(t *testing.T) {
+	dm := &DisplayManager{config: Config{
+		Order:   []int{2, 0, 2, 1},
+		Screens: make([]Screen, 3),
+	}}
+	dm.currentScreen = dm.screenOrder().first(3)
+
+	got := []int{dm.currentScreen}
+	for i := 0; i < 7; i++ {
+		dm.currentScreen = dm.nextScreen()
+		got = append(got, dm.currentScreen)
+	}
+	if want := []int{2, 0, 2, 1, 2, 0, 2, 1}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Expected order %v, got %v", want, got)
+	}
+}
+
+// TestSequentialOrder tests the default rotation
+func TestSequentialOrder(t *testing.T) {
+	dm := &DisplayManager{config: Config{Screens: make([]Screen, 3)}}
+	var got []int
+	for i := 0; i < 4; i++ {
+		dm.currentScreen = dm.nextScreen()
+		got = append(got, dm.currentScreen)
+	}
+	if want := []int{1, 2, 0, 1}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Expected order %v, got %v", want, got)
+	}
+}
+
+// TestRandomOrder tests that random rotation reaches every screen without
+// showing one twice in a row
+func TestRandomOrder(t *testing.T) {
+	dm := &DisplayManager{
+		config: Config{Rotation: "random", Screens: make([]Screen, 4)},
+		rng:    rand.New(rand.NewSource(42)),
+	}
+	dm.currentScreen = dm.screenOrder().first(4)
+
+	seen := make(map[int]int)
+	for i := 0; i < 1000; i++ {
+		next := dm.nextScreen()
+		if next == dm.currentScreen {
+			t.Fatalf("Step %d: screen %d repeated", i, next)
+		}
+		if next < 0 || next >= 4 {
+			t.Fatalf("Step %d: screen %d out of range", i, next)
+		}
+		seen[next]++
+		dm.currentScreen = next
+	}
+	if len(seen) != 4 {
+		t.Errorf("Expected every screen to be shown, got %v", seen)
+	}
+
+	// A single screen has nothing to alternate with
+	single := &randomOrder{rng: rand.New(rand.NewSource(1))}
+	if got := single.next(0, 1); got != 0 {
+		t.Errorf("Expected the only screen, got %d", got)
+	}
+}
+
+// TestValidateRotation tests rotation and order problems
+func TestValidateRotation(t *testing.T) {
+	tests := []struct {
+		name    string
+		config  Config
+		wantErr string
+	}{
+		{"Sequential", Config{Rotation: "sequential"}, ""},
+		{"Order in range", Config{Order: []int{1, 0, 1}}, ""},
+		{"Unknown rotation", Config{Rotation: "shuffle"}, `rotation must be sequential or random, got "shuffle"`},
+		{"Order out of range", Config{Order: []int{0, 3}}, "order refers to screen 3, but there are only 2 screens"},
+		{"Random with order", Config{Rotation: "random", Order: []int{0}}, "order cannot be combined with rotation: random"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			problems := strings.Join(append(validateRotation(tt.config), validateOrder(tt.config.Order, 2, "")...), "\n")
+			if tt.wantErr == "" && problems != "" {
+				t.Errorf("Expected no problems, got %s", problems)
+			}
+			if tt.wantErr != "" && !strings.Contains(problems, tt.wantErr) {
+				t.Errorf("Expected %q, got %q", tt.wantErr, problems)
+			}
+		})
+	}
+}
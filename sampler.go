@@ -0,0 +1,56 @@
+package main
+
+import (
+	"sync"
+	"time"
+)
+
+// sharedSampleAge is how long sharedSampler reuses a CPU reading. Updates are
+// at least a second apart, so displays share one reading per update.
+const sharedSampleAge = 500 * time.Millisecond
+
+// sharedSampler implements MetricsProvider for several displays at once.
+// gopsutil measures CPU usage since its previous call anywhere in the
+// process, so displays sampling on their own would cut each other's
+// intervals short. CPU readings are taken once and handed to every display;
+// everything else is passed through.
+type sharedSampler struct {
+	MetricsProvider
+	timeNow func() time.Time
+
+	mu       sync.Mutex
+	cpuAt    time.Time
+	cpu      float64
+	cpuErr   error
+	coresAt  time.Time
+	cores    []float64
+	coresErr error
+}
+
+// newSharedSampler shares the CPU readings of source
+func newSharedSampler(source MetricsProvider) *sharedSampler {
+	return &sharedSampler{MetricsProvider: source, timeNow: time.Now}
+}
+
+// CPUPercent returns the total CPU usage, sampling it only when the last
+// reading is older than sharedSampleAge
+func (s *sharedSampler) CPUPercent() (float64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if now := s.timeNow(); s.cpuAt.IsZero() || now.Sub(s.cpuAt) >= sharedSampleAge {
+		s.cpu, s.cpuErr = s.MetricsProvider.CPUPercent()
+		s.cpuAt = now
+	}
+	return s.cpu, s.cpuErr
+}
+
+// PerCPUPercent returns the usage of each core, sampled like CPUPercent
+func (s *sharedSampler) PerCPUPercent() ([]float64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if now := s.timeNow(); s.coresAt.IsZero() || now.Sub(s.coresAt) >= sharedSampleAge {
+		s.cores, s.coresErr = s.MetricsProvider.PerCPUPercent()
+		s.coresAt = now
+	}
+	return s.cores, s.coresErr
+}
@@ -0,0 +1,44 @@
+package main
+
+import (
+	"sync"
+	"testing"
+	"time"
+)
+
+// TestSharedSampler tests that displays reading CPU usage within one update
+// share a reading, and that a later update takes a new one
+func TestSharedSampler(t *testing.T) {
+	provider := &countingMetricsProvider{MockMetricsProvider: MockMetricsProvider{cpu: 40, cores: []float64{10, 20}}}
+	now := time.Date(2024, 3, 9, 14, 0, 0, 0, time.Local)
+	sampler := newSharedSampler(provider)
+	sampler.timeNow = func() time.Time { return now }
+
+	displays := make([]*DisplayManager, 3)
+	for i := range displays {
+		displays[i] = &DisplayManager{metricsSource: sampler}
+	}
+	var wg sync.WaitGroup
+	for _, dm := range displays {
+		wg.Add(1)
+		go func(dm *DisplayManager) {
+			defer wg.Done()
+			if percent, err := dm.metricsSource.CPUPercent(); err != nil || percent != 40 {
+				t.Errorf("Expected 40%%, got %v (err %v)", percent, err)
+			}
+			if cores, err := dm.metricsSource.PerCPUPercent(); err != nil || len(cores) != 2 {
+				t.Errorf("Expected 2 cores, got %v (err %v)", cores, err)
+			}
+		}(dm)
+	}
+	wg.Wait()
+	if provider.cpuCalls != 1 || provider.coreCalls != 1 {
+		t.Errorf("Expected one reading shared by every display, got %d total and %d per-core reads", provider.cpuCalls, provider.coreCalls)
+	}
+
+	now = now.Add(time.Second)
+	provider.cpu = 60
+	if percent, _ := displays[0].metricsSource.CPUPercent(); percent != 60 || provider.cpuCalls != 2 {
+		t.Errorf("Expected a new reading of 60%% on the next update, got %v after %d reads", percent, provider.cpuCalls)
+	}
+}
@@ -0,0 +1,73 @@
+package main
+
+import (
+	"fmt"
+	"log/slog"
+	"net"
+	"os"
+	"strings"
+)
+
+// systemd notification states
+const (
+	notifyReady    = "READY=1"
+	notifyWatchdog = "WATCHDOG=1"
+	notifyStopping = "STOPPING=1"
+)
+
+// Notifier interface for reporting service state to the service manager
+type Notifier interface {
+	Notify(state string) error
+}
+
+// RealNotifier implements Notifier with the sd_notify protocol: each state is
+// one datagram sent to the unix socket systemd passes in NOTIFY_SOCKET
+type RealNotifier struct {
+	addr *net.UnixAddr
+}
+
+// newNotifier returns a notifier for socket, or nil when the service isn't run
+// by systemd (socket is empty). A leading "@" names an abstract socket.
+func newNotifier(socket string) *RealNotifier {
+	if socket == "" {
+		return nil
+	}
+	if strings.HasPrefix(socket, "@") {
+		socket = "\x00" + socket[1:]
+	}
+	return &RealNotifier{addr: &net.UnixAddr{Name: socket, Net: "unixgram"}}
+}
+
+// Notify sends state, e.g. "READY=1", to the notify socket
+func (n *RealNotifier) Notify(state string) error {
+	conn, err := net.DialUnix("unixgram", nil, n.addr)
+	if err != nil {
+		return fmt.Errorf("failed to connect to notify socket: %v", err)
+	}
+	defer conn.Close()
+	if _, err := conn.Write([]byte(state)); err != nil {
+		return fmt.Errorf("failed to notify %s: %v", state, err)
+	}
+	return nil
+}
+
+// systemdNotifier returns the notifier for NOTIFY_SOCKET, or nil when it isn't set
+func systemdNotifier() Notifier {
+	if n := newNotifier(os.Getenv("NOTIFY_SOCKET")); n != nil {
+		return n
+	}
+	return nil
+}
+
+// notify reports state to systemd. The service is one process however many
+// displays it drives, so only the first display speaks for it. A failure is
+// only logged: the display keeps running, and a missed watchdog ping shows up
+// as a restart.
+func (dm *DisplayManager) notify(state string) {
+	if dm.notifier == nil || dm.displayIndex > 0 {
+		return
+	}
+	if err := dm.notifier.Notify(state); err != nil {
+		slog.Debug("failed to notify systemd", "state", state, "err", err)
+	}
+}
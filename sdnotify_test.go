@@ -0,0 +1,109 @@
+package main
+
+import (
+	"context"
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// listenNotifySocket opens a fake systemd notify socket and returns it with
+// its path. Socket paths are short, so it lives under the system temp dir.
+func listenNotifySocket(t *testing.T) (*net.UnixConn, string) {
+	dir, err := os.MkdirTemp("", "sdnotify")
+	if err != nil {
+		t.Fatalf("Failed to create temp dir: %v", err)
+	}
+	t.Cleanup(func() { os.RemoveAll(dir) })
+	path := filepath.Join(dir, "notify")
+	conn, err := net.ListenUnixgram("unixgram", &net.UnixAddr{Name: path, Net: "unixgram"})
+	if err != nil {
+		t.Fatalf("Failed to listen on notify socket: %v", err)
+	}
+	t.Cleanup(func() { conn.Close() })
+	return conn, path
+}
+
+// readNotifications reads the queued datagrams until none arrive
+func readNotifications(t *testing.T, conn *net.UnixConn) []string {
+	var states []string
+	buf := make([]byte, 256)
+	for {
+		conn.SetReadDeadline(time.Now().Add(100 * time.Millisecond))
+		n, err := conn.Read(buf)
+		if err != nil {
+			return states
+		}
+		states = append(states, string(buf[:n]))
+	}
+}
+
+// TestNewNotifier tests that no notifier is made outside systemd and that
+// abstract socket names are translated
+func TestNewNotifier(t *testing.T) {
+	if n := newNotifier(""); n != nil {
+		t.Errorf("Expected no notifier without NOTIFY_SOCKET, got %+v", n)
+	}
+	if n := newNotifier("@/org/test"); n == nil || n.addr.Name != "\x00/org/test" {
+		t.Errorf("Expected an abstract socket address, got %+v", n)
+	}
+	t.Setenv("NOTIFY_SOCKET", "")
+	if n := systemdNotifier(); n != nil {
+		t.Errorf("Expected a nil Notifier, got %+v", n)
+	}
+}
+
+// TestRunNotifiesSystemd tests that Run reports readiness after the first
+// frame, pings the watchdog on each update and reports stopping on shutdown
+func TestRunNotifiesSystemd(t *testing.T) {
+	conn, path := listenNotifySocket(t)
+	ticker := &MockTicker{c: make(chan time.Time)}
+	dm := &DisplayManager{
+		dev:      NewMockDisplay(t),
+		img:      blankFrame(),
+		notifier: newNotifier(path),
+		timeNow:  time.Now,
+		newTimer: func(d time.Duration) screenTimer {
+			return &MockTimer{c: make(chan time.Time), resets: make(chan time.Duration, 1)}
+		},
+		newTicker: func(d time.Duration) updateTicker { return ticker },
+		config: Config{
+			ScreenDuration: 60,
+			Screens:        []Screen{{Name: "Clock", Components: []Component{{Type: "time", X: 5, Y: 20}}}},
+		},
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error)
+	go func() {
+		done <- dm.Run(ctx)
+	}()
+	for i := 0; i < 2; i++ {
+		ticker.c <- time.Now()
+	}
+	cancel()
+	if err := <-done; err != nil {
+		t.Fatalf("Run failed: %v", err)
+	}
+
+	got := readNotifications(t, conn)
+	want := []string{notifyReady, notifyWatchdog, notifyWatchdog, notifyStopping}
+	if len(got) != len(want) {
+		t.Fatalf("Expected notifications %q, got %q", want, got)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("Notification %d: expected %q, got %q", i, want[i], got[i])
+		}
+	}
+
+	t.Run("Secondary display", func(t *testing.T) {
+		secondary := &DisplayManager{notifier: newNotifier(path), displayIndex: 1}
+		secondary.notify(notifyReady)
+		if got := readNotifications(t, conn); len(got) != 0 {
+			t.Errorf("Expected only the first display to notify, got %q", got)
+		}
+	})
+}
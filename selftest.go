@@ -0,0 +1,47 @@
+package main
+
+import (
+	"fmt"
+	"image"
+	"time"
+
+	"github.com/swilcox/go-monitor-ssd1306/render"
+)
+
+// selfTestStep is how long each self-test pattern stays on the panel
+const selfTestStep = 500 * time.Millisecond
+
+// fillImage lights every pixel of img
+func fillImage(img *image.RGBA) {
+	for i := 0; i < len(img.Pix); i += 4 {
+		img.Pix[i], img.Pix[i+1], img.Pix[i+2], img.Pix[i+3] = 0xff, 0xff, 0xff, 0xff
+	}
+}
+
+// checkerImage lights every other pixel of img, starting with the top left,
+// so neighboring rows and columns are driven to opposite states
+func checkerImage(img *image.RGBA) {
+	render.Clear(img)
+	b := img.Bounds()
+	for y := b.Min.Y; y < b.Max.Y; y++ {
+		for x := b.Min.X + (y-b.Min.Y)%2; x < b.Max.X; x += 2 {
+			i := img.PixOffset(x, y)
+			img.Pix[i], img.Pix[i+1], img.Pix[i+2] = 0xff, 0xff, 0xff
+		}
+	}
+}
+
+// runSelfTest shows an all-lit frame, a checkerboard and a blank frame in turn
+// so dead pixels, rows or columns stand out before anything else is drawn
+func (dm *DisplayManager) runSelfTest() error {
+	for _, pattern := range []func(*image.RGBA){fillImage, checkerImage, render.Clear} {
+		pattern(dm.img)
+		if err := dm.drawToDevice(dm.img); err != nil {
+			return fmt.Errorf("failed to draw self-test pattern: %v", err)
+		}
+		dm.sleep(selfTestStep)
+	}
+	// The first screen must be drawn even if it happens to match the last pattern
+	dm.prevFrame = nil
+	return nil
+}
@@ -0,0 +1,81 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"testing"
+	"time"
+)
+
+// TestRunSelfTest tests that selftest draws a full, a checkerboard and a blank
+// frame, each held for a step, before the splash and the first screen
+func TestRunSelfTest(t *testing.T) {
+	full, checker, blank := blankFrame(), blankFrame(), blankFrame()
+	fillImage(full)
+	checkerImage(checker)
+
+	mockDisplay := NewMockDisplay(t)
+	var frames [][]byte
+	var holds []time.Duration
+	dm := &DisplayManager{
+		dev:     mockDisplay,
+		img:     blankFrame(),
+		timeNow: time.Now,
+		config: Config{
+			ScreenDuration: 5,
+			SelfTest:       true,
+			SplashDuration: 2,
+			SplashText:     "Booting...",
+			Screens:        []Screen{{Name: "A", Components: []Component{{Type: "text", X: 5, Y: 20, Text: "First"}}}},
+		},
+		sleepFunc: func(d time.Duration) {
+			frames = append(frames, append([]byte(nil), mockDisplay.lastImage.Pix...))
+			holds = append(holds, d)
+		},
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if err := dm.Run(ctx); err != nil {
+		t.Fatalf("Run failed: %v", err)
+	}
+
+	// The three patterns, then the splash
+	if len(frames) != 4 {
+		t.Fatalf("Expected 4 held frames, got %d", len(frames))
+	}
+	for i, want := range []struct {
+		name string
+		pix  []byte
+	}{{"full", full.Pix}, {"checkerboard", checker.Pix}, {"blank", blank.Pix}} {
+		if !bytes.Equal(frames[i], want.pix) {
+			t.Errorf("Expected frame %d to be the %s pattern", i, want.name)
+		}
+		if holds[i] != selfTestStep {
+			t.Errorf("Expected the %s pattern to be held for %v, got %v", want.name, selfTestStep, holds[i])
+		}
+	}
+	if holds[3] != 2*time.Second {
+		t.Errorf("Expected the splash to follow the self-test, got a %v hold", holds[3])
+	}
+	// Three patterns, splash, first screen and the blank frame on shutdown
+	if mockDisplay.drawCount != 6 {
+		t.Errorf("Expected 6 draws, got %d", mockDisplay.drawCount)
+	}
+}
+
+// TestCheckerImage tests that neighboring pixels of the checkerboard differ
+func TestCheckerImage(t *testing.T) {
+	img := blankFrame()
+	checkerImage(img)
+	for _, p := range []struct{ x, y int }{{0, 0}, {2, 0}, {1, 1}, {width - 1, height - 1}} {
+		if img.RGBAAt(p.x, p.y).R == 0 {
+			t.Errorf("Expected pixel %d,%d to be lit", p.x, p.y)
+		}
+	}
+	for _, p := range []struct{ x, y int }{{1, 0}, {0, 1}, {width - 1, 0}} {
+		if img.RGBAAt(p.x, p.y).R != 0 {
+			t.Errorf("Expected pixel %d,%d to be unlit", p.x, p.y)
+		}
+	}
+}
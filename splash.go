@@ -0,0 +1,55 @@
+package main
+
+import (
+	"context"
+	_ "embed"
+	"fmt"
+	"time"
+
+	"github.com/swilcox/go-monitor-ssd1306/render"
+)
+
+// splashXBM is the logo shown at startup when no splash text is configured
+//
+//go:embed assets/splash.xbm
+var splashXBM string
+
+// splashLogo is the parsed startup logo
+var splashLogo = mustParseSplash()
+
+// mustParseSplash parses the embedded logo. It ships with the binary, so a
+// parse failure is a build mistake.
+func mustParseSplash() *render.Icon {
+	ic, err := render.ParseXBM(splashXBM)
+	if err != nil {
+		panic(fmt.Sprintf("splash: %v", err))
+	}
+	return ic
+}
+
+// drawSplash renders the startup splash into the frame buffer: the splash text
+// centered on the display, or the bundled logo when there is none
+func (dm *DisplayManager) drawSplash() {
+	dm.clearImage()
+	b := dm.img.Bounds()
+	if dm.config.SplashText == "" {
+		render.DrawIcon(dm.img, splashLogo, (b.Dx()-splashLogo.Width)/2, (b.Dy()-splashLogo.Height)/2)
+		return
+	}
+	face := dm.fontFace()
+	y := (b.Dy() + face.Metrics().Ascent.Ceil()) / 2
+	render.AddLabel(dm.img, face, render.AlignX(face, b.Dx()/2, dm.config.SplashText, "center"), y, dm.config.SplashText)
+}
+
+// showSplash draws the splash and holds it for splash_duration seconds before
+// the first screen is rendered, or until ctx is done
+func (dm *DisplayManager) showSplash(ctx context.Context) error {
+	dm.drawSplash()
+	if err := dm.drawToDevice(dm.img); err != nil {
+		return fmt.Errorf("failed to draw splash: %v", err)
+	}
+	// The first screen must be drawn even if it happens to match the splash
+	dm.prevFrame = nil
+	dm.sleepContext(ctx, time.Duration(dm.config.SplashDuration)*time.Second)
+	return nil
+}
@@ -0,0 +1,104 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"testing"
+	"time"
+)
+
+// TestEmbeddedSplash tests that the bundled logo parses
+func TestEmbeddedSplash(t *testing.T) {
+	if splashLogo.Width != 32 || splashLogo.Height != 24 {
+		t.Errorf("Expected a 32x24 logo, got %dx%d", splashLogo.Width, splashLogo.Height)
+	}
+}
+
+// TestRunSplash tests that the splash is drawn and held before the first
+// screen, and skipped when splash_duration is zero
+func TestRunSplash(t *testing.T) {
+	screens := []Screen{{Name: "A", Components: []Component{{Type: "text", X: 5, Y: 20, Text: "First"}}}}
+
+	tests := []struct {
+		name       string
+		config     Config
+		wantSplash bool
+	}{
+		{name: "logo", config: Config{ScreenDuration: 5, SplashDuration: 3, Screens: screens}, wantSplash: true},
+		{name: "text", config: Config{ScreenDuration: 5, SplashDuration: 3, SplashText: "Booting...", Screens: screens}, wantSplash: true},
+		{name: "disabled", config: Config{ScreenDuration: 5, Screens: screens}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockDisplay := NewMockDisplay(t)
+			var splashFrame []byte
+			var held time.Duration
+			dm := &DisplayManager{
+				dev:     mockDisplay,
+				img:     blankFrame(),
+				timeNow: time.Now,
+				config:  tt.config,
+				sleepFunc: func(d time.Duration) {
+					// Only the splash has been drawn while it is held
+					if mockDisplay.drawCount != 1 {
+						t.Errorf("Expected 1 draw before the splash wait, got %d", mockDisplay.drawCount)
+					}
+					splashFrame = append([]byte(nil), mockDisplay.lastImage.Pix...)
+					held = d
+				},
+			}
+
+			ctx, cancel := context.WithCancel(context.Background())
+			cancel()
+			if err := dm.Run(ctx); err != nil {
+				t.Fatalf("Run failed: %v", err)
+			}
+
+			if !tt.wantSplash {
+				if splashFrame != nil {
+					t.Error("Expected no splash when splash_duration is 0")
+				}
+				return
+			}
+			if held != 3*time.Second {
+				t.Errorf("Expected the splash to be held for 3s, got %v", held)
+			}
+
+			want := &DisplayManager{img: blankFrame(), config: tt.config}
+			want.drawSplash()
+			if !bytes.Equal(splashFrame, want.img.Pix) {
+				t.Error("Expected the first Draw to be the splash frame")
+			}
+			if bytes.Equal(splashFrame, make([]byte, len(splashFrame))) {
+				t.Error("Expected the splash frame to have lit pixels")
+			}
+			// Splash, first screen, then the blank frame on shutdown
+			if mockDisplay.drawCount != 3 {
+				t.Errorf("Expected 3 draws, got %d", mockDisplay.drawCount)
+			}
+		})
+	}
+}
+
+// TestSplashStopsOnShutdown tests that a long splash doesn't delay shutdown
+func TestSplashStopsOnShutdown(t *testing.T) {
+	dm := &DisplayManager{
+		dev:     NewMockDisplay(t),
+		img:     blankFrame(),
+		timeNow: time.Now,
+		config:  Config{SplashDuration: 60},
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	done := make(chan error)
+	go func() { done <- dm.showSplash(ctx) }()
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Fatalf("showSplash failed: %v", err)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("Expected the splash to stop waiting once the context is done")
+	}
+}
@@ -0,0 +1,158 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"image"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// minHealthStaleAfter is how long after the last render /healthz starts
+// failing, at least; slower update intervals allow three missed updates
+const minHealthStaleAfter = 10 * time.Second
+
+// healthStaleAfter returns how long /healthz tolerates no renders when the
+// display updates every interval
+func healthStaleAfter(interval time.Duration) time.Duration {
+	return max(minHealthStaleAfter, 3*interval)
+}
+
+// displayStatus is the snapshot of the render loop served by /status
+type displayStatus struct {
+	Screen     int    `json:"screen"`
+	ScreenName string `json:"screen_name"`
+	Inverted   bool   `json:"inverted"`
+	Contrast   uint8  `json:"contrast"`
+	Paused     bool   `json:"paused"`
+
+	screens    int
+	lastRender time.Time
+	staleAfter time.Duration // see healthStaleAfter
+}
+
+// publishStatus records the render loop's state for the HTTP handlers, which
+// run on other goroutines
+func (dm *DisplayManager) publishStatus() {
+	status := displayStatus{
+		Screen:     dm.currentScreen,
+		Inverted:   dm.isInverted,
+		Contrast:   dm.contrast,
+		Paused:     dm.paused,
+		screens:    len(dm.config.Screens),
+		lastRender: dm.timeNow(),
+		staleAfter: healthStaleAfter(dm.config.updateInterval()),
+	}
+	if dm.currentScreen < len(dm.config.Screens) {
+		status.ScreenName = dm.config.Screens[dm.currentScreen].Name
+	}
+
+	dm.statusMu.Lock()
+	dm.status = status
+	dm.statusMu.Unlock()
+}
+
+// publishFrame keeps a copy of the frame just rendered for CurrentFrame
+func (dm *DisplayManager) publishFrame() {
+	dm.statusMu.Lock()
+	defer dm.statusMu.Unlock()
+	if dm.frame == nil || dm.frame.Bounds() != dm.img.Bounds() {
+		dm.frame = image.NewRGBA(dm.img.Bounds())
+	}
+	copy(dm.frame.Pix, dm.img.Pix)
+}
+
+// CurrentFrame returns a copy of the most recently rendered frame, before any
+// anti_burnin shift or rotation, or nil before the first render. It is safe to
+// call while the display is running.
+func (dm *DisplayManager) CurrentFrame() *image.RGBA {
+	dm.statusMu.Lock()
+	defer dm.statusMu.Unlock()
+	if dm.frame == nil {
+		return nil
+	}
+	frame := image.NewRGBA(dm.frame.Bounds())
+	copy(frame.Pix, dm.frame.Pix)
+	return frame
+}
+
+// currentStatus returns the last published status
+func (dm *DisplayManager) currentStatus() displayStatus {
+	dm.statusMu.Lock()
+	defer dm.statusMu.Unlock()
+	return dm.status
+}
+
+// statusHandler serves /healthz, which fails once the render loop stops
+// publishing, /status with the current screen as JSON, and the POST
+// endpoints /screen/{index}, /pause and /resume that control rotation
+func (dm *DisplayManager) statusHandler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/healthz", func(w http.ResponseWriter, r *http.Request) {
+		status := dm.currentStatus()
+		if status.lastRender.IsZero() || dm.timeNow().Sub(status.lastRender) > status.staleAfter {
+			http.Error(w, "render loop stalled", http.StatusServiceUnavailable)
+			return
+		}
+		w.Write([]byte("ok\n"))
+	})
+	mux.HandleFunc("/status", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		if err := json.NewEncoder(w).Encode(dm.currentStatus()); err != nil {
+			slog.Warn("failed to write status", "err", err)
+		}
+	})
+	mux.HandleFunc("POST /screen/{index}", func(w http.ResponseWriter, r *http.Request) {
+		index, err := strconv.Atoi(r.PathValue("index"))
+		if err != nil {
+			http.Error(w, "screen index must be a number", http.StatusBadRequest)
+			return
+		}
+		if screens := dm.currentStatus().screens; index < 0 || index >= screens {
+			http.Error(w, fmt.Sprintf("screen %d does not exist, there are %d screens", index, screens), http.StatusNotFound)
+			return
+		}
+		dm.sendCommand(w, r, displayCommand{kind: cmdShowScreen, screen: index})
+	})
+	mux.HandleFunc("POST /pause", func(w http.ResponseWriter, r *http.Request) {
+		dm.sendCommand(w, r, displayCommand{kind: cmdPause})
+	})
+	mux.HandleFunc("POST /resume", func(w http.ResponseWriter, r *http.Request) {
+		dm.sendCommand(w, r, displayCommand{kind: cmdResume})
+	})
+	return mux
+}
+
+// sendCommand queues a command for Run's loop, giving up if the client goes away
+func (dm *DisplayManager) sendCommand(w http.ResponseWriter, r *http.Request, cmd displayCommand) {
+	select {
+	case dm.commands <- cmd:
+		w.WriteHeader(http.StatusAccepted)
+	case <-r.Context().Done():
+		http.Error(w, "display is busy", http.StatusServiceUnavailable)
+	}
+}
+
+// startHTTPServer serves handler on addr in the background. Failing to
+// listen is logged rather than stopping the display.
+func startHTTPServer(addr string, handler http.Handler) *http.Server {
+	server := &http.Server{Addr: addr, Handler: handler}
+	go func() {
+		if err := server.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+			slog.Error("http server failed", "addr", addr, "err", err)
+		}
+	}()
+	return server
+}
+
+// stopHTTPServer gives in-flight requests a moment to finish, then closes the server
+func stopHTTPServer(server *http.Server) {
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+	if err := server.Shutdown(ctx); err != nil {
+		slog.Warn("failed to stop http server", "err", err)
+	}
+}
@@ -0,0 +1,203 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/swilcox/go-monitor-ssd1306/render"
+)
+
+// TestStatusHandler tests the /status JSON and /healthz liveness responses
+func TestStatusHandler(t *testing.T) {
+	now := time.Date(2024, 3, 9, 14, 0, 0, 0, time.Local)
+	dm := &DisplayManager{
+		config: Config{Screens: []Screen{
+			{Name: "System"},
+			{Name: "Network"},
+		}},
+		currentScreen: 1,
+		isInverted:    true,
+		contrast:      128,
+		timeNow:       func() time.Time { return now },
+	}
+	handler := dm.statusHandler()
+
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/healthz", nil))
+	if rec.Code != http.StatusServiceUnavailable {
+		t.Errorf("Expected /healthz to fail before the first render, got %d", rec.Code)
+	}
+
+	dm.publishStatus()
+
+	rec = httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/status", nil))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("Expected /status to return 200, got %d", rec.Code)
+	}
+	var got map[string]interface{}
+	if err := json.Unmarshal(rec.Body.Bytes(), &got); err != nil {
+		t.Fatalf("Failed to decode status: %v", err)
+	}
+	want := map[string]interface{}{
+		"screen":      1.0,
+		"screen_name": "Network",
+		"inverted":    true,
+		"contrast":    128.0,
+	}
+	for key, value := range want {
+		if got[key] != value {
+			t.Errorf("Expected %s to be %v, got %v", key, value, got[key])
+		}
+	}
+
+	rec = httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/healthz", nil))
+	if rec.Code != http.StatusOK {
+		t.Errorf("Expected /healthz to return 200 after a render, got %d", rec.Code)
+	}
+
+	now = now.Add(time.Minute)
+	rec = httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/healthz", nil))
+	if rec.Code != http.StatusServiceUnavailable {
+		t.Errorf("Expected /healthz to fail once renders stop, got %d", rec.Code)
+	}
+}
+
+// TestHealthzSlowUpdates tests that /healthz allows three missed updates when
+// update_interval is longer than the default staleness limit
+func TestHealthzSlowUpdates(t *testing.T) {
+	tests := []struct {
+		interval int
+		healthy  time.Duration
+		stalled  time.Duration
+	}{
+		{1, 10 * time.Second, 11 * time.Second},
+		{30, 90 * time.Second, 91 * time.Second},
+	}
+
+	for _, tt := range tests {
+		t.Run(fmt.Sprintf("%ds", tt.interval), func(t *testing.T) {
+			start := time.Date(2024, 3, 9, 14, 0, 0, 0, time.Local)
+			now := start
+			dm := &DisplayManager{
+				config:  Config{UpdateInterval: tt.interval, Screens: []Screen{{Name: "Slow"}}},
+				timeNow: func() time.Time { return now },
+			}
+			handler := dm.statusHandler()
+			dm.publishStatus()
+
+			for _, check := range []struct {
+				after time.Duration
+				want  int
+			}{{tt.healthy, http.StatusOK}, {tt.stalled, http.StatusServiceUnavailable}} {
+				now = start.Add(check.after)
+				rec := httptest.NewRecorder()
+				handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/healthz", nil))
+				if rec.Code != check.want {
+					t.Errorf("After %v: expected %d, got %d", check.after, check.want, rec.Code)
+				}
+			}
+		})
+	}
+}
+
+// TestControlEndpoints tests that the POST endpoints queue commands and
+// reject screens that don't exist
+func TestControlEndpoints(t *testing.T) {
+	dm := &DisplayManager{
+		config:   Config{Screens: []Screen{{Name: "System"}, {Name: "Network"}}},
+		timeNow:  time.Now,
+		commands: make(chan displayCommand, commandQueueSize),
+	}
+	dm.publishStatus()
+	handler := dm.statusHandler()
+
+	tests := []struct {
+		path     string
+		wantCode int
+		wantCmd  *displayCommand
+	}{
+		{"/screen/1", http.StatusAccepted, &displayCommand{kind: cmdShowScreen, screen: 1}},
+		{"/screen/2", http.StatusNotFound, nil},
+		{"/screen/-1", http.StatusNotFound, nil},
+		{"/screen/next", http.StatusBadRequest, nil},
+		{"/pause", http.StatusAccepted, &displayCommand{kind: cmdPause}},
+		{"/resume", http.StatusAccepted, &displayCommand{kind: cmdResume}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			rec := httptest.NewRecorder()
+			handler.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, tt.path, nil))
+			if rec.Code != tt.wantCode {
+				t.Errorf("Expected status %d, got %d", tt.wantCode, rec.Code)
+			}
+			select {
+			case cmd := <-dm.commands:
+				if tt.wantCmd == nil || cmd != *tt.wantCmd {
+					t.Errorf("Expected command %v, got %v", tt.wantCmd, cmd)
+				}
+			default:
+				if tt.wantCmd != nil {
+					t.Errorf("Expected command %v to be queued", *tt.wantCmd)
+				}
+			}
+		})
+	}
+
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/pause", nil))
+	if rec.Code != http.StatusMethodNotAllowed {
+		t.Errorf("Expected GET /pause to be rejected, got %d", rec.Code)
+	}
+}
+
+// TestCurrentFrame tests that CurrentFrame returns the latest render as a copy
+// that later renders and callers' changes don't affect
+func TestCurrentFrame(t *testing.T) {
+	dm := &DisplayManager{
+		dev:     NewMockDisplay(t),
+		img:     blankFrame(),
+		timeNow: time.Now,
+		config: Config{Screens: []Screen{
+			{Name: "First", Components: []Component{{Type: "text", X: 5, Y: 12, Text: "first"}}},
+			{Name: "Second", Components: []Component{{Type: "text", X: 5, Y: 12, Text: "second"}}},
+		}},
+	}
+	if dm.CurrentFrame() != nil {
+		t.Error("Expected no frame before the first render")
+	}
+
+	if err := dm.renderCurrentScreen(); err != nil {
+		t.Fatalf("Failed to render screen: %v", err)
+	}
+	first := dm.CurrentFrame()
+	if want := labelImage(5, 12, "first"); !bytes.Equal(first.Pix, want.Pix) {
+		t.Fatal("Expected the first screen's frame")
+	}
+
+	// Scribbling on the copy must not reach the manager
+	render.Invert(first)
+	if want := labelImage(5, 12, "first"); !bytes.Equal(dm.CurrentFrame().Pix, want.Pix) {
+		t.Error("Expected changes to a returned frame not to affect the next one")
+	}
+
+	dm.currentScreen = 1
+	if err := dm.renderCurrentScreen(); err != nil {
+		t.Fatalf("Failed to render screen: %v", err)
+	}
+	if want := labelImage(5, 12, "second"); !bytes.Equal(dm.CurrentFrame().Pix, want.Pix) {
+		t.Error("Expected the frame to follow the latest render")
+	}
+	render.Invert(first)
+	if want := labelImage(5, 12, "first"); !bytes.Equal(first.Pix, want.Pix) {
+		t.Error("Expected an earlier frame not to change with later renders")
+	}
+}
@@ -0,0 +1,52 @@
+package main
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+)
+
+// expandZones turns the temperature component's zones into file paths.
+// Glob patterns such as /sys/class/thermal/thermal_zone*/temp expand to the
+// files that exist; plain paths are kept so a missing file is reported when
+// read.
+func expandZones(zones []string) []string {
+	var paths []string
+	for _, zone := range zones {
+		if !strings.ContainsAny(zone, `*?[\`) {
+			paths = append(paths, zone)
+			continue
+		}
+		// Patterns are checked by validateConfig, so the error is always nil
+		matches, _ := filepath.Glob(zone)
+		paths = append(paths, matches...)
+	}
+	return paths
+}
+
+// hottestZone reads every zone and returns the highest temperature in Celsius
+// with the file it came from. Zones that can't be read are skipped.
+func hottestZone(r TemperatureReader, zones []string) (float64, string, error) {
+	var (
+		hottest float64
+		path    string
+		lastErr error
+	)
+	for _, zone := range expandZones(zones) {
+		temp, err := r.FileTemperature(zone)
+		if err != nil {
+			lastErr = err
+			continue
+		}
+		if path == "" || temp > hottest {
+			hottest, path = temp, zone
+		}
+	}
+	if path == "" {
+		if lastErr != nil {
+			return 0, "", fmt.Errorf("no readable temperature zone: %v", lastErr)
+		}
+		return 0, "", fmt.Errorf("no temperature zone matches %s", strings.Join(zones, ", "))
+	}
+	return hottest, path, nil
+}
@@ -0,0 +1,107 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/swilcox/go-monitor-ssd1306/render"
+)
+
+// TestHottestZone tests that the hottest readable zone is chosen and broken
+// or missing zones are skipped
+func TestHottestZone(t *testing.T) {
+	dir := t.TempDir()
+	zones := map[string]string{
+		"thermal_zone0": "40000\n",
+		"thermal_zone1": "71500\n",
+		"thermal_zone2": "55000\n",
+		"thermal_zone3": "not a number\n",
+	}
+	for name, contents := range zones {
+		if err := os.MkdirAll(filepath.Join(dir, name), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(filepath.Join(dir, name, "temp"), []byte(contents), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	reader := &RealTemperatureReader{}
+
+	tests := []struct {
+		name     string
+		zones    []string
+		want     float64
+		wantZone string
+		wantErr  bool
+	}{
+		{name: "Glob", zones: []string{filepath.Join(dir, "thermal_zone*", "temp")}, want: 71.5, wantZone: "thermal_zone1"},
+		{
+			name:     "Listed paths",
+			zones:    []string{filepath.Join(dir, "thermal_zone0", "temp"), filepath.Join(dir, "thermal_zone2", "temp")},
+			want:     55,
+			wantZone: "thermal_zone2",
+		},
+		{
+			name:     "Unreadable zones skipped",
+			zones:    []string{filepath.Join(dir, "missing", "temp"), filepath.Join(dir, "thermal_zone3", "temp"), filepath.Join(dir, "thermal_zone0", "temp")},
+			want:     40,
+			wantZone: "thermal_zone0",
+		},
+		{name: "Nothing readable", zones: []string{filepath.Join(dir, "thermal_zone3", "temp")}, wantErr: true},
+		{name: "No matches", zones: []string{filepath.Join(dir, "hwmon*", "temp")}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			temp, path, err := hottestZone(reader, tt.zones)
+			if tt.wantErr {
+				if err == nil {
+					t.Errorf("Expected an error, got %v from %s", temp, path)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Failed to read zones: %v", err)
+			}
+			if temp != tt.want || render.ZoneName(path) != tt.wantZone {
+				t.Errorf("Expected %v from %s, got %v from %s", tt.want, tt.wantZone, temp, path)
+			}
+		})
+	}
+}
+
+// TestTemperatureZonesComponent tests that the temperature component shows
+// the hottest zone, naming it when show_zone is set
+func TestTemperatureZonesComponent(t *testing.T) {
+	provider := &MockMetricsProvider{temps: &MockTemperatureReader{files: map[string]float64{
+		"/sys/class/thermal/thermal_zone0/temp": 48.2,
+		"/sys/class/thermal/thermal_zone1/temp": 63.4,
+	}}}
+	zones := []string{"/sys/class/thermal/thermal_zone0/temp", "/sys/class/thermal/thermal_zone1/temp"}
+
+	tests := []struct {
+		name      string
+		comp      Component
+		wantLabel string
+	}{
+		{name: "Hottest", comp: Component{Type: "temperature", X: 5, Y: 20, Label: "SoC", Zones: zones}, wantLabel: "SoC: 63.4 C"},
+		{name: "Show zone", comp: Component{Type: "temperature", X: 5, Y: 20, Label: "SoC", Zones: zones, ShowZone: true}, wantLabel: "SoC: 63.4 C thermal_zone1"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dm := &DisplayManager{
+				metricsSource: provider,
+				img:           blankFrame(),
+			}
+			if err := dm.renderComponent(tt.comp); err != nil {
+				t.Fatalf("Failed to render component: %v", err)
+			}
+			if want := labelImage(5, 20, tt.wantLabel); !bytes.Equal(dm.img.Pix, want.Pix) {
+				t.Errorf("Rendered image does not match %q", tt.wantLabel)
+			}
+		})
+	}
+}
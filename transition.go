@@ -0,0 +1,107 @@
+package main
+
+import (
+	"context"
+	"image"
+	"image/draw"
+	"time"
+)
+
+const (
+	transitionDuration = 300 * time.Millisecond
+	transitionSteps    = 6
+)
+
+// bayer4 is a 4x4 ordered-dither matrix. A fade shows the new frame at a
+// pixel once the step passes its threshold, approximating grey on a 1-bit panel.
+var bayer4 = [4][4]int{
+	{0, 8, 2, 10},
+	{12, 4, 14, 6},
+	{3, 11, 1, 9},
+	{15, 7, 13, 5},
+}
+
+// transitionTo renders the current screen once, animates to it from the
+// previous frame and shows it
+func (dm *DisplayManager) transitionTo(from []byte) error {
+	dm.renderFrame()
+	if err := dm.playTransition(from); err != nil {
+		return err
+	}
+	return dm.presentFrame()
+}
+
+// playTransition animates from the previous frame to the one renderFrame drew
+// using the configured transition. The final frame is left for presentFrame
+// to draw.
+func (dm *DisplayManager) playTransition(from []byte) error {
+	transition := dm.config.Transition
+	if transition == "" || transition == "none" || len(from) != len(dm.img.Pix) {
+		return nil
+	}
+
+	bounds := dm.img.Bounds()
+	old := &image.RGBA{Pix: from, Stride: dm.img.Stride, Rect: bounds}
+
+	var wide *image.RGBA
+	if transition == "slide_left" {
+		// Old and new side by side; each step shows a window further right
+		w := bounds.Dx()
+		wide = image.NewRGBA(image.Rect(0, 0, 2*w, bounds.Dy()))
+		draw.Draw(wide, bounds, old, image.Point{}, draw.Src)
+		draw.Draw(wide, bounds.Add(image.Point{X: w}), dm.img, image.Point{}, draw.Src)
+	}
+
+	frame := image.NewRGBA(bounds)
+	for step := 1; step < transitionSteps; step++ {
+		switch transition {
+		case "slide_left":
+			offset := bounds.Dx() * step / transitionSteps
+			draw.Draw(frame, bounds, wide, image.Point{X: offset}, draw.Src)
+		case "fade":
+			ditherFrames(frame, old, dm.img, step*16/transitionSteps)
+		}
+		err := dm.drawToDevice(frame)
+		if err != nil {
+			return err
+		}
+		dm.sleep(transitionDuration / transitionSteps)
+	}
+	return nil
+}
+
+// ditherFrames fills dst with to wherever the dither threshold is below
+// level (0-16) and with from elsewhere
+func ditherFrames(dst, from, to *image.RGBA, level int) {
+	b := dst.Bounds()
+	for y := b.Min.Y; y < b.Max.Y; y++ {
+		for x := b.Min.X; x < b.Max.X; x++ {
+			src := from
+			if bayer4[y%4][x%4] < level {
+				src = to
+			}
+			dst.SetRGBA(x, y, src.RGBAAt(x, y))
+		}
+	}
+}
+
+// sleep pauses between transition steps
+func (dm *DisplayManager) sleep(d time.Duration) {
+	if dm.sleepFunc != nil {
+		dm.sleepFunc(d)
+		return
+	}
+	time.Sleep(d)
+}
+
+// sleepContext pauses like sleep, returning early when ctx is done
+func (dm *DisplayManager) sleepContext(ctx context.Context, d time.Duration) {
+	if dm.sleepFunc != nil {
+		dm.sleepFunc(d)
+		return
+	}
+	select {
+	case <-ctx.Done():
+	case <-time.After(d):
+	}
+}
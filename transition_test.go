@@ -0,0 +1,134 @@
+package main
+
+import (
+	"bytes"
+	"image"
+	"testing"
+	"time"
+)
+
+// newTransitionManager returns a DisplayManager with two different screens
+// whose first screen has already been drawn
+func newTransitionManager(t *testing.T, transition string) (*DisplayManager, *MockDisplay) {
+	mockDisplay := NewMockDisplay(t)
+	dm := &DisplayManager{
+		dev:            mockDisplay,
+		networkChecker: &MockNetworkChecker{ipAddress: "192.168.1.100"},
+		img:            blankFrame(),
+		timeNow:        time.Now,
+		sleepFunc:      func(time.Duration) {},
+		config: Config{
+			Transition: transition,
+			Screens: []Screen{
+				{Name: "A", Components: []Component{{Type: "text", X: 5, Y: 20, Text: "First"}}},
+				{Name: "B", Components: []Component{{Type: "text", X: 5, Y: 40, Text: "Second"}}},
+			},
+		},
+	}
+	if err := dm.renderCurrentScreen(); err != nil {
+		t.Fatalf("Failed to render screen: %v", err)
+	}
+	return dm, mockDisplay
+}
+
+// TestTransitions tests that animated transitions issue intermediate Draw
+// calls before the final frame, and that none switches directly
+func TestTransitions(t *testing.T) {
+	tests := []struct {
+		transition string
+		wantDraws  int
+	}{
+		{"none", 1},
+		{"slide_left", transitionSteps},
+		{"fade", transitionSteps},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.transition, func(t *testing.T) {
+			dm, mockDisplay := newTransitionManager(t, tt.transition)
+			from := append([]byte(nil), dm.prevFrame...)
+			drawsBefore := mockDisplay.drawCount
+
+			dm.currentScreen = 1
+			if err := dm.transitionTo(from); err != nil {
+				t.Fatalf("Transition failed: %v", err)
+			}
+
+			if got := mockDisplay.drawCount - drawsBefore; got != tt.wantDraws {
+				t.Errorf("Expected %d Draw calls, got %d", tt.wantDraws, got)
+			}
+			if want := labelImage(5, 40, "Second"); !bytes.Equal(mockDisplay.lastImage.Pix, want.Pix) {
+				t.Error("Expected the new screen to be drawn last")
+			}
+		})
+	}
+}
+
+// TestTransitionSamplesOnce tests that the incoming screen is rendered once
+// per rotation, so its components aren't sampled again after the animation
+func TestTransitionSamplesOnce(t *testing.T) {
+	for _, transition := range []string{"none", "slide_left", "fade"} {
+		t.Run(transition, func(t *testing.T) {
+			provider := &countingMetricsProvider{MockMetricsProvider: MockMetricsProvider{cpu: 50}}
+			dm := &DisplayManager{
+				dev:           NewMockDisplay(t),
+				metricsSource: provider,
+				img:           blankFrame(),
+				timeNow:       time.Now,
+				sleepFunc:     func(time.Duration) {},
+				config: Config{
+					Transition: transition,
+					Screens: []Screen{
+						{Name: "A", Components: []Component{{Type: "text", X: 5, Y: 20, Text: "First"}}},
+						{Name: "B", Components: []Component{{Type: "cpugraph", X: 5, Y: 20, BarWidth: 10}}},
+					},
+				},
+			}
+			if err := dm.renderCurrentScreen(); err != nil {
+				t.Fatalf("Failed to render screen: %v", err)
+			}
+			from := append([]byte(nil), dm.prevFrame...)
+
+			dm.currentScreen = 1
+			if err := dm.transitionTo(from); err != nil {
+				t.Fatalf("Transition failed: %v", err)
+			}
+			if provider.cpuCalls != 1 {
+				t.Errorf("Expected one CPU reading for the transition, got %d", provider.cpuCalls)
+			}
+		})
+	}
+}
+
+// TestDitherFrames tests that fade levels move from the old frame to the new one
+func TestDitherFrames(t *testing.T) {
+	rect := image.Rect(0, 0, 8, 8)
+	from, to := image.NewRGBA(rect), image.NewRGBA(rect)
+	for i := range to.Pix {
+		to.Pix[i] = 0xff
+	}
+
+	lit := func(img *image.RGBA) int {
+		n := 0
+		for i := 0; i < len(img.Pix); i += 4 {
+			if img.Pix[i] != 0 {
+				n++
+			}
+		}
+		return n
+	}
+
+	dst := image.NewRGBA(rect)
+	prev := -1
+	for level := 0; level <= 16; level += 4 {
+		ditherFrames(dst, from, to, level)
+		got := lit(dst)
+		if want := 64 * level / 16; got != want {
+			t.Errorf("Level %d: expected %d new pixels, got %d", level, want, got)
+		}
+		if got <= prev {
+			t.Errorf("Level %d: expected more new pixels than the previous level", level)
+		}
+		prev = got
+	}
+}
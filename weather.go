@@ -0,0 +1,146 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"time"
+)
+
+const (
+	defaultWeatherURL      = "https://api.openweathermap.org/data/2.5/weather"
+	defaultWeatherInterval = 15 // minutes
+	weatherRetryInterval   = time.Minute
+	httpFetchTimeout       = 5 * time.Second
+	maxFetchBytes          = 1 << 20
+)
+
+// WeatherConfig configures where the weather component fetches conditions from
+type WeatherConfig struct {
+	URL      string `yaml:"url" json:"url"`           // OpenWeatherMap-style current weather endpoint, defaults to OpenWeatherMap
+	APIKey   string `yaml:"api_key" json:"api_key"`   // sent as appid
+	Location string `yaml:"location" json:"location"` // city name sent as q, e.g. "London,uk"
+	Interval int    `yaml:"interval" json:"interval"` // minutes between fetches, defaults to 15
+}
+
+// requestURL returns the endpoint with the location, key and metric units
+// added to its query
+func (c WeatherConfig) requestURL() (string, error) {
+	base := c.URL
+	if base == "" {
+		base = defaultWeatherURL
+	}
+	u, err := url.Parse(base)
+	if err != nil {
+		return "", fmt.Errorf("invalid weather url: %v", err)
+	}
+	query := u.Query()
+	if c.Location != "" {
+		query.Set("q", c.Location)
+	}
+	if c.APIKey != "" {
+		query.Set("appid", c.APIKey)
+	}
+	query.Set("units", "metric")
+	u.RawQuery = query.Encode()
+	return u.String(), nil
+}
+
+// interval returns how long a fetched report is reused
+func (c WeatherConfig) interval() time.Duration {
+	if c.Interval == 0 {
+		return defaultWeatherInterval * time.Minute
+	}
+	return time.Duration(c.Interval) * time.Minute
+}
+
+// validateWeather checks the weather settings a weather component needs, so
+// a bad location or endpoint is reported at load instead of on every fetch
+func validateWeather(config WeatherConfig, where string) []string {
+	var problems []string
+	if config.Location == "" {
+		problems = append(problems, fmt.Sprintf("%s: weather location must be set", where))
+	}
+	if config.URL == "" && config.APIKey == "" {
+		problems = append(problems, fmt.Sprintf("%s: weather api_key must be set for OpenWeatherMap", where))
+	}
+	if config.URL != "" {
+		if u, err := url.Parse(config.URL); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			problems = append(problems, fmt.Sprintf("%s: weather url must be an http or https URL, got %q", where, config.URL))
+		}
+	}
+	return problems
+}
+
+// HTTPGetter interface for fetching a URL; *http.Client implements it
+type HTTPGetter interface {
+	Get(url string) (*http.Response, error)
+}
+
+// fetchURL returns the body of a successful GET
+func fetchURL(client HTTPGetter, url string) ([]byte, error) {
+	resp, err := client.Get(url)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("unexpected status %s", resp.Status)
+	}
+	return io.ReadAll(io.LimitReader(resp.Body, maxFetchBytes))
+}
+
+// weatherReport holds current conditions
+type weatherReport struct {
+	tempCelsius float64
+	description string
+}
+
+// parseWeather parses an OpenWeatherMap current weather response fetched
+// with metric units
+func parseWeather(data []byte) (weatherReport, error) {
+	var resp struct {
+		Weather []struct {
+			Main string `json:"main"`
+		} `json:"weather"`
+		Main struct {
+			Temp *float64 `json:"temp"`
+		} `json:"main"`
+	}
+	if err := json.Unmarshal(data, &resp); err != nil {
+		return weatherReport{}, fmt.Errorf("failed to parse weather: %v", err)
+	}
+	if resp.Main.Temp == nil {
+		return weatherReport{}, fmt.Errorf("weather response has no temperature")
+	}
+	report := weatherReport{tempCelsius: *resp.Main.Temp}
+	if len(resp.Weather) > 0 {
+		report.description = resp.Weather[0].Main
+	}
+	return report, nil
+}
+
+// currentWeather returns the cached report, fetching a new one in the
+// background once the configured interval has passed, or a minute after a
+// failed fetch. ok is false until a fetch has succeeded.
+func (dm *DisplayManager) currentWeather() (weatherReport, bool) {
+	client, config := dm.httpClient, dm.config.Weather
+	return dm.weather.get("weather", dm.timeNow(), config.interval(), weatherRetryInterval, dm.goAsync, func() (weatherReport, error) {
+		return fetchWeather(client, config)
+	})
+}
+
+// fetchWeather requests current conditions from the configured endpoint
+func fetchWeather(client HTTPGetter, config WeatherConfig) (weatherReport, error) {
+	u, err := config.requestURL()
+	if err != nil {
+		return weatherReport{}, err
+	}
+	data, err := fetchURL(client, u)
+	if err != nil {
+		return weatherReport{}, fmt.Errorf("failed to fetch weather: %v", err)
+	}
+	return parseWeather(data)
+}
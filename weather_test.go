@@ -0,0 +1,147 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"testing"
+	"time"
+)
+
+// owmResponse is a trimmed OpenWeatherMap current weather response
+const owmResponse = `{
+  "coord": {"lon": -0.1257, "lat": 51.5085},
+  "weather": [{"id": 803, "main": "Clouds", "description": "broken clouds", "icon": "04d"}],
+  "base": "stations",
+  "main": {"temp": 21.64, "feels_like": 21.3, "temp_min": 20.1, "temp_max": 23.2, "pressure": 1014, "humidity": 55},
+  "visibility": 10000,
+  "name": "London",
+  "cod": 200
+}`
+
+// MockHTTPGetter implements HTTPGetter with canned responses
+type MockHTTPGetter struct {
+	status int
+	body   string
+	err    error
+	urls   []string
+}
+
+func (m *MockHTTPGetter) Get(url string) (*http.Response, error) {
+	m.urls = append(m.urls, url)
+	if m.err != nil {
+		return nil, m.err
+	}
+	status := m.status
+	if status == 0 {
+		status = http.StatusOK
+	}
+	return &http.Response{
+		StatusCode: status,
+		Status:     fmt.Sprintf("%d %s", status, http.StatusText(status)),
+		Body:       io.NopCloser(bytes.NewBufferString(m.body)),
+	}, nil
+}
+
+// TestParseWeather tests parsing OpenWeatherMap responses
+func TestParseWeather(t *testing.T) {
+	tests := []struct {
+		name    string
+		data    string
+		want    weatherReport
+		wantErr bool
+	}{
+		{"Clouds", owmResponse, weatherReport{tempCelsius: 21.64, description: "Clouds"}, false},
+		{"Below zero", `{"weather":[{"main":"Snow"}],"main":{"temp":-3.5}}`, weatherReport{tempCelsius: -3.5, description: "Snow"}, false},
+		{"No conditions", `{"main":{"temp":12}}`, weatherReport{tempCelsius: 12}, false},
+		{"Error response", `{"cod":401,"message":"Invalid API key."}`, weatherReport{}, true},
+		{"Not JSON", `<html>Bad Gateway</html>`, weatherReport{}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parseWeather([]byte(tt.data))
+			if tt.wantErr {
+				if err == nil {
+					t.Errorf("Expected an error, got %+v", got)
+				}
+				return
+			}
+			if err != nil || got != tt.want {
+				t.Errorf("Expected %+v, got %+v (err %v)", tt.want, got, err)
+			}
+		})
+	}
+}
+
+// TestWeatherRequestURL tests the query sent to the weather endpoint
+func TestWeatherRequestURL(t *testing.T) {
+	got, err := WeatherConfig{APIKey: "secret", Location: "London,uk"}.requestURL()
+	if err != nil {
+		t.Fatal(err)
+	}
+	u, err := url.Parse(got)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if u.Host != "api.openweathermap.org" {
+		t.Errorf("Expected the OpenWeatherMap host, got %s", u.Host)
+	}
+	query := u.Query()
+	if query.Get("q") != "London,uk" || query.Get("appid") != "secret" || query.Get("units") != "metric" {
+		t.Errorf("Unexpected query %v", query)
+	}
+}
+
+// TestWeatherComponent tests rendering, caching and failure handling
+func TestWeatherComponent(t *testing.T) {
+	now := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+	client := &MockHTTPGetter{body: owmResponse}
+	dm := &DisplayManager{
+		httpClient: client,
+		asyncFunc:  func(f func()) { f() },
+		config:     Config{Weather: WeatherConfig{APIKey: "secret", Location: "London", Interval: 10}},
+		img:        blankFrame(),
+		timeNow:    func() time.Time { return now },
+	}
+	comp := Component{Type: "weather", X: 5, Y: 12}
+	render := func(want string) {
+		t.Helper()
+		dm.clearImage()
+		if err := dm.renderComponent(comp); err != nil {
+			t.Fatalf("Failed to render component: %v", err)
+		}
+		if !bytes.Equal(dm.img.Pix, labelImage(5, 12, want).Pix) {
+			t.Errorf("Expected %q to be drawn", want)
+		}
+	}
+
+	render("22C Clouds")
+	// Renders within the interval reuse the cached report
+	now = now.Add(9 * time.Minute)
+	render("22C Clouds")
+	if len(client.urls) != 1 {
+		t.Fatalf("Expected one fetch within the interval, got %d", len(client.urls))
+	}
+
+	// A failed refetch keeps the last good report and retries a minute later
+	now = now.Add(time.Minute)
+	client.status = http.StatusBadGateway
+	render("22C Clouds")
+	now = now.Add(30 * time.Second)
+	render("22C Clouds")
+	if len(client.urls) != 2 {
+		t.Fatalf("Expected no retry within a minute, got %d fetches", len(client.urls))
+	}
+	now = now.Add(30 * time.Second)
+	client.status = 0
+	client.body = `{"weather":[{"main":"Rain"}],"main":{"temp":18.2}}`
+	render("18C Rain")
+
+	// Without any good report the component shows N/A
+	dm.weather = fetchCache[weatherReport]{}
+	client.err = fmt.Errorf("dial tcp: lookup api.openweathermap.org: no such host")
+	render("weather: N/A")
+}
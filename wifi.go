@@ -0,0 +1,218 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/swilcox/go-monitor-ssd1306/render"
+)
+
+const (
+	wirelessFile         = "/proc/net/wireless"
+	defaultWifiInterface = "wlan0"
+	iwTimeout            = 2 * time.Second
+	ssidRefresh          = 30 * time.Second // how long an interface's SSID is reused
+)
+
+// Signal bar geometry: four bars, each two pixels wider and taller than a gap
+const (
+	signalBarCount  = 4
+	signalBarWidth  = 2
+	signalBarGap    = 1
+	signalBarHeight = 8
+)
+
+// wifiStatus is the state of one wireless interface
+type wifiStatus struct {
+	Connected bool
+	SSID      string // empty when iw is unavailable
+	Signal    int    // dBm
+}
+
+// WifiReader interface for getting the link state of a wireless interface
+type WifiReader interface {
+	WifiStatus(iface string) (wifiStatus, error)
+}
+
+// RealWifiReader implements WifiReader using /proc/net/wireless for the signal
+// level and `iw dev <iface> link` for the SSID. The SSID rarely changes while
+// connected, so iw only runs once per ssidRefresh instead of every update.
+type RealWifiReader struct {
+	runner  CommandRunner
+	timeNow func() time.Time // defaults to time.Now
+	ssids   map[string]cachedSSID
+}
+
+// cachedSSID is the SSID iw last reported for an interface
+type cachedSSID struct {
+	ssid string
+	at   time.Time
+}
+
+// WifiStatus returns the interface's link state. A system without wireless
+// extensions has no /proc/net/wireless, which reads as disconnected.
+func (r *RealWifiReader) WifiStatus(iface string) (wifiStatus, error) {
+	data, err := os.ReadFile(wirelessFile)
+	if os.IsNotExist(err) {
+		return wifiStatus{}, nil
+	}
+	if err != nil {
+		return wifiStatus{}, fmt.Errorf("failed to read wireless status: %v", err)
+	}
+	status, err := parseWireless(string(data), iface)
+	if err != nil || !status.Connected {
+		// The next connection may be to another network
+		delete(r.ssids, iface)
+		return status, err
+	}
+	status.SSID = r.ssid(iface)
+	return status, nil
+}
+
+// ssid returns the connected interface's SSID, running iw when the cached one
+// is older than ssidRefresh. The SSID is a nicety, so a missing or failing iw
+// still shows the signal.
+func (r *RealWifiReader) ssid(iface string) string {
+	now := time.Now()
+	if r.timeNow != nil {
+		now = r.timeNow()
+	}
+	if cached, ok := r.ssids[iface]; ok && now.Sub(cached.at) < ssidRefresh {
+		return cached.ssid
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), iwTimeout)
+	defer cancel()
+	var ssid string
+	if output, err := r.runner.Output(ctx, "iw", "dev", iface, "link"); err == nil {
+		ssid = parseIwSSID(string(output))
+	}
+	if r.ssids == nil {
+		r.ssids = make(map[string]cachedSSID)
+	}
+	r.ssids[iface] = cachedSSID{ssid: ssid, at: now}
+	return ssid
+}
+
+// parseWireless finds iface in /proc/net/wireless contents and returns its
+// signal level. An interface that is missing or has no link quality is
+// disconnected.
+func parseWireless(data, iface string) (wifiStatus, error) {
+	scanner := bufio.NewScanner(strings.NewReader(data))
+	for scanner.Scan() {
+		name, rest, ok := strings.Cut(scanner.Text(), ":")
+		if !ok || strings.TrimSpace(name) != iface {
+			continue
+		}
+		// status, link quality, signal level, noise, ...
+		fields := strings.Fields(rest)
+		if len(fields) < 3 {
+			return wifiStatus{}, fmt.Errorf("unexpected wireless line for %s: %q", iface, scanner.Text())
+		}
+		link, err := strconv.ParseFloat(strings.TrimSuffix(fields[1], "."), 64)
+		if err != nil {
+			return wifiStatus{}, fmt.Errorf("bad link quality %q: %v", fields[1], err)
+		}
+		level, err := strconv.ParseFloat(strings.TrimSuffix(fields[2], "."), 64)
+		if err != nil {
+			return wifiStatus{}, fmt.Errorf("bad signal level %q: %v", fields[2], err)
+		}
+		if link <= 0 {
+			return wifiStatus{}, nil
+		}
+		// Some drivers report dBm as an unsigned byte
+		if level > 0 {
+			level -= 256
+		}
+		return wifiStatus{Connected: true, Signal: int(level)}, nil
+	}
+	return wifiStatus{}, nil
+}
+
+// parseIwSSID returns the SSID from `iw dev <iface> link` output, or "" when
+// the interface is not connected
+func parseIwSSID(output string) string {
+	for _, line := range strings.Split(output, "\n") {
+		if ssid, ok := strings.CutPrefix(strings.TrimSpace(line), "SSID:"); ok {
+			return strings.TrimSpace(ssid)
+		}
+	}
+	return ""
+}
+
+// signalBars maps a signal level to 0-4 lit bars
+func signalBars(dBm int) int {
+	switch {
+	case dBm >= -55:
+		return 4
+	case dBm >= -67:
+		return 3
+	case dBm >= -75:
+		return 2
+	case dBm >= -85:
+		return 1
+	default:
+		return 0
+	}
+}
+
+// signalIcon draws four ascending bars with the first lit bars filled and the
+// rest reduced to their bottom row, so the shape stays visible
+func signalIcon(lit int) *render.Icon {
+	ic := &render.Icon{
+		Width:  signalBarCount*(signalBarWidth+signalBarGap) - signalBarGap,
+		Height: signalBarHeight,
+	}
+	rowBytes := (ic.Width + 7) / 8
+	ic.Bits = make([]byte, rowBytes*ic.Height)
+	for bar := 0; bar < signalBarCount; bar++ {
+		barHeight := (bar + 1) * signalBarHeight / signalBarCount
+		if bar >= lit {
+			barHeight = 1
+		}
+		for col := 0; col < signalBarWidth; col++ {
+			x := bar*(signalBarWidth+signalBarGap) + col
+			for y := ic.Height - barHeight; y < ic.Height; y++ {
+				ic.Bits[y*rowBytes+x/8] |= 1 << uint(x%8)
+			}
+		}
+	}
+	return ic
+}
+
+// wifiText finds the first connected interface in names and describes it,
+// e.g. "wlan0: MyNet -52dBm". The second result is the number of signal bars.
+func (dm *DisplayManager) wifiText(comp Component) (string, int, error) {
+	names := comp.Interfaces
+	if len(names) == 0 {
+		names = []string{defaultWifiInterface}
+	}
+	for _, name := range names {
+		status, err := dm.wifiReader.WifiStatus(name)
+		if err != nil {
+			return "", 0, err
+		}
+		if !status.Connected {
+			continue
+		}
+		label := comp.Label
+		if label == "" {
+			label = name
+		}
+		text := fmt.Sprintf("%s: %ddBm", label, status.Signal)
+		if status.SSID != "" {
+			text = fmt.Sprintf("%s: %s %ddBm", label, status.SSID, status.Signal)
+		}
+		return text, signalBars(status.Signal), nil
+	}
+	label := comp.Label
+	if label == "" {
+		label = "wifi"
+	}
+	return label + ": down", 0, nil
+}
@@ -0,0 +1,204 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/swilcox/go-monitor-ssd1306/render"
+)
+
+// sampleWireless is /proc/net/wireless with one connected and one idle interface
+const sampleWireless = `Inter-| sta-|   Quality        |   Discarded packets               | Missed | WE
+ face | tus | link level noise |  nwid  crypt   frag  retry   misc | beacon | 22
+ wlan0: 0000   54.  -56.  -256        0      0      0      0      0        0
+ wlan1: 0000    0.    0.  -256        0      0      0      0      0        0
+`
+
+// MockWifiReader implements WifiReader for testing
+type MockWifiReader struct {
+	statuses map[string]wifiStatus
+	err      error
+}
+
+func (m *MockWifiReader) WifiStatus(iface string) (wifiStatus, error) {
+	return m.statuses[iface], m.err
+}
+
+// TestParseWireless tests reading the signal level of an interface from
+// /proc/net/wireless
+func TestParseWireless(t *testing.T) {
+	tests := []struct {
+		name    string
+		data    string
+		iface   string
+		want    wifiStatus
+		wantErr bool
+	}{
+		{name: "Connected", data: sampleWireless, iface: "wlan0", want: wifiStatus{Connected: true, Signal: -56}},
+		{name: "No link", data: sampleWireless, iface: "wlan1", want: wifiStatus{}},
+		{name: "Missing interface", data: sampleWireless, iface: "wlan2", want: wifiStatus{}},
+		{name: "Headers only", data: "Inter-| sta-|   Quality\n face | tus | link level noise\n", iface: "wlan0", want: wifiStatus{}},
+		{
+			name:  "Unsigned level",
+			data:  " wlan0: 0000   60.  200.  0        0      0      0      0      0        0\n",
+			iface: "wlan0",
+			want:  wifiStatus{Connected: true, Signal: -56},
+		},
+		{name: "Truncated line", data: " wlan0: 0000   54.\n", iface: "wlan0", wantErr: true},
+		{name: "Bad level", data: " wlan0: 0000   54.  abc.  -256\n", iface: "wlan0", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parseWireless(tt.data, tt.iface)
+			if tt.wantErr {
+				if err == nil {
+					t.Error("Expected an error, got nil")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Failed to parse: %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("Expected %+v, got %+v", tt.want, got)
+			}
+		})
+	}
+}
+
+// TestParseIwSSID tests extracting the SSID from `iw dev <iface> link`
+func TestParseIwSSID(t *testing.T) {
+	connected := `Connected to aa:bb:cc:dd:ee:ff (on wlan0)
+	SSID: My Network
+	freq: 5180
+	signal: -52 dBm
+	tx bitrate: 433.3 MBit/s
+`
+	if got := parseIwSSID(connected); got != "My Network" {
+		t.Errorf("Expected \"My Network\", got %q", got)
+	}
+	if got := parseIwSSID("Not connected.\n"); got != "" {
+		t.Errorf("Expected no SSID when not connected, got %q", got)
+	}
+}
+
+// TestWifiSSIDCached tests that iw only runs again once the cached SSID is
+// older than ssidRefresh
+func TestWifiSSIDCached(t *testing.T) {
+	runner := &MockCommandRunner{output: []byte("Connected to aa:bb:cc:dd:ee:ff (on wlan0)\n\tSSID: HomeNet\n")}
+	start := time.Date(2024, 3, 9, 14, 0, 0, 0, time.Local)
+	now := start
+	reader := &RealWifiReader{runner: runner, timeNow: func() time.Time { return now }}
+
+	for _, after := range []time.Duration{0, time.Second, ssidRefresh - time.Second} {
+		now = start.Add(after)
+		if got := reader.ssid("wlan0"); got != "HomeNet" {
+			t.Errorf("After %v: expected HomeNet, got %q", after, got)
+		}
+	}
+	if len(runner.calls) != 1 {
+		t.Errorf("Expected iw to run once within %v, got %d runs", ssidRefresh, len(runner.calls))
+	}
+
+	now = start.Add(ssidRefresh)
+	runner.output = []byte("Connected to aa:bb:cc:dd:ee:ff (on wlan0)\n\tSSID: Guest\n")
+	if got := reader.ssid("wlan0"); got != "Guest" || len(runner.calls) != 2 {
+		t.Errorf("Expected a fresh SSID after %v, got %q after %d runs", ssidRefresh, got, len(runner.calls))
+	}
+}
+
+// TestSignalBars tests the dBm thresholds for each number of bars
+func TestSignalBars(t *testing.T) {
+	tests := []struct {
+		dBm  int
+		want int
+	}{
+		{-40, 4},
+		{-55, 4},
+		{-60, 3},
+		{-70, 2},
+		{-80, 1},
+		{-90, 0},
+	}
+	for _, tt := range tests {
+		if got := signalBars(tt.dBm); got != tt.want {
+			t.Errorf("signalBars(%d) = %d, want %d", tt.dBm, got, tt.want)
+		}
+	}
+}
+
+// TestSignalIcon tests that lit bars ascend and unlit bars keep their base
+func TestSignalIcon(t *testing.T) {
+	ic := signalIcon(2)
+	heights := make([]int, signalBarCount)
+	for bar := range heights {
+		x := bar * (signalBarWidth + signalBarGap)
+		for y := 0; y < ic.Height; y++ {
+			if ic.Lit(x, y) {
+				heights[bar]++
+			}
+		}
+	}
+	if want := []int{2, 4, 1, 1}; fmt.Sprint(heights) != fmt.Sprint(want) {
+		t.Errorf("Expected bar heights %v, got %v", want, heights)
+	}
+}
+
+// TestWifiComponent tests the wifi component's text for connected,
+// SSID-less and disconnected interfaces
+func TestWifiComponent(t *testing.T) {
+	reader := &MockWifiReader{statuses: map[string]wifiStatus{
+		"wlan0": {Connected: true, SSID: "MyNet", Signal: -52},
+		"wlan1": {Connected: true, Signal: -70},
+	}}
+
+	tests := []struct {
+		name      string
+		comp      Component
+		wantLabel string
+		wantBars  int // -1 for no signal icon
+	}{
+		{name: "Default interface", comp: Component{Type: "wifi", X: 5, Y: 20}, wantLabel: "wlan0: MyNet -52dBm", wantBars: -1},
+		{name: "No SSID", comp: Component{Type: "wifi", X: 5, Y: 20, Interfaces: []string{"wlan1"}}, wantLabel: "wlan1: -70dBm", wantBars: -1},
+		{name: "First connected", comp: Component{Type: "wifi", X: 5, Y: 20, Interfaces: []string{"wlan2", "wlan1"}}, wantLabel: "wlan1: -70dBm", wantBars: -1},
+		{name: "Label", comp: Component{Type: "wifi", X: 5, Y: 20, Label: "WiFi"}, wantLabel: "WiFi: MyNet -52dBm", wantBars: -1},
+		{name: "Down", comp: Component{Type: "wifi", X: 5, Y: 20, Interfaces: []string{"wlan2"}}, wantLabel: "wifi: down", wantBars: -1},
+		{name: "Signal bars", comp: Component{Type: "wifi", X: 5, Y: 20, SignalBars: true}, wantLabel: "wlan0: MyNet -52dBm", wantBars: 4},
+		{name: "Signal bars down", comp: Component{Type: "wifi", X: 5, Y: 20, Interfaces: []string{"wlan2"}, SignalBars: true}, wantLabel: "wifi: down", wantBars: 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dm := &DisplayManager{
+				wifiReader: reader,
+				img:        blankFrame(),
+			}
+			if err := dm.renderComponent(tt.comp); err != nil {
+				t.Fatalf("Failed to render component: %v", err)
+			}
+
+			want := labelImage(5, 20, tt.wantLabel)
+			if tt.wantBars >= 0 {
+				ic := signalIcon(tt.wantBars)
+				want = labelImage(5+ic.Width+render.IconGap, 20, tt.wantLabel)
+				render.DrawIcon(want, ic, 5, 20-ic.Height)
+			}
+			if !bytes.Equal(dm.img.Pix, want.Pix) {
+				t.Errorf("Rendered image does not match %q", tt.wantLabel)
+			}
+		})
+	}
+
+	t.Run("Read error", func(t *testing.T) {
+		dm := &DisplayManager{
+			wifiReader: &MockWifiReader{err: fmt.Errorf("permission denied")},
+			img:        blankFrame(),
+		}
+		if err := dm.renderComponent(Component{Type: "wifi", X: 5, Y: 20}); err == nil {
+			t.Error("Expected an error, got nil")
+		}
+	})
+}